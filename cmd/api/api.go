@@ -1,55 +1,246 @@
 package api
 
 import (
-	"database/sql"
+	"context"
+	"ecom_go/configs"
+	"ecom_go/db"
+	"ecom_go/services/address"
+	"ecom_go/services/catalog"
+	"ecom_go/services/favorite"
+	"ecom_go/services/geo"
+	"ecom_go/services/health"
+	"ecom_go/services/imaging"
+	"ecom_go/services/inventory"
+	"ecom_go/services/logging"
+	"ecom_go/services/metrics"
+	"ecom_go/services/notify"
+	"ecom_go/services/openinghours"
 	"ecom_go/services/product"
 	"ecom_go/services/productcategory"
+	"ecom_go/services/promotion"
+	"ecom_go/services/review"
+	"ecom_go/services/shipping"
 	"ecom_go/services/shop"
 	"ecom_go/services/shopcategory"
+	"ecom_go/services/shopmember"
+	"ecom_go/services/tax"
+	"ecom_go/services/tracing"
+	"ecom_go/services/trash"
 	"ecom_go/services/user"
-	"log"
+	"ecom_go/services/wishlist"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
 type APIServer struct {
 	addr string
-	db   *sql.DB
+	db   *db.DB
 }
 
-func NewAPIServer(addr string, db *sql.DB) *APIServer {
+func NewAPIServer(addr string, db *db.DB) *APIServer {
 	return &APIServer{
 		addr: addr,
 		db:   db,
 	}
 }
 
-func (s *APIServer) Run() error {
+// Run serves the API until ctx is cancelled, then stops accepting requests and
+// gives the ones in flight the configured shutdown timeout to finish. The
+// background workers are stopped after that, in reverse order of starting, so
+// a worker is never stopped while another one may still hand it work.
+func (s *APIServer) Run(ctx context.Context) error {
 	router := mux.NewRouter()
+
+	timeouts, err := queryTimeouts(time.Duration(configs.Envs.QueryTimeoutMs)*time.Millisecond, configs.Envs.QueryTimeouts)
+	if err != nil {
+		return err
+	}
+	router.Use(logging.RecordRoute, tracing.RecordRoute, timeouts, s.db.Middleware)
+
+	if err := metrics.RegisterDB(s.db.Primary(), configs.Envs.DBName); err != nil {
+		return err
+	}
+	for i, replica := range s.db.Replicas() {
+		if err := metrics.RegisterDB(replica, fmt.Sprintf("%s_replica_%d", configs.Envs.DBName, i+1)); err != nil {
+			return err
+		}
+	}
+
 	subrouter := router.PathPrefix("/api/v1").Subrouter()
 
 	userRouter := subrouter.PathPrefix("/users").Subrouter()
 	shopRouter := subrouter.PathPrefix("/shops").Subrouter()
 	productRouter := subrouter.PathPrefix("/products").Subrouter()
+	reviewRouter := subrouter.PathPrefix("/reviews").Subrouter()
+	promotionRouter := subrouter.PathPrefix("/promotions").Subrouter()
+	taxRouter := subrouter.PathPrefix("/tax").Subrouter()
+	shippingRouter := subrouter.PathPrefix("/shipping").Subrouter()
+	inventoryRouter := subrouter.PathPrefix("/inventory").Subrouter()
 
 	userStore := user.NewStore(s.db)
 	userHandler := user.NewHandler(userStore)
 	userHandler.RegisterRoutes(userRouter)
 
+	addressStore := address.NewStore(s.db)
+	addressHandler := address.NewHandler(addressStore, userStore)
+	addressHandler.RegisterRoutes(userRouter)
+
+	imageStore := imaging.NewStore(s.db)
+	imageProcessor := imaging.NewProcessor(imageStore, configs.Envs.StaticDir, int(configs.Envs.ImageQueueSize))
+	imageProcessor.Start()
+	defer imageProcessor.Stop()
+
+	notifier := notify.NewNotifier(configs.Envs.SMTPHost, configs.Envs.SMTPPort, configs.Envs.SMTPUsername, configs.Envs.SMTPPassword, configs.Envs.MailFrom)
+
+	favoriteStore := favorite.NewStore(s.db)
+	wishlistStore := wishlist.NewStore(s.db)
+
 	shopCategoryStore := shopcategory.NewStore(s.db)
 	shopStore := shop.NewStore(s.db)
-	shopHandler := shop.NewHandler(shopStore, shopCategoryStore, userStore)
+	shopMemberStore := shopmember.NewStore(s.db)
+	openingHoursStore := openinghours.NewStore(s.db)
+	geocoder := geo.NewGeocoder(configs.Envs.Geocoder, configs.Envs.GeocoderURL, configs.Envs.GeocoderUserAgent)
+	shopHandler := shop.NewHandler(shopStore, shopCategoryStore, userStore, imageStore, imageProcessor, openingHoursStore, geocoder, shopMemberStore, notifier, favoriteStore)
 	shopHandler.RegisterRoutes(shopRouter)
 
+	shopMemberHandler := shopmember.NewHandler(shopMemberStore, shopStore, userStore, notifier)
+	shopMemberHandler.RegisterRoutes(shopRouter)
+
 	productCategoryStore := productcategory.NewStore(s.db)
 	productStore := product.NewStore(s.db)
-	productHandler := product.NewHandler(productStore, productCategoryStore, userStore)
+	inventoryStore := inventory.NewStore(s.db)
+	stockAlerter := inventory.NewAlerter(productStore, shopStore, userStore, notifier)
+	productHandler := product.NewHandler(productStore, productCategoryStore, userStore, shopStore, shopMemberStore, imageStore, imageProcessor, wishlistStore, inventoryStore, stockAlerter)
 	productHandler.RegisterRoutes(productRouter)
 
-	router.PathPrefix("/").Handler(http.FileServer(http.Dir("static")))
+	inventoryHandler := inventory.NewHandler(inventoryStore, stockAlerter, productStore, shopStore, shopMemberStore, userStore,
+		time.Duration(configs.Envs.ReservationTTLMinutes)*time.Minute)
+	inventoryHandler.RegisterRoutes(inventoryRouter)
+
+	importStore := catalog.NewStore(s.db)
+	productImporter := catalog.NewImporter(importStore, productStore, productCategoryStore, inventoryStore, stockAlerter, imageProcessor, int(configs.Envs.ImportQueueSize))
+	productImporter.Start()
+	defer productImporter.Stop()
+
+	catalogHandler := catalog.NewHandler(importStore, productImporter, productStore, shopStore, shopMemberStore, userStore, configs.Envs.ImportMaxBytes)
+	catalogHandler.RegisterRoutes(shopRouter)
+
+	reservationExpirer := inventory.NewExpirer(inventoryStore, time.Duration(configs.Envs.ReservationExpiryIntervalSecs)*time.Second)
+	reservationExpirer.Start()
+	defer reservationExpirer.Stop()
+
+	reviewStore := review.NewStore(s.db)
+	reviewHandler := review.NewHandler(reviewStore, productStore, shopStore, shopMemberStore, userStore)
+	reviewHandler.RegisterRoutes(reviewRouter)
+
+	wishlistHandler := wishlist.NewHandler(wishlistStore, productStore, shopStore, shopMemberStore, userStore)
+	wishlistHandler.RegisterRoutes(userRouter)
+
+	favoriteHandler := favorite.NewHandler(favoriteStore, shopStore, shopMemberStore, userStore)
+	favoriteHandler.RegisterRoutes(userRouter)
+
+	promotionStore := promotion.NewStore(s.db)
+	promotionHandler := promotion.NewHandler(promotionStore, productStore, shopStore, shopMemberStore, userStore)
+	promotionHandler.RegisterRoutes(promotionRouter)
+
+	taxStore := tax.NewStore(s.db)
+	taxCalculator := tax.NewCalculator(taxStore, configs.Envs.TaxRounding)
+	taxHandler := tax.NewHandler(taxStore, taxCalculator, productStore, shopStore, shopMemberStore, userStore)
+	taxHandler.RegisterRoutes(taxRouter)
 
-	log.Println("Listening on", s.addr)
+	shippingStore := shipping.NewStore(s.db)
+	shippingHandler := shipping.NewHandler(shippingStore, productStore, shopStore, shopMemberStore, userStore, geocoder)
+	shippingHandler.RegisterRoutes(shippingRouter)
+
+	trashStore := trash.NewStore(s.db)
+	purger := trash.NewPurger(trashStore,
+		time.Duration(configs.Envs.TrashRetentionDays)*24*time.Hour,
+		time.Duration(configs.Envs.TrashPurgeIntervalMinutes)*time.Minute)
+	purger.Start()
+	defer purger.Stop()
+
+	healthStore := health.NewStore(s.db)
+	healthHandler := health.NewHandler(time.Duration(configs.Envs.HealthCheckTimeoutMs) * time.Millisecond)
+	healthHandler.Register(
+		health.NewDatabaseCheck(healthStore),
+		health.NewMigrationCheck(healthStore, configs.Envs.DriverMigrationsDir()),
+		imageProcessor,
+		productImporter,
+		reservationExpirer,
+		purger,
+	)
+	healthHandler.RegisterRoutes(router)
+
+	servers := []*http.Server{}
+
+	// Without a port of their own the metrics are served next to the API.
+	if configs.Envs.MetricsPort == "" {
+		router.Handle("/metrics", metrics.Handler()).Methods(http.MethodGet)
+	} else {
+		metricsRouter := http.NewServeMux()
+		metricsRouter.Handle("GET /metrics", metrics.Handler())
+
+		servers = append(servers, &http.Server{
+			Addr:              fmt.Sprintf(":%s", configs.Envs.MetricsPort),
+			Handler:           metricsRouter,
+			ReadTimeout:       time.Duration(configs.Envs.HTTPReadTimeoutSecs) * time.Second,
+			ReadHeaderTimeout: time.Duration(configs.Envs.HTTPReadHeaderTimeoutSecs) * time.Second,
+			WriteTimeout:      time.Duration(configs.Envs.HTTPWriteTimeoutSecs) * time.Second,
+			IdleTimeout:       time.Duration(configs.Envs.HTTPIdleTimeoutSecs) * time.Second,
+		})
+	}
+
+	router.PathPrefix("/").Handler(http.FileServer(http.Dir(configs.Envs.StaticDir)))
+
+	servers = append(servers, &http.Server{
+		Addr:              s.addr,
+		Handler:           logging.Middleware(slog.Default(), tracing.Middleware(metrics.Middleware(router, router))),
+		ReadTimeout:       time.Duration(configs.Envs.HTTPReadTimeoutSecs) * time.Second,
+		ReadHeaderTimeout: time.Duration(configs.Envs.HTTPReadHeaderTimeoutSecs) * time.Second,
+		WriteTimeout:      time.Duration(configs.Envs.HTTPWriteTimeoutSecs) * time.Second,
+		IdleTimeout:       time.Duration(configs.Envs.HTTPIdleTimeoutSecs) * time.Second,
+		MaxHeaderBytes:    int(configs.Envs.HTTPMaxHeaderBytes),
+	})
+
+	serveErr := make(chan error, len(servers))
+	for _, server := range servers {
+		go func() {
+			slog.Info("listening", "addr", server.Addr)
+			serveErr <- server.ListenAndServe()
+		}()
+	}
+
+	// A server failing to listen stops the others as well.
+	var runErr error
+	stopped := 0
+	select {
+	case runErr = <-serveErr:
+		stopped++
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down, draining in-flight requests")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(configs.Envs.ShutdownTimeoutSecs)*time.Second)
+	defer cancel()
+
+	for _, server := range servers {
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("requests did not finish in time, closing their connections", "addr", server.Addr, "error", err)
+			server.Close()
+		}
+	}
+
+	for ; stopped < len(servers); stopped++ {
+		if err := <-serveErr; runErr == nil && !errors.Is(err, http.ErrServerClosed) {
+			runErr = err
+		}
+	}
 
-	return http.ListenAndServe(s.addr, router)
+	return runErr
 }
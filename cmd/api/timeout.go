@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// queryTimeouts returns a middleware that bounds the context of each request,
+// and so every query made for it, by a timeout. Routes are given their own
+// timeout in spec as comma separated "METHOD /path/template=milliseconds"
+// entries, the path template as registered on the router; all other routes
+// get the fallback. A timeout of 0 leaves the route unbounded.
+func queryTimeouts(fallback time.Duration, spec string) (mux.MiddlewareFunc, error) {
+	routes := map[string]time.Duration{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		route, ms, ok := strings.Cut(entry, "=")
+		method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+		timeout, err := strconv.Atoi(strings.TrimSpace(ms))
+		if !ok || !hasPath || err != nil || timeout < 0 {
+			return nil, fmt.Errorf("invalid query timeout %q", entry)
+		}
+
+		routes[strings.ToUpper(method)+" "+strings.TrimSpace(path)] = time.Duration(timeout) * time.Millisecond
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout := fallback
+			if route := mux.CurrentRoute(r); route != nil {
+				if template, err := route.GetPathTemplate(); err == nil {
+					if routeTimeout, ok := routes[r.Method+" "+template]; ok {
+						timeout = routeTimeout
+					}
+				}
+			}
+
+			if timeout > 0 {
+				ctx, cancel := context.WithTimeout(r.Context(), timeout)
+				defer cancel()
+				r = r.WithContext(ctx)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
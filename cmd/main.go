@@ -1,46 +1,160 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"ecom_go/cmd/api"
 	"ecom_go/configs"
 	"ecom_go/db"
+	"ecom_go/services/logging"
+	"ecom_go/services/tracing"
+	"errors"
 	"fmt"
 	"log"
-
-	"github.com/go-sql-driver/mysql"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
-	cfg := mysql.Config{
-		User:                 configs.Envs.DBUser,
-		Passwd:               configs.Envs.DBPassword,
-		Addr:                 configs.Envs.DBAddress,
-		DBName:               configs.Envs.DBName,
-		Net:                  "tcp",
-		AllowNativePasswords: true,
-		ParseTime:            true,
+	cfg, args, err := configs.Load(os.Args[1:])
+
+	var invalid *configs.ValidationError
+	if err != nil && !errors.As(err, &invalid) {
+		log.Fatal(err)
+	}
+
+	if len(args) == 2 && args[0] == "config" && args[1] == "print" {
+		printConfig(cfg, invalid)
+		return
 	}
 
-	db, err := db.NewMySQLStorage(cfg)
+	if err != nil {
+		log.Fatalf("invalid configuration:\n%v", err)
+	}
+	configs.Envs = cfg
+
+	logger, err := logging.New(os.Stdout, configs.Envs.LogFormat, configs.Envs.LogLevel)
 	if err != nil {
 		log.Fatal(err)
 	}
+	slog.SetDefault(logger)
 
-	initStorage(db)
+	shutdownTracing, err := tracing.Setup(context.Background(), configs.Envs.TracingExporter, configs.Envs.TracingOTLPEndpoint, configs.Envs.TracingServiceName)
+	if err != nil {
+		fatal(err)
+	}
 
-	server := api.NewAPIServer(fmt.Sprintf(":%s", configs.Envs.Port), db)
-	if err := server.Run(); err != nil {
-		log.Fatal(err)
+	db, err := openStorage()
+	if err != nil {
+		fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	err = initStorage(ctx, db, time.Duration(configs.Envs.DBConnectMaxBackoffSecs)*time.Second)
+	if err == nil {
+		server := api.NewAPIServer(fmt.Sprintf(":%s", configs.Envs.Port), db)
+		err = server.Run(ctx)
+	}
+
+	// The workers are stopped by now, nothing uses the database anymore.
+	if closeErr := db.Close(); closeErr != nil {
+		slog.Error("failed to close the database", "error", closeErr)
 	}
 
+	flushCtx, cancel := context.WithTimeout(context.Background(), time.Duration(configs.Envs.ShutdownTimeoutSecs)*time.Second)
+	defer cancel()
+
+	if flushErr := shutdownTracing(flushCtx); flushErr != nil {
+		slog.Error("failed to flush traces", "error", flushErr)
+	}
+
+	// Being stopped while still waiting for the database is not a failure.
+	if err != nil && !errors.Is(err, context.Canceled) {
+		fatal(err)
+	}
+
+	slog.Info("server stopped")
 }
 
-func initStorage(db *sql.DB) {
-	err := db.Ping()
+// openStorage opens the connection pools of the primary database and of the
+// read replicas.
+func openStorage() (*db.DB, error) {
+	dialect := db.Dialect(configs.Envs.DBDriver)
+	if dialect == db.MySQL && configs.Envs.DBTLS == db.TLSConfigName {
+		if err := db.RegisterTLS(configs.Envs.DBTLSCAFile, configs.Envs.DBTLSCertFile, configs.Envs.DBTLSKeyFile); err != nil {
+			return nil, err
+		}
+	}
+
+	pool := db.PoolOptions{
+		MaxOpenConns:    int(configs.Envs.DBMaxOpenConns),
+		MaxIdleConns:    int(configs.Envs.DBMaxIdleConns),
+		ConnMaxLifetime: time.Duration(configs.Envs.DBConnMaxLifetimeSecs) * time.Second,
+		ConnMaxIdleTime: time.Duration(configs.Envs.DBConnMaxIdleTimeSecs) * time.Second,
+	}
+
+	primary, err := db.Open(dialect, configs.Envs.DSN(), pool)
 	if err != nil {
+		return nil, err
+	}
+
+	var replicas []*sql.DB
+	for _, dsn := range configs.Envs.ReplicaDSNs() {
+		replica, err := db.Open(dialect, dsn, pool)
+		if err != nil {
+			return nil, err
+		}
+		replicas = append(replicas, replica)
+	}
+
+	return db.New(dialect, primary, replicas...), nil
+}
+
+// initStorage waits for the database to accept connections, so the API can
+// start before the database does. Failed attempts are retried with an
+// exponential backoff up to maxBackoff until ctx is cancelled.
+func initStorage(ctx context.Context, db *db.DB, maxBackoff time.Duration) error {
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		err := db.PingContext(pingCtx)
+		cancel()
+		if err == nil {
+			slog.Info("connected to the database")
+			return nil
+		}
+
+		backoff = min(backoff, maxBackoff)
+		slog.Warn("failed to connect to the database, retrying", "attempt", attempt, "retry_in", backoff, "error", err)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		backoff *= 2
+	}
+}
+
+// printConfig shows the effective configuration with its secrets redacted,
+// followed by the problems with it, if any.
+func printConfig(cfg configs.Config, invalid *configs.ValidationError) {
+	if err := cfg.Print(os.Stdout); err != nil {
 		log.Fatal(err)
 	}
 
-	log.Println("DB: Successfully connected!")
+	if invalid != nil {
+		log.Fatalf("invalid configuration:\n%v", invalid)
+	}
+}
+
+func fatal(err error) {
+	slog.Error(err.Error())
+	os.Exit(1)
 }
@@ -6,36 +6,49 @@ import (
 	"log"
 	"os"
 
-	mysqlDriver "github.com/go-sql-driver/mysql"
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
 	mysqlMigrate "github.com/golang-migrate/migrate/v4/database/mysql"
+	pgxMigrate "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	sqliteMigrate "github.com/golang-migrate/migrate/v4/database/sqlite"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
 func main() {
-	cfg := mysqlDriver.Config{
-		User: configs.Envs.DBUser,
-		Passwd: configs.Envs.DBPassword,
-		Addr: configs.Envs.DBAddress,
-		DBName: configs.Envs.DBName,
-		Net: "tcp",
-		AllowNativePasswords: true,
-		ParseTime: true,
+	cfg, args, err := configs.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("invalid configuration:\n%v", err)
 	}
+	configs.Envs = cfg
 
-	db, err := db.NewMySQLStorage(cfg)
+	dialect := db.Dialect(configs.Envs.DBDriver)
+	if dialect == db.MySQL && configs.Envs.DBTLS == db.TLSConfigName {
+		if err := db.RegisterTLS(configs.Envs.DBTLSCAFile, configs.Envs.DBTLSCertFile, configs.Envs.DBTLSKeyFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	db, err := db.Open(dialect, configs.Envs.DSN(), db.PoolOptions{})
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	driver, err := mysqlMigrate.WithInstance(db, &mysqlMigrate.Config{})
+	var driver database.Driver
+	switch configs.Envs.DBDriver {
+	case "postgres":
+		driver, err = pgxMigrate.WithInstance(db, &pgxMigrate.Config{})
+	case "sqlite":
+		driver, err = sqliteMigrate.WithInstance(db, &sqliteMigrate.Config{})
+	default:
+		driver, err = mysqlMigrate.WithInstance(db, &mysqlMigrate.Config{})
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	m, err := migrate.NewWithDatabaseInstance(
-		"file://cmd/migrate/migrations",
-		"mysql",
+		"file://"+configs.Envs.DriverMigrationsDir(),
+		string(dialect),
 		driver,
 	)
 	if err != nil {
@@ -45,7 +58,10 @@ func main() {
 	v, d, _ := m.Version()
 	log.Printf("Version: %d, dirty: %v", v, d)
 
-	cmd := os.Args[len(os.Args)-1]
+	cmd := ""
+	if len(args) > 0 {
+		cmd = args[len(args)-1]
+	}
 	if cmd == "up" {
 		if err := m.Up(); err != nil && err != migrate.ErrNoChange {
 			log.Fatal(err)
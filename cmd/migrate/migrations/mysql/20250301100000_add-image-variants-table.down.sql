@@ -0,0 +1 @@
+DROP TABLE IF EXISTS image_variants;
\ No newline at end of file
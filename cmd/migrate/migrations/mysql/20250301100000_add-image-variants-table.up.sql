@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS image_variants (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `source` VARCHAR(255) NOT NULL,
+  `size` ENUM('thumbnail', 'medium', 'large') NOT NULL,
+  `format` ENUM('jpeg', 'png', 'webp') NOT NULL,
+  `width` INT UNSIGNED NOT NULL,
+  `height` INT UNSIGNED NOT NULL,
+  `url` VARCHAR(255) NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY (`source`, `size`, `format`)
+);
\ No newline at end of file
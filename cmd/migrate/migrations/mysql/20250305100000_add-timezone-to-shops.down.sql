@@ -0,0 +1 @@
+ALTER TABLE shops DROP COLUMN `timezone`;
\ No newline at end of file
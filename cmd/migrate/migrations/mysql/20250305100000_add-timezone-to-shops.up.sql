@@ -0,0 +1 @@
+ALTER TABLE shops ADD COLUMN `timezone` VARCHAR(64) NOT NULL DEFAULT 'UTC' AFTER `image`;
\ No newline at end of file
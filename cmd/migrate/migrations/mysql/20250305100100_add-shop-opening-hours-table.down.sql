@@ -0,0 +1 @@
+DROP TABLE IF EXISTS shop_opening_hours;
\ No newline at end of file
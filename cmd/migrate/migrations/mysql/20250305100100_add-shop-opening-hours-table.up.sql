@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS shop_opening_hours (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `shop_id` INT UNSIGNED NOT NULL,
+  `weekday` TINYINT UNSIGNED NOT NULL,
+  `opens_at` TIME NOT NULL,
+  `closes_at` TIME NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  FOREIGN KEY (`shop_id`) REFERENCES shops(`id`) ON DELETE CASCADE
+);
\ No newline at end of file
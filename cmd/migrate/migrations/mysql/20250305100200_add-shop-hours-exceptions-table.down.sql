@@ -0,0 +1 @@
+DROP TABLE IF EXISTS shop_hours_exceptions;
\ No newline at end of file
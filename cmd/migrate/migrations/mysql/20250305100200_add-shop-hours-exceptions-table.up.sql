@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS shop_hours_exceptions (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `shop_id` INT UNSIGNED NOT NULL,
+  `date` DATE NOT NULL,
+  `opens_at` TIME DEFAULT NULL,
+  `closes_at` TIME DEFAULT NULL,
+  `note` VARCHAR(255) DEFAULT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY (`shop_id`, `date`),
+  FOREIGN KEY (`shop_id`) REFERENCES shops(`id`) ON DELETE CASCADE
+);
\ No newline at end of file
@@ -0,0 +1,8 @@
+ALTER TABLE shops
+  DROP COLUMN `location`,
+  DROP COLUMN `longitude`,
+  DROP COLUMN `latitude`,
+  DROP COLUMN `country`,
+  DROP COLUMN `postal_code`,
+  DROP COLUMN `city`,
+  DROP COLUMN `street`;
\ No newline at end of file
@@ -0,0 +1,9 @@
+ALTER TABLE shops
+  ADD COLUMN `street` VARCHAR(255) DEFAULT NULL AFTER `address`,
+  ADD COLUMN `city` VARCHAR(255) DEFAULT NULL AFTER `street`,
+  ADD COLUMN `postal_code` VARCHAR(32) DEFAULT NULL AFTER `city`,
+  ADD COLUMN `country` CHAR(2) DEFAULT NULL AFTER `postal_code`,
+  ADD COLUMN `latitude` DOUBLE DEFAULT NULL AFTER `country`,
+  ADD COLUMN `longitude` DOUBLE DEFAULT NULL AFTER `latitude`,
+  ADD COLUMN `location` POINT GENERATED ALWAYS AS (POINT(IFNULL(`longitude`, 0), IFNULL(`latitude`, 0))) STORED NOT NULL SRID 0 INVISIBLE,
+  ADD SPATIAL INDEX (`location`);
\ No newline at end of file
@@ -0,0 +1 @@
+DELETE FROM shop_members WHERE role = 'owner';
\ No newline at end of file
@@ -0,0 +1 @@
+INSERT INTO shop_members (shop_id, user_id, role) SELECT id, user_id, 'owner' FROM shops;
\ No newline at end of file
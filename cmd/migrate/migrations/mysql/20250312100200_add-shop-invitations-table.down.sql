@@ -0,0 +1 @@
+DROP TABLE IF EXISTS shop_invitations;
\ No newline at end of file
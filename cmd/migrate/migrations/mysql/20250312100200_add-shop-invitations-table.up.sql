@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS shop_invitations (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `shop_id` INT UNSIGNED NOT NULL,
+  `email` VARCHAR(255) NOT NULL,
+  `role` ENUM('manager', 'staff') NOT NULL DEFAULT 'staff',
+  `token` CHAR(64) NOT NULL UNIQUE,
+  `invited_by` INT UNSIGNED NOT NULL,
+  `expires_at` TIMESTAMP NOT NULL,
+  `accepted_at` TIMESTAMP NULL DEFAULT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  FOREIGN KEY (`shop_id`) REFERENCES shops(`id`) ON DELETE CASCADE,
+  FOREIGN KEY (`invited_by`) REFERENCES users(`id`) ON DELETE CASCADE
+);
\ No newline at end of file
@@ -0,0 +1,6 @@
+ALTER TABLE shops
+  DROP FOREIGN KEY `fk_shops_reviewed_by`,
+  DROP COLUMN `reviewed_at`,
+  DROP COLUMN `reviewed_by`,
+  DROP COLUMN `status_reason`,
+  DROP COLUMN `status`;
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE shops
+  ADD COLUMN `status` ENUM('pending', 'approved', 'rejected', 'suspended') NOT NULL DEFAULT 'pending' AFTER `timezone`,
+  ADD COLUMN `status_reason` VARCHAR(1000) DEFAULT NULL AFTER `status`,
+  ADD COLUMN `reviewed_by` INT UNSIGNED DEFAULT NULL AFTER `status_reason`,
+  ADD COLUMN `reviewed_at` TIMESTAMP NULL DEFAULT NULL AFTER `reviewed_by`,
+  ADD INDEX (`status`),
+  ADD CONSTRAINT `fk_shops_reviewed_by` FOREIGN KEY (`reviewed_by`) REFERENCES users(`id`) ON DELETE SET NULL;
\ No newline at end of file
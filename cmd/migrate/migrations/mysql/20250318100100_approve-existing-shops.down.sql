@@ -0,0 +1 @@
+UPDATE shops SET `status` = 'pending';
\ No newline at end of file
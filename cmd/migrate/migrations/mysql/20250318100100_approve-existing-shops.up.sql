@@ -0,0 +1 @@
+UPDATE shops SET `status` = 'approved';
\ No newline at end of file
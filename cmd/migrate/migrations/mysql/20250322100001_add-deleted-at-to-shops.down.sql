@@ -0,0 +1,3 @@
+ALTER TABLE shops
+  DROP INDEX `deleted_at`,
+  DROP COLUMN `deleted_at`;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE shops
+  ADD COLUMN `deleted_at` TIMESTAMP NULL DEFAULT NULL AFTER `updated_at`,
+  ADD INDEX (`deleted_at`);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS product_reviews;
\ No newline at end of file
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS product_reviews (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `product_id` INT UNSIGNED NOT NULL,
+  `user_id` INT UNSIGNED NOT NULL,
+  `rating` TINYINT UNSIGNED NOT NULL,
+  `body` TEXT DEFAULT NULL,
+  `status` ENUM('visible', 'hidden', 'flagged') NOT NULL DEFAULT 'visible',
+  `moderation_reason` VARCHAR(1000) DEFAULT NULL,
+  `owner_reply` TEXT DEFAULT NULL,
+  `owner_replied_at` TIMESTAMP NULL DEFAULT NULL,
+  `helpful_count` INT UNSIGNED NOT NULL DEFAULT 0,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY (`product_id`, `user_id`),
+  KEY (`status`),
+  FOREIGN KEY (`product_id`) REFERENCES products(`id`) ON DELETE CASCADE,
+  FOREIGN KEY (`user_id`) REFERENCES users(`id`) ON DELETE CASCADE
+);
\ No newline at end of file
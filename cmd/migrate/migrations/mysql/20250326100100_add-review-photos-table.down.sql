@@ -0,0 +1 @@
+DROP TABLE IF EXISTS review_photos;
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS review_photos (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `review_id` INT UNSIGNED NOT NULL,
+  `url` VARCHAR(255) NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  FOREIGN KEY (`review_id`) REFERENCES product_reviews(`id`) ON DELETE CASCADE
+);
\ No newline at end of file
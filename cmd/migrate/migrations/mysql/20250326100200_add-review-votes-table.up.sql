@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS review_votes (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `review_id` INT UNSIGNED NOT NULL,
+  `user_id` INT UNSIGNED NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY (`review_id`, `user_id`),
+  FOREIGN KEY (`review_id`) REFERENCES product_reviews(`id`) ON DELETE CASCADE,
+  FOREIGN KEY (`user_id`) REFERENCES users(`id`) ON DELETE CASCADE
+);
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE products
+  DROP COLUMN `rating_count`,
+  DROP COLUMN `rating_average`;
\ No newline at end of file
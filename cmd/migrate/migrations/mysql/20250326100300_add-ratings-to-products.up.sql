@@ -0,0 +1,3 @@
+ALTER TABLE products
+  ADD COLUMN `rating_average` DECIMAL(3, 2) NOT NULL DEFAULT 0 AFTER `image`,
+  ADD COLUMN `rating_count` INT UNSIGNED NOT NULL DEFAULT 0 AFTER `rating_average`;
\ No newline at end of file
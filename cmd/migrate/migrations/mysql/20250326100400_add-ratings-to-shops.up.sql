@@ -0,0 +1,3 @@
+ALTER TABLE shops
+  ADD COLUMN `rating_average` DECIMAL(3, 2) NOT NULL DEFAULT 0 AFTER `reviewed_at`,
+  ADD COLUMN `rating_count` INT UNSIGNED NOT NULL DEFAULT 0 AFTER `rating_average`;
\ No newline at end of file
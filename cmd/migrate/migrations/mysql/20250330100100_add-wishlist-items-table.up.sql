@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS wishlist_items (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `wishlist_id` INT UNSIGNED NOT NULL,
+  `product_id` INT UNSIGNED NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY (`wishlist_id`, `product_id`),
+  FOREIGN KEY (`wishlist_id`) REFERENCES wishlists(`id`) ON DELETE CASCADE,
+  FOREIGN KEY (`product_id`) REFERENCES products(`id`) ON DELETE CASCADE
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS favorite_shops;
\ No newline at end of file
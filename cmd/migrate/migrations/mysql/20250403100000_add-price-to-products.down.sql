@@ -0,0 +1,2 @@
+ALTER TABLE products
+  DROP COLUMN `price_cents`;
\ No newline at end of file
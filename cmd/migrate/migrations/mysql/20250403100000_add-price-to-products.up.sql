@@ -0,0 +1,2 @@
+ALTER TABLE products
+  ADD COLUMN `price_cents` BIGINT UNSIGNED NOT NULL DEFAULT 0 AFTER `quantity`;
\ No newline at end of file
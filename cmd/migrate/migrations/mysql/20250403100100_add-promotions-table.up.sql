@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS promotions (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `shop_id` INT UNSIGNED DEFAULT NULL,
+  `created_by` INT UNSIGNED NOT NULL,
+  `name` VARCHAR(255) NOT NULL,
+  `code` VARCHAR(64) DEFAULT NULL,
+  `type` ENUM('percentage', 'fixed_amount', 'buy_x_get_y', 'free_shipping') NOT NULL,
+  `value` BIGINT UNSIGNED NOT NULL DEFAULT 0,
+  `buy_quantity` INT UNSIGNED NOT NULL DEFAULT 0,
+  `get_quantity` INT UNSIGNED NOT NULL DEFAULT 0,
+  `scope` ENUM('all', 'shop', 'category', 'product') NOT NULL DEFAULT 'all',
+  `min_subtotal_cents` BIGINT UNSIGNED NOT NULL DEFAULT 0,
+  `starts_at` TIMESTAMP NULL DEFAULT NULL,
+  `ends_at` TIMESTAMP NULL DEFAULT NULL,
+  `usage_limit` INT UNSIGNED DEFAULT NULL,
+  `usage_limit_per_user` INT UNSIGNED DEFAULT NULL,
+  `usage_count` INT UNSIGNED NOT NULL DEFAULT 0,
+  `stackable` BOOLEAN NOT NULL DEFAULT TRUE,
+  `priority` INT NOT NULL DEFAULT 0,
+  `active` BOOLEAN NOT NULL DEFAULT TRUE,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY (`code`),
+  KEY (`active`, `starts_at`, `ends_at`),
+  FOREIGN KEY (`shop_id`) REFERENCES shops(`id`) ON DELETE CASCADE,
+  FOREIGN KEY (`created_by`) REFERENCES users(`id`) ON DELETE CASCADE
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS promotion_targets;
\ No newline at end of file
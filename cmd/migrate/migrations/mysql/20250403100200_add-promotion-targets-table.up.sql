@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS promotion_targets (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `promotion_id` INT UNSIGNED NOT NULL,
+  `target_id` INT UNSIGNED NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY (`promotion_id`, `target_id`),
+  FOREIGN KEY (`promotion_id`) REFERENCES promotions(`id`) ON DELETE CASCADE
+);
\ No newline at end of file
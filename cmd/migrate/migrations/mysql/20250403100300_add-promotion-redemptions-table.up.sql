@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS promotion_redemptions (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `promotion_id` INT UNSIGNED NOT NULL,
+  `user_id` INT UNSIGNED NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY (`promotion_id`, `user_id`),
+  FOREIGN KEY (`promotion_id`) REFERENCES promotions(`id`) ON DELETE CASCADE,
+  FOREIGN KEY (`user_id`) REFERENCES users(`id`) ON DELETE CASCADE
+);
\ No newline at end of file
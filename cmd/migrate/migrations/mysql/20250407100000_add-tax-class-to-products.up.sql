@@ -0,0 +1,2 @@
+ALTER TABLE products
+  ADD COLUMN `tax_class` VARCHAR(32) NOT NULL DEFAULT 'standard' AFTER `price_cents`;
\ No newline at end of file
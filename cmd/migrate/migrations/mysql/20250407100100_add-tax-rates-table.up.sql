@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS tax_rates (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `country` CHAR(2) NOT NULL,
+  `region` VARCHAR(64) DEFAULT NULL,
+  `tax_class` VARCHAR(32) NOT NULL DEFAULT 'standard',
+  `name` VARCHAR(100) NOT NULL,
+  `rate_basis_points` INT UNSIGNED NOT NULL,
+  `inclusive` BOOLEAN NOT NULL DEFAULT FALSE,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY (`country`, `region`, `tax_class`)
+);
\ No newline at end of file
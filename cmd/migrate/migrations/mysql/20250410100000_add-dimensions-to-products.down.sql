@@ -0,0 +1,5 @@
+ALTER TABLE products
+  DROP COLUMN `height_mm`,
+  DROP COLUMN `width_mm`,
+  DROP COLUMN `length_mm`,
+  DROP COLUMN `weight_grams`;
\ No newline at end of file
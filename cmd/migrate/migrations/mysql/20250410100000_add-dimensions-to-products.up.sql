@@ -0,0 +1,5 @@
+ALTER TABLE products
+  ADD COLUMN `weight_grams` INT UNSIGNED NOT NULL DEFAULT 0 AFTER `tax_class`,
+  ADD COLUMN `length_mm` INT UNSIGNED NOT NULL DEFAULT 0 AFTER `weight_grams`,
+  ADD COLUMN `width_mm` INT UNSIGNED NOT NULL DEFAULT 0 AFTER `length_mm`,
+  ADD COLUMN `height_mm` INT UNSIGNED NOT NULL DEFAULT 0 AFTER `width_mm`;
\ No newline at end of file
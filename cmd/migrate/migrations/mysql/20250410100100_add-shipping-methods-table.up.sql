@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS shipping_methods (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `shop_id` INT UNSIGNED NOT NULL,
+  `name` VARCHAR(100) NOT NULL,
+  `type` ENUM('pickup', 'flat_rate', 'weight_tiers', 'price_tiers') NOT NULL,
+  `rate_cents` BIGINT UNSIGNED NOT NULL DEFAULT 0,
+  `free_over_cents` BIGINT UNSIGNED DEFAULT NULL,
+  `active` BOOLEAN NOT NULL DEFAULT TRUE,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  FOREIGN KEY (`shop_id`) REFERENCES shops(`id`) ON DELETE CASCADE
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS shipping_rate_tiers;
\ No newline at end of file
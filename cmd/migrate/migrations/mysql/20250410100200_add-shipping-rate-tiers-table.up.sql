@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS shipping_rate_tiers (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `method_id` INT UNSIGNED NOT NULL,
+  `min_value` BIGINT UNSIGNED NOT NULL,
+  `rate_cents` BIGINT UNSIGNED NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY (`method_id`, `min_value`),
+  FOREIGN KEY (`method_id`) REFERENCES shipping_methods(`id`) ON DELETE CASCADE
+);
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS shipping_zones (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `method_id` INT UNSIGNED NOT NULL,
+  `type` ENUM('postal_range', 'radius') NOT NULL,
+  `country` CHAR(2) DEFAULT NULL,
+  `postal_from` VARCHAR(16) DEFAULT NULL,
+  `postal_to` VARCHAR(16) DEFAULT NULL,
+  `radius_km` DECIMAL(8, 3) DEFAULT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  FOREIGN KEY (`method_id`) REFERENCES shipping_methods(`id`) ON DELETE CASCADE
+);
\ No newline at end of file
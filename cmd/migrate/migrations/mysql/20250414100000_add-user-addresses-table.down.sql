@@ -0,0 +1 @@
+DROP TABLE IF EXISTS user_addresses;
\ No newline at end of file
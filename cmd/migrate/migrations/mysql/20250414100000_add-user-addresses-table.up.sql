@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS user_addresses (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `user_id` INT UNSIGNED NOT NULL,
+  `label` VARCHAR(50) DEFAULT NULL,
+  `full_name` VARCHAR(255) NOT NULL,
+  `phone` VARCHAR(32) DEFAULT NULL,
+  `street` VARCHAR(255) NOT NULL,
+  `street2` VARCHAR(255) NOT NULL DEFAULT '',
+  `city` VARCHAR(100) NOT NULL,
+  `region` VARCHAR(100) NOT NULL DEFAULT '',
+  `postal_code` VARCHAR(16) NOT NULL DEFAULT '',
+  `country` CHAR(2) NOT NULL,
+  `is_default` BOOLEAN NOT NULL DEFAULT FALSE,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY (`user_id`),
+  FOREIGN KEY (`user_id`) REFERENCES users(`id`) ON DELETE CASCADE
+);
\ No newline at end of file
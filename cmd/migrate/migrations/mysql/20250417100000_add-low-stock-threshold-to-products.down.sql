@@ -0,0 +1 @@
+ALTER TABLE products DROP COLUMN `low_stock_threshold`;
\ No newline at end of file
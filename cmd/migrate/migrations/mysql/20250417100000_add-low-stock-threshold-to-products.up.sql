@@ -0,0 +1 @@
+ALTER TABLE products ADD COLUMN `low_stock_threshold` INT UNSIGNED DEFAULT NULL AFTER `quantity`;
\ No newline at end of file
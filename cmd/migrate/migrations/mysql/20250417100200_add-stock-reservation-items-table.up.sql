@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS stock_reservation_items (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `reservation_id` INT UNSIGNED NOT NULL,
+  `product_id` INT UNSIGNED NOT NULL,
+  `quantity` INT UNSIGNED NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY (`reservation_id`, `product_id`),
+  KEY (`product_id`),
+  FOREIGN KEY (`reservation_id`) REFERENCES stock_reservations(`id`) ON DELETE CASCADE,
+  FOREIGN KEY (`product_id`) REFERENCES products(`id`) ON DELETE CASCADE
+);
\ No newline at end of file
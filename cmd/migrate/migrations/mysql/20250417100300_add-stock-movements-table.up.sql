@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS stock_movements (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `product_id` INT UNSIGNED NOT NULL,
+  `type` ENUM('receipt', 'sale', 'adjustment', 'return') NOT NULL,
+  `quantity` INT NOT NULL,
+  `reason` VARCHAR(255) DEFAULT NULL,
+  `actor_id` INT UNSIGNED DEFAULT NULL,
+  `reservation_id` INT UNSIGNED DEFAULT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY (`product_id`, `id`),
+  FOREIGN KEY (`product_id`) REFERENCES products(`id`) ON DELETE CASCADE,
+  FOREIGN KEY (`actor_id`) REFERENCES users(`id`) ON DELETE SET NULL,
+  FOREIGN KEY (`reservation_id`) REFERENCES stock_reservations(`id`) ON DELETE SET NULL
+);
\ No newline at end of file
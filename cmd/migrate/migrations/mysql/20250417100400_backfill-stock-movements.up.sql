@@ -0,0 +1,2 @@
+INSERT INTO stock_movements (product_id, type, quantity, reason)
+SELECT id, 'adjustment', quantity, 'opening balance' FROM products WHERE quantity <> 0;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE shops DROP COLUMN `allocation_strategy`;
\ No newline at end of file
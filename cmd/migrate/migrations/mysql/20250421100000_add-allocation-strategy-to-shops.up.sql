@@ -0,0 +1 @@
+ALTER TABLE shops ADD COLUMN `allocation_strategy` ENUM('highest_stock', 'nearest') NOT NULL DEFAULT 'highest_stock' AFTER `timezone`;
\ No newline at end of file
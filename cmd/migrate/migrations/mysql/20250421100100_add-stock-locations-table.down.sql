@@ -0,0 +1 @@
+DROP TABLE IF EXISTS stock_locations;
\ No newline at end of file
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS stock_locations (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `shop_id` INT UNSIGNED NOT NULL,
+  `name` VARCHAR(100) NOT NULL,
+  `street` VARCHAR(255) NOT NULL DEFAULT '',
+  `street2` VARCHAR(255) NOT NULL DEFAULT '',
+  `city` VARCHAR(255) NOT NULL DEFAULT '',
+  `region` VARCHAR(100) NOT NULL DEFAULT '',
+  `postal_code` VARCHAR(32) NOT NULL DEFAULT '',
+  `country` VARCHAR(2) NOT NULL DEFAULT '',
+  `latitude` DOUBLE DEFAULT NULL,
+  `longitude` DOUBLE DEFAULT NULL,
+  `is_default` BOOLEAN NOT NULL DEFAULT FALSE,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY (`shop_id`),
+  FOREIGN KEY (`shop_id`) REFERENCES shops(`id`) ON DELETE CASCADE
+);
\ No newline at end of file
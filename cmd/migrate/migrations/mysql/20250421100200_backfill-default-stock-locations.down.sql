@@ -0,0 +1 @@
+DELETE FROM stock_locations;
\ No newline at end of file
@@ -0,0 +1,3 @@
+INSERT INTO stock_locations (shop_id, name, street, city, postal_code, country, latitude, longitude, is_default)
+SELECT id, 'Main', COALESCE(street, ''), COALESCE(city, ''), COALESCE(postal_code, ''), COALESCE(country, ''), latitude, longitude, TRUE
+FROM shops;
\ No newline at end of file
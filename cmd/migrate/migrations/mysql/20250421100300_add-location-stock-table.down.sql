@@ -0,0 +1 @@
+DROP TABLE IF EXISTS location_stock;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS location_stock (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `location_id` INT UNSIGNED NOT NULL,
+  `product_id` INT UNSIGNED NOT NULL,
+  `quantity` INT UNSIGNED NOT NULL DEFAULT 0,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY (`location_id`, `product_id`),
+  KEY (`product_id`),
+  FOREIGN KEY (`location_id`) REFERENCES stock_locations(`id`) ON DELETE CASCADE,
+  FOREIGN KEY (`product_id`) REFERENCES products(`id`) ON DELETE CASCADE
+);
\ No newline at end of file
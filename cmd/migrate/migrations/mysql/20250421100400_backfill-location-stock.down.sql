@@ -0,0 +1 @@
+DELETE FROM location_stock;
\ No newline at end of file
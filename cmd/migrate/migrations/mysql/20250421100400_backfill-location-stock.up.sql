@@ -0,0 +1,5 @@
+INSERT INTO location_stock (location_id, product_id, quantity)
+SELECT l.id, p.id, p.quantity
+FROM products p
+JOIN stock_locations l ON l.shop_id = p.shop_id AND l.is_default = TRUE
+WHERE p.quantity > 0;
\ No newline at end of file
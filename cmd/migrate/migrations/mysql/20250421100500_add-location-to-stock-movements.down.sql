@@ -0,0 +1,4 @@
+ALTER TABLE stock_movements
+  DROP FOREIGN KEY `fk_stock_movements_location`,
+  DROP COLUMN `location_id`,
+  MODIFY COLUMN `type` ENUM('receipt', 'sale', 'adjustment', 'return') NOT NULL;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE stock_movements
+  MODIFY COLUMN `type` ENUM('receipt', 'sale', 'adjustment', 'return', 'transfer') NOT NULL,
+  ADD COLUMN `location_id` INT UNSIGNED DEFAULT NULL AFTER `quantity`,
+  ADD CONSTRAINT `fk_stock_movements_location` FOREIGN KEY (`location_id`) REFERENCES stock_locations(`id`) ON DELETE SET NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+UPDATE stock_movements SET location_id = NULL;
\ No newline at end of file
@@ -0,0 +1,5 @@
+UPDATE stock_movements m
+JOIN products p ON p.id = m.product_id
+JOIN stock_locations l ON l.shop_id = p.shop_id AND l.is_default = TRUE
+SET m.location_id = l.id
+WHERE m.location_id IS NULL;
\ No newline at end of file
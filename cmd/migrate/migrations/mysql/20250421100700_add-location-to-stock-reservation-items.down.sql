@@ -0,0 +1,5 @@
+ALTER TABLE stock_reservation_items
+  DROP FOREIGN KEY `fk_stock_reservation_items_location`,
+  ADD UNIQUE KEY `reservation_id` (`reservation_id`, `product_id`),
+  DROP INDEX `uq_reservation_product_location`,
+  DROP COLUMN `location_id`;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE stock_reservation_items
+  ADD COLUMN `location_id` INT UNSIGNED DEFAULT NULL AFTER `product_id`,
+  ADD UNIQUE KEY `uq_reservation_product_location` (`reservation_id`, `product_id`, `location_id`),
+  DROP INDEX `reservation_id`,
+  ADD CONSTRAINT `fk_stock_reservation_items_location` FOREIGN KEY (`location_id`) REFERENCES stock_locations(`id`) ON DELETE CASCADE;
\ No newline at end of file
@@ -0,0 +1 @@
+UPDATE stock_reservation_items SET location_id = NULL;
\ No newline at end of file
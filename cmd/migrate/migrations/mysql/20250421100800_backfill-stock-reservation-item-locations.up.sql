@@ -0,0 +1,5 @@
+UPDATE stock_reservation_items i
+JOIN products p ON p.id = i.product_id
+JOIN stock_locations l ON l.shop_id = p.shop_id AND l.is_default = TRUE
+SET i.location_id = l.id
+WHERE i.location_id IS NULL;
\ No newline at end of file
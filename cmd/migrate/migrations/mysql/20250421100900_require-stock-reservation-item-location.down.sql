@@ -0,0 +1 @@
+ALTER TABLE stock_reservation_items MODIFY COLUMN `location_id` INT UNSIGNED DEFAULT NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE products DROP KEY `idx_products_sku`, DROP COLUMN `sku`;
\ No newline at end of file
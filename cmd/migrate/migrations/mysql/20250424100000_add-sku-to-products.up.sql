@@ -0,0 +1 @@
+ALTER TABLE products ADD COLUMN `sku` VARCHAR(64) DEFAULT NULL AFTER `shop_id`, ADD KEY `idx_products_sku` (`sku`, `shop_id`);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS product_import_jobs;
\ No newline at end of file
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS product_import_jobs (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `shop_id` INT UNSIGNED NOT NULL,
+  `user_id` INT UNSIGNED NOT NULL,
+  `format` ENUM('csv', 'jsonl') NOT NULL,
+  `dry_run` BOOLEAN NOT NULL DEFAULT FALSE,
+  `status` ENUM('pending', 'running', 'completed', 'failed') NOT NULL DEFAULT 'pending',
+  `total_rows` INT UNSIGNED NOT NULL DEFAULT 0,
+  `created_rows` INT UNSIGNED NOT NULL DEFAULT 0,
+  `updated_rows` INT UNSIGNED NOT NULL DEFAULT 0,
+  `failed_rows` INT UNSIGNED NOT NULL DEFAULT 0,
+  `error` VARCHAR(255) DEFAULT NULL,
+  `data` MEDIUMBLOB DEFAULT NULL,
+  `started_at` TIMESTAMP NULL DEFAULT NULL,
+  `finished_at` TIMESTAMP NULL DEFAULT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY (`status`),
+  FOREIGN KEY (`shop_id`) REFERENCES shops(`id`) ON DELETE CASCADE,
+  FOREIGN KEY (`user_id`) REFERENCES users(`id`) ON DELETE CASCADE
+);
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS product_import_errors (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `job_id` INT UNSIGNED NOT NULL,
+  `line` INT UNSIGNED NOT NULL,
+  `sku` VARCHAR(64) NOT NULL DEFAULT '',
+  `message` VARCHAR(512) NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY (`job_id`, `line`),
+  FOREIGN KEY (`job_id`) REFERENCES product_import_jobs(`id`) ON DELETE CASCADE
+);
\ No newline at end of file
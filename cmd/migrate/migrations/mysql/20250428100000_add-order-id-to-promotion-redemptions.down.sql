@@ -0,0 +1,3 @@
+ALTER TABLE promotion_redemptions
+  DROP INDEX `uk_promotion_redemptions_order`,
+  DROP COLUMN `order_id`;
\ No newline at end of file
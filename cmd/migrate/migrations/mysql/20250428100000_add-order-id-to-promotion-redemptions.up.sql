@@ -0,0 +1,3 @@
+ALTER TABLE promotion_redemptions
+  ADD COLUMN `order_id` VARCHAR(64) DEFAULT NULL AFTER `user_id`,
+  ADD UNIQUE KEY `uk_promotion_redemptions_order` (`promotion_id`, `order_id`);
\ No newline at end of file
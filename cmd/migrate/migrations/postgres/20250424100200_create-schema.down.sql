@@ -0,0 +1,32 @@
+DROP TABLE IF EXISTS product_import_errors;
+DROP TABLE IF EXISTS product_import_jobs;
+DROP TABLE IF EXISTS location_stock;
+DROP TABLE IF EXISTS stock_movements;
+DROP TABLE IF EXISTS stock_reservation_items;
+DROP TABLE IF EXISTS stock_locations;
+DROP TABLE IF EXISTS stock_reservations;
+DROP TABLE IF EXISTS user_addresses;
+DROP TABLE IF EXISTS shipping_zones;
+DROP TABLE IF EXISTS shipping_rate_tiers;
+DROP TABLE IF EXISTS shipping_methods;
+DROP TABLE IF EXISTS tax_rates;
+DROP TABLE IF EXISTS promotion_redemptions;
+DROP TABLE IF EXISTS promotion_targets;
+DROP TABLE IF EXISTS promotions;
+DROP TABLE IF EXISTS favorite_shops;
+DROP TABLE IF EXISTS wishlist_items;
+DROP TABLE IF EXISTS wishlists;
+DROP TABLE IF EXISTS review_votes;
+DROP TABLE IF EXISTS review_photos;
+DROP TABLE IF EXISTS product_reviews;
+DROP TABLE IF EXISTS shop_invitations;
+DROP TABLE IF EXISTS shop_members;
+DROP TABLE IF EXISTS shop_hours_exceptions;
+DROP TABLE IF EXISTS shop_opening_hours;
+DROP TABLE IF EXISTS image_variants;
+DROP TABLE IF EXISTS products;
+DROP TABLE IF EXISTS productcategories;
+DROP TABLE IF EXISTS shops;
+DROP TABLE IF EXISTS shopcategories;
+DROP TABLE IF EXISTS users;
+DROP FUNCTION IF EXISTS set_updated_at;
\ No newline at end of file
@@ -0,0 +1,457 @@
+-- The schema of the MySQL migrations up to this version. Later migrations are
+-- added to every dialect with the same version.
+
+CREATE FUNCTION set_updated_at() RETURNS trigger AS $$
+BEGIN
+  IF NEW IS DISTINCT FROM OLD AND NEW.updated_at IS NOT DISTINCT FROM OLD.updated_at THEN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TABLE IF NOT EXISTS users (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  first_name VARCHAR(255) NOT NULL,
+  last_name VARCHAR(255) NOT NULL,
+  email VARCHAR(255) NOT NULL UNIQUE,
+  phone_number VARCHAR(20) NOT NULL,
+  role VARCHAR(16) NOT NULL DEFAULT 'customer' CHECK (role IN ('customer', 'admin')),
+  password VARCHAR(255) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TRIGGER users_updated_at BEFORE UPDATE ON users FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS shopcategories (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMPTZ NULL DEFAULT NULL
+);
+CREATE INDEX shopcategories_deleted_at_idx ON shopcategories (deleted_at);
+CREATE TRIGGER shopcategories_updated_at BEFORE UPDATE ON shopcategories FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS shops (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users (id),
+  name VARCHAR(255) NOT NULL,
+  description TEXT DEFAULT NULL,
+  category_id INTEGER NOT NULL REFERENCES shopcategories (id),
+  opens_at VARCHAR(255) DEFAULT NULL,
+  closes_at VARCHAR(255) DEFAULT NULL,
+  address VARCHAR(255) DEFAULT NULL,
+  street VARCHAR(255) DEFAULT NULL,
+  city VARCHAR(255) DEFAULT NULL,
+  postal_code VARCHAR(32) DEFAULT NULL,
+  country CHAR(2) DEFAULT NULL,
+  latitude DOUBLE PRECISION DEFAULT NULL,
+  longitude DOUBLE PRECISION DEFAULT NULL,
+  image VARCHAR(255) DEFAULT NULL,
+  timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+  allocation_strategy VARCHAR(16) NOT NULL DEFAULT 'highest_stock' CHECK (allocation_strategy IN ('highest_stock', 'nearest')),
+  status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected', 'suspended')),
+  status_reason VARCHAR(1000) DEFAULT NULL,
+  reviewed_by INTEGER DEFAULT NULL REFERENCES users (id) ON DELETE SET NULL,
+  reviewed_at TIMESTAMPTZ NULL DEFAULT NULL,
+  rating_average DECIMAL(3, 2) NOT NULL DEFAULT 0,
+  rating_count INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMPTZ NULL DEFAULT NULL
+);
+CREATE INDEX shops_latitude_longitude_idx ON shops (latitude, longitude);
+CREATE INDEX shops_status_idx ON shops (status);
+CREATE INDEX shops_deleted_at_idx ON shops (deleted_at);
+CREATE TRIGGER shops_updated_at BEFORE UPDATE ON shops FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS productcategories (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMPTZ NULL DEFAULT NULL
+);
+CREATE INDEX productcategories_deleted_at_idx ON productcategories (deleted_at);
+CREATE TRIGGER productcategories_updated_at BEFORE UPDATE ON productcategories FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS products (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  shop_id INTEGER NOT NULL REFERENCES shops (id),
+  sku VARCHAR(64) DEFAULT NULL,
+  title VARCHAR(255) NOT NULL,
+  description TEXT DEFAULT NULL,
+  category_id INTEGER NOT NULL REFERENCES productcategories (id),
+  quantity INTEGER NOT NULL,
+  low_stock_threshold INTEGER DEFAULT NULL,
+  price_cents BIGINT NOT NULL DEFAULT 0,
+  tax_class VARCHAR(32) NOT NULL DEFAULT 'standard',
+  weight_grams INTEGER NOT NULL DEFAULT 0,
+  length_mm INTEGER NOT NULL DEFAULT 0,
+  width_mm INTEGER NOT NULL DEFAULT 0,
+  height_mm INTEGER NOT NULL DEFAULT 0,
+  image VARCHAR(255) DEFAULT NULL,
+  rating_average DECIMAL(3, 2) NOT NULL DEFAULT 0,
+  rating_count INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMPTZ NULL DEFAULT NULL
+);
+CREATE INDEX idx_products_sku ON products (sku, shop_id);
+CREATE INDEX products_deleted_at_idx ON products (deleted_at);
+CREATE TRIGGER products_updated_at BEFORE UPDATE ON products FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS image_variants (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  source VARCHAR(255) NOT NULL,
+  size VARCHAR(16) NOT NULL CHECK (size IN ('thumbnail', 'medium', 'large')),
+  format VARCHAR(16) NOT NULL CHECK (format IN ('jpeg', 'png', 'webp')),
+  width INTEGER NOT NULL,
+  height INTEGER NOT NULL,
+  url VARCHAR(255) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (source, size, format)
+);
+CREATE TRIGGER image_variants_updated_at BEFORE UPDATE ON image_variants FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS shop_opening_hours (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  shop_id INTEGER NOT NULL REFERENCES shops (id) ON DELETE CASCADE,
+  weekday SMALLINT NOT NULL,
+  opens_at TIME NOT NULL,
+  closes_at TIME NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX shop_opening_hours_shop_id_idx ON shop_opening_hours (shop_id);
+CREATE TRIGGER shop_opening_hours_updated_at BEFORE UPDATE ON shop_opening_hours FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS shop_hours_exceptions (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  shop_id INTEGER NOT NULL REFERENCES shops (id) ON DELETE CASCADE,
+  date DATE NOT NULL,
+  opens_at TIME DEFAULT NULL,
+  closes_at TIME DEFAULT NULL,
+  note VARCHAR(255) DEFAULT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX shop_hours_exceptions_shop_id_date_idx ON shop_hours_exceptions (shop_id, date);
+CREATE TRIGGER shop_hours_exceptions_updated_at BEFORE UPDATE ON shop_hours_exceptions FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS shop_members (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  shop_id INTEGER NOT NULL REFERENCES shops (id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  role VARCHAR(16) NOT NULL DEFAULT 'staff' CHECK (role IN ('owner', 'manager', 'staff')),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (shop_id, user_id)
+);
+CREATE INDEX shop_members_user_id_idx ON shop_members (user_id);
+CREATE TRIGGER shop_members_updated_at BEFORE UPDATE ON shop_members FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS shop_invitations (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  shop_id INTEGER NOT NULL REFERENCES shops (id) ON DELETE CASCADE,
+  email VARCHAR(255) NOT NULL,
+  role VARCHAR(16) NOT NULL DEFAULT 'staff' CHECK (role IN ('manager', 'staff')),
+  token CHAR(64) NOT NULL UNIQUE,
+  invited_by INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  expires_at TIMESTAMPTZ NOT NULL,
+  accepted_at TIMESTAMPTZ NULL DEFAULT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX shop_invitations_shop_id_idx ON shop_invitations (shop_id);
+CREATE TRIGGER shop_invitations_updated_at BEFORE UPDATE ON shop_invitations FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS product_reviews (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  rating SMALLINT NOT NULL,
+  body TEXT DEFAULT NULL,
+  status VARCHAR(16) NOT NULL DEFAULT 'visible' CHECK (status IN ('visible', 'hidden', 'flagged')),
+  moderation_reason VARCHAR(1000) DEFAULT NULL,
+  owner_reply TEXT DEFAULT NULL,
+  owner_replied_at TIMESTAMPTZ NULL DEFAULT NULL,
+  helpful_count INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (product_id, user_id)
+);
+CREATE INDEX product_reviews_status_idx ON product_reviews (status);
+CREATE TRIGGER product_reviews_updated_at BEFORE UPDATE ON product_reviews FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS review_photos (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  review_id INTEGER NOT NULL REFERENCES product_reviews (id) ON DELETE CASCADE,
+  url VARCHAR(255) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX review_photos_review_id_idx ON review_photos (review_id);
+CREATE TRIGGER review_photos_updated_at BEFORE UPDATE ON review_photos FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS review_votes (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  review_id INTEGER NOT NULL REFERENCES product_reviews (id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (review_id, user_id)
+);
+CREATE TRIGGER review_votes_updated_at BEFORE UPDATE ON review_votes FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS wishlists (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  name VARCHAR(100) NOT NULL,
+  is_default BOOLEAN NOT NULL DEFAULT FALSE,
+  share_token VARCHAR(64) DEFAULT NULL UNIQUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX wishlists_user_id_idx ON wishlists (user_id);
+CREATE TRIGGER wishlists_updated_at BEFORE UPDATE ON wishlists FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS wishlist_items (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  wishlist_id INTEGER NOT NULL REFERENCES wishlists (id) ON DELETE CASCADE,
+  product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (wishlist_id, product_id)
+);
+CREATE TRIGGER wishlist_items_updated_at BEFORE UPDATE ON wishlist_items FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS favorite_shops (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  shop_id INTEGER NOT NULL REFERENCES shops (id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (user_id, shop_id)
+);
+CREATE TRIGGER favorite_shops_updated_at BEFORE UPDATE ON favorite_shops FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS promotions (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  shop_id INTEGER DEFAULT NULL REFERENCES shops (id) ON DELETE CASCADE,
+  created_by INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  code VARCHAR(64) DEFAULT NULL UNIQUE,
+  type VARCHAR(16) NOT NULL CHECK (type IN ('percentage', 'fixed_amount', 'buy_x_get_y', 'free_shipping')),
+  value BIGINT NOT NULL DEFAULT 0,
+  buy_quantity INTEGER NOT NULL DEFAULT 0,
+  get_quantity INTEGER NOT NULL DEFAULT 0,
+  scope VARCHAR(16) NOT NULL DEFAULT 'all' CHECK (scope IN ('all', 'shop', 'category', 'product')),
+  min_subtotal_cents BIGINT NOT NULL DEFAULT 0,
+  starts_at TIMESTAMPTZ NULL DEFAULT NULL,
+  ends_at TIMESTAMPTZ NULL DEFAULT NULL,
+  usage_limit INTEGER DEFAULT NULL,
+  usage_limit_per_user INTEGER DEFAULT NULL,
+  usage_count INTEGER NOT NULL DEFAULT 0,
+  stackable BOOLEAN NOT NULL DEFAULT TRUE,
+  priority INTEGER NOT NULL DEFAULT 0,
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX promotions_active_starts_at_ends_at_idx ON promotions (active, starts_at, ends_at);
+CREATE TRIGGER promotions_updated_at BEFORE UPDATE ON promotions FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS promotion_targets (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  promotion_id INTEGER NOT NULL REFERENCES promotions (id) ON DELETE CASCADE,
+  target_id INTEGER NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (promotion_id, target_id)
+);
+CREATE TRIGGER promotion_targets_updated_at BEFORE UPDATE ON promotion_targets FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS promotion_redemptions (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  promotion_id INTEGER NOT NULL REFERENCES promotions (id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX promotion_redemptions_promotion_id_user_id_idx ON promotion_redemptions (promotion_id, user_id);
+CREATE TRIGGER promotion_redemptions_updated_at BEFORE UPDATE ON promotion_redemptions FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS tax_rates (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  country CHAR(2) NOT NULL,
+  region VARCHAR(64) DEFAULT NULL,
+  tax_class VARCHAR(32) NOT NULL DEFAULT 'standard',
+  name VARCHAR(100) NOT NULL,
+  rate_basis_points INTEGER NOT NULL,
+  inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (country, region, tax_class)
+);
+CREATE TRIGGER tax_rates_updated_at BEFORE UPDATE ON tax_rates FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS shipping_methods (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  shop_id INTEGER NOT NULL REFERENCES shops (id) ON DELETE CASCADE,
+  name VARCHAR(100) NOT NULL,
+  type VARCHAR(16) NOT NULL CHECK (type IN ('pickup', 'flat_rate', 'weight_tiers', 'price_tiers')),
+  rate_cents BIGINT NOT NULL DEFAULT 0,
+  free_over_cents BIGINT DEFAULT NULL,
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX shipping_methods_shop_id_idx ON shipping_methods (shop_id);
+CREATE TRIGGER shipping_methods_updated_at BEFORE UPDATE ON shipping_methods FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS shipping_rate_tiers (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  method_id INTEGER NOT NULL REFERENCES shipping_methods (id) ON DELETE CASCADE,
+  min_value BIGINT NOT NULL,
+  rate_cents BIGINT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (method_id, min_value)
+);
+CREATE TRIGGER shipping_rate_tiers_updated_at BEFORE UPDATE ON shipping_rate_tiers FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS shipping_zones (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  method_id INTEGER NOT NULL REFERENCES shipping_methods (id) ON DELETE CASCADE,
+  type VARCHAR(16) NOT NULL CHECK (type IN ('postal_range', 'radius')),
+  country CHAR(2) DEFAULT NULL,
+  postal_from VARCHAR(16) DEFAULT NULL,
+  postal_to VARCHAR(16) DEFAULT NULL,
+  radius_km DECIMAL(8, 3) DEFAULT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX shipping_zones_method_id_idx ON shipping_zones (method_id);
+CREATE TRIGGER shipping_zones_updated_at BEFORE UPDATE ON shipping_zones FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS user_addresses (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  label VARCHAR(50) DEFAULT NULL,
+  full_name VARCHAR(255) NOT NULL,
+  phone VARCHAR(32) DEFAULT NULL,
+  street VARCHAR(255) NOT NULL,
+  street2 VARCHAR(255) NOT NULL DEFAULT '',
+  city VARCHAR(100) NOT NULL,
+  region VARCHAR(100) NOT NULL DEFAULT '',
+  postal_code VARCHAR(16) NOT NULL DEFAULT '',
+  country CHAR(2) NOT NULL,
+  is_default BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX user_addresses_user_id_idx ON user_addresses (user_id);
+CREATE TRIGGER user_addresses_updated_at BEFORE UPDATE ON user_addresses FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS stock_reservations (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  status VARCHAR(16) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'committed', 'released', 'expired')),
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX stock_reservations_status_expires_at_idx ON stock_reservations (status, expires_at);
+CREATE TRIGGER stock_reservations_updated_at BEFORE UPDATE ON stock_reservations FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS stock_locations (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  shop_id INTEGER NOT NULL REFERENCES shops (id) ON DELETE CASCADE,
+  name VARCHAR(100) NOT NULL,
+  street VARCHAR(255) NOT NULL DEFAULT '',
+  street2 VARCHAR(255) NOT NULL DEFAULT '',
+  city VARCHAR(255) NOT NULL DEFAULT '',
+  region VARCHAR(100) NOT NULL DEFAULT '',
+  postal_code VARCHAR(32) NOT NULL DEFAULT '',
+  country VARCHAR(2) NOT NULL DEFAULT '',
+  latitude DOUBLE PRECISION DEFAULT NULL,
+  longitude DOUBLE PRECISION DEFAULT NULL,
+  is_default BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX stock_locations_shop_id_idx ON stock_locations (shop_id);
+CREATE TRIGGER stock_locations_updated_at BEFORE UPDATE ON stock_locations FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS stock_reservation_items (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  reservation_id INTEGER NOT NULL REFERENCES stock_reservations (id) ON DELETE CASCADE,
+  product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  location_id INTEGER NOT NULL REFERENCES stock_locations (id) ON DELETE CASCADE,
+  quantity INTEGER NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uq_reservation_product_location UNIQUE (reservation_id, product_id, location_id)
+);
+CREATE INDEX stock_reservation_items_product_id_idx ON stock_reservation_items (product_id);
+CREATE TRIGGER stock_reservation_items_updated_at BEFORE UPDATE ON stock_reservation_items FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  type VARCHAR(16) NOT NULL CHECK (type IN ('receipt', 'sale', 'adjustment', 'return', 'transfer')),
+  quantity INTEGER NOT NULL,
+  location_id INTEGER DEFAULT NULL REFERENCES stock_locations (id) ON DELETE SET NULL,
+  reason VARCHAR(255) DEFAULT NULL,
+  actor_id INTEGER DEFAULT NULL REFERENCES users (id) ON DELETE SET NULL,
+  reservation_id INTEGER DEFAULT NULL REFERENCES stock_reservations (id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX stock_movements_product_id_id_idx ON stock_movements (product_id, id);
+CREATE TRIGGER stock_movements_updated_at BEFORE UPDATE ON stock_movements FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS location_stock (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  location_id INTEGER NOT NULL REFERENCES stock_locations (id) ON DELETE CASCADE,
+  product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  quantity INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (location_id, product_id)
+);
+CREATE INDEX location_stock_product_id_idx ON location_stock (product_id);
+CREATE TRIGGER location_stock_updated_at BEFORE UPDATE ON location_stock FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS product_import_jobs (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  shop_id INTEGER NOT NULL REFERENCES shops (id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  format VARCHAR(16) NOT NULL CHECK (format IN ('csv', 'jsonl')),
+  dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+  status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+  total_rows INTEGER NOT NULL DEFAULT 0,
+  created_rows INTEGER NOT NULL DEFAULT 0,
+  updated_rows INTEGER NOT NULL DEFAULT 0,
+  failed_rows INTEGER NOT NULL DEFAULT 0,
+  error VARCHAR(255) DEFAULT NULL,
+  data BYTEA DEFAULT NULL,
+  started_at TIMESTAMPTZ NULL DEFAULT NULL,
+  finished_at TIMESTAMPTZ NULL DEFAULT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX product_import_jobs_status_idx ON product_import_jobs (status);
+CREATE TRIGGER product_import_jobs_updated_at BEFORE UPDATE ON product_import_jobs FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS product_import_errors (
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  job_id INTEGER NOT NULL REFERENCES product_import_jobs (id) ON DELETE CASCADE,
+  line INTEGER NOT NULL,
+  sku VARCHAR(64) NOT NULL DEFAULT '',
+  message VARCHAR(512) NOT NULL
+);
+CREATE INDEX product_import_errors_job_id_line_idx ON product_import_errors (job_id, line);
\ No newline at end of file
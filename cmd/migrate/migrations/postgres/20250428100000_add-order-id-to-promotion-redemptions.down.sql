@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS promotion_redemptions_promotion_id_order_id_idx;
+ALTER TABLE promotion_redemptions DROP COLUMN order_id;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE promotion_redemptions ADD COLUMN order_id VARCHAR(64) DEFAULT NULL;
+CREATE UNIQUE INDEX promotion_redemptions_promotion_id_order_id_idx ON promotion_redemptions (promotion_id, order_id);
\ No newline at end of file
@@ -0,0 +1,63 @@
+package configs
+
+import (
+	"net/url"
+	"path/filepath"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// DSN returns the data source name of the primary database for DB_DRIVER.
+// With DB_TLS=custom MySQL uses the TLS configuration registered under that
+// name, PostgreSQL reads the certificates itself.
+func (c Config) DSN() string {
+	switch c.DBDriver {
+	case "postgres":
+		query := url.Values{}
+		switch c.DBTLS {
+		case "false":
+			query.Set("sslmode", "disable")
+		case "true", "custom":
+			query.Set("sslmode", "verify-full")
+		case "skip-verify":
+			query.Set("sslmode", "require")
+		case "preferred":
+			query.Set("sslmode", "prefer")
+		}
+		if c.DBTLS == "custom" {
+			for key, file := range map[string]string{"sslrootcert": c.DBTLSCAFile, "sslcert": c.DBTLSCertFile, "sslkey": c.DBTLSKeyFile} {
+				if file != "" {
+					query.Set(key, file)
+				}
+			}
+		}
+
+		dsn := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(c.DBUser, c.DBPassword),
+			Host:     c.DBAddress,
+			Path:     "/" + c.DBName,
+			RawQuery: query.Encode(),
+		}
+		return dsn.String()
+	case "sqlite":
+		return c.DBPath
+	default:
+		cfg := mysql.Config{
+			User:                 c.DBUser,
+			Passwd:               c.DBPassword,
+			Addr:                 c.DBAddress,
+			DBName:               c.DBName,
+			Net:                  "tcp",
+			AllowNativePasswords: true,
+			ParseTime:            true,
+			TLSConfig:            c.DBTLS,
+		}
+		return cfg.FormatDSN()
+	}
+}
+
+// DriverMigrationsDir returns the directory with the migrations of DB_DRIVER.
+func (c Config) DriverMigrationsDir() string {
+	return filepath.Join(c.MigrationsDir, c.DBDriver)
+}
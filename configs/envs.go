@@ -1,60 +1,148 @@
 package configs
 
-import (
-	"fmt"
-	"os"
-	"strconv"
+import "strings"
 
-	"github.com/joho/godotenv"
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
 )
 
+// Config is the configuration of the API. Each setting is read from the
+// environment variable named by its env tag; the same name in lower case is its
+// key in a config file, and in lower case with dashes its command line flag.
+// Secrets can also be read from the file named by the variable with a _FILE
+// suffix, such as JWT_SECRET_FILE.
 type Config struct {
-	PublicHost                    string
-	Port                          string
-	DBUser                        string
-	DBPassword                    string
-	DBAddress                     string
-	DBName                        string
-	JWTSecret                     string
-	JWTExpirationInSeconds        int64
-	JWTRefreshExpirationInSeconds int64
+	Environment                   string `env:"APP_ENV"`
+	PublicHost                    string `env:"PUBLIC_HOST"`
+	Port                          string `env:"PORT"`
+	HTTPReadTimeoutSecs           int64  `env:"HTTP_READ_TIMEOUT_SECONDS"`
+	HTTPReadHeaderTimeoutSecs     int64  `env:"HTTP_READ_HEADER_TIMEOUT_SECONDS"`
+	HTTPWriteTimeoutSecs          int64  `env:"HTTP_WRITE_TIMEOUT_SECONDS"`
+	HTTPIdleTimeoutSecs           int64  `env:"HTTP_IDLE_TIMEOUT_SECONDS"`
+	HTTPMaxHeaderBytes            int64  `env:"HTTP_MAX_HEADER_BYTES"`
+	ShutdownTimeoutSecs           int64  `env:"SHUTDOWN_TIMEOUT_SECONDS"`
+	QueryTimeoutMs                int64  `env:"QUERY_TIMEOUT_MS"`
+	QueryTimeouts                 string `env:"QUERY_TIMEOUTS"`
+	LogFormat                     string `env:"LOG_FORMAT"`
+	LogLevel                      string `env:"LOG_LEVEL"`
+	MetricsPort                   string `env:"METRICS_PORT"`
+	TracingExporter               string `env:"TRACING_EXPORTER"`
+	TracingOTLPEndpoint           string `env:"TRACING_OTLP_ENDPOINT"`
+	TracingServiceName            string `env:"TRACING_SERVICE_NAME"`
+	DBDriver                      string `env:"DB_DRIVER"`
+	DBPath                        string `env:"DB_PATH"`
+	DBUser                        string `env:"DB_USER"`
+	DBPassword                    string `env:"DB_PASSWORD" secret:"true"`
+	DBHost                        string `env:"DB_HOST"`
+	DBPort                        string `env:"DB_PORT"`
+	DBAddress                     string `env:"-"`
+	DBName                        string `env:"DB_NAME"`
+	DBConnectMaxBackoffSecs       int64  `env:"DB_CONNECT_MAX_BACKOFF_SECONDS"`
+	DBMaxOpenConns                int64  `env:"DB_MAX_OPEN_CONNS"`
+	DBMaxIdleConns                int64  `env:"DB_MAX_IDLE_CONNS"`
+	DBConnMaxLifetimeSecs         int64  `env:"DB_CONN_MAX_LIFETIME_SECONDS"`
+	DBConnMaxIdleTimeSecs         int64  `env:"DB_CONN_MAX_IDLE_TIME_SECONDS"`
+	DBTLS                         string `env:"DB_TLS"`
+	DBTLSCAFile                   string `env:"DB_TLS_CA_FILE"`
+	DBTLSCertFile                 string `env:"DB_TLS_CERT_FILE"`
+	DBTLSKeyFile                  string `env:"DB_TLS_KEY_FILE"`
+	DBReplicaDSNs                 string `env:"DB_REPLICA_DSNS" secret:"true"`
+	MigrationsDir                 string `env:"MIGRATIONS_DIR"`
+	HealthCheckTimeoutMs          int64  `env:"HEALTH_CHECK_TIMEOUT_MS"`
+	JWTSecret                     string `env:"JWT_SECRET" secret:"true"`
+	JWTExpirationInSeconds        int64  `env:"JWT_EXPIRATION_IN_SECONDS"`
+	JWTRefreshExpirationInSeconds int64  `env:"JWT_REFRESH_EXPIRATION_IN_SECONDS"`
+	StaticDir                     string `env:"STATIC_DIR"`
+	ImageQueueSize                int64  `env:"IMAGE_QUEUE_SIZE"`
+	Geocoder                      string `env:"GEOCODER"`
+	GeocoderURL                   string `env:"GEOCODER_URL"`
+	GeocoderUserAgent             string `env:"GEOCODER_USER_AGENT"`
+	SMTPHost                      string `env:"SMTP_HOST"`
+	SMTPPort                      string `env:"SMTP_PORT"`
+	SMTPUsername                  string `env:"SMTP_USERNAME"`
+	SMTPPassword                  string `env:"SMTP_PASSWORD" secret:"true"`
+	MailFrom                      string `env:"MAIL_FROM"`
+	TrashRetentionDays            int64  `env:"TRASH_RETENTION_DAYS"`
+	TrashPurgeIntervalMinutes     int64  `env:"TRASH_PURGE_INTERVAL_MINUTES"`
+	TaxRounding                   string `env:"TAX_ROUNDING"`
+	ReservationTTLMinutes         int64  `env:"RESERVATION_TTL_MINUTES"`
+	ReservationExpiryIntervalSecs int64  `env:"RESERVATION_EXPIRY_INTERVAL_SECONDS"`
+	ImportMaxBytes                int64  `env:"IMPORT_MAX_BYTES"`
+	ImportQueueSize               int64  `env:"IMPORT_QUEUE_SIZE"`
 }
 
-var Envs = initConfig()
-
-func initConfig() Config {
-	godotenv.Load()
+// Envs is the configuration of the running process, set by main from Load
+// before anything else runs.
+var Envs Config
 
+// Default returns the configuration used for settings that are not set
+// anywhere else. The default secrets are only accepted in development.
+func Default() Config {
 	return Config{
-		PublicHost:                    getEnv("PUBLIC_HOST", "http://localhost"),
-		Port:                          getEnv("PORT", "8080"),
-		DBUser:                        getEnv("DB_USER", "root"),
-		DBPassword:                    getEnv("DB_PASSWORD", "password"),
-		DBAddress:                     fmt.Sprintf("%s:%s", getEnv("DB_HOST", "127.0.0.1"), getEnv("DB_PORT", "3306")),
-		DBName:                        getEnv("DB_NAME", "ecom_go"),
-		JWTSecret:                     getEnv("JWT_SECRET", "notsecret"),
-		JWTExpirationInSeconds:        getEnvAsInt("JWT_EXPIRATION_IN_SECONDS", 15*60),
-		JWTRefreshExpirationInSeconds: getEnvAsInt("JWT_REFRESH_EXPIRATION_IN_SECONDS", 3600*24*7),
-	}
-}
-
-func getEnv(key, fallback string) string {
-	if value, ok := os.LookupEnv(key); ok {
-		return value
+		Environment:                   EnvProduction,
+		PublicHost:                    "http://localhost",
+		Port:                          "8080",
+		HTTPReadTimeoutSecs:           30,
+		HTTPReadHeaderTimeoutSecs:     5,
+		HTTPWriteTimeoutSecs:          60,
+		HTTPIdleTimeoutSecs:           120,
+		HTTPMaxHeaderBytes:            1 << 20,
+		ShutdownTimeoutSecs:           30,
+		QueryTimeoutMs:                5000,
+		QueryTimeouts:                 "GET /api/v1/shops/{shop_id}/products/export=0",
+		LogFormat:                     "text",
+		LogLevel:                      "info",
+		MetricsPort:                   "",
+		TracingExporter:               "none",
+		TracingOTLPEndpoint:           "http://localhost:4318",
+		TracingServiceName:            "ecom_go",
+		DBDriver:                      "mysql",
+		DBPath:                        "ecom_go.db",
+		DBUser:                        "root",
+		DBPassword:                    "password",
+		DBHost:                        "127.0.0.1",
+		DBPort:                        "3306",
+		DBName:                        "ecom_go",
+		DBConnectMaxBackoffSecs:       30,
+		DBMaxOpenConns:                25,
+		DBMaxIdleConns:                10,
+		DBConnMaxLifetimeSecs:         300,
+		DBConnMaxIdleTimeSecs:         60,
+		DBTLS:                         "false",
+		MigrationsDir:                 "cmd/migrate/migrations",
+		HealthCheckTimeoutMs:          2000,
+		JWTSecret:                     "notsecret",
+		JWTExpirationInSeconds:        15 * 60,
+		JWTRefreshExpirationInSeconds: 3600 * 24 * 7,
+		StaticDir:                     "static",
+		ImageQueueSize:                100,
+		Geocoder:                      "offline",
+		GeocoderURL:                   "https://nominatim.openstreetmap.org",
+		GeocoderUserAgent:             "ecom_go",
+		SMTPHost:                      "",
+		SMTPPort:                      "587",
+		SMTPUsername:                  "",
+		SMTPPassword:                  "",
+		MailFrom:                      "no-reply@localhost",
+		TrashRetentionDays:            30,
+		TrashPurgeIntervalMinutes:     60,
+		TaxRounding:                   "half_up",
+		ReservationTTLMinutes:         15,
+		ReservationExpiryIntervalSecs: 60,
+		ImportMaxBytes:                10 << 20,
+		ImportQueueSize:               20,
 	}
-
-	return fallback
 }
 
-func getEnvAsInt(key string, fallback int64) int64 {
-	if value, ok := os.LookupEnv(key); ok {
-		i, err := strconv.ParseInt(value, 10, 64)
-		if err != nil {
-			return fallback
+// ReplicaDSNs returns the DSNs of the read replicas, given comma separated.
+func (c Config) ReplicaDSNs() []string {
+	var dsns []string
+	for _, dsn := range strings.Split(c.DBReplicaDSNs, ",") {
+		if dsn = strings.TrimSpace(dsn); dsn != "" {
+			dsns = append(dsns, dsn)
 		}
-
-		return i
 	}
 
-	return fallback
+	return dsns
 }
@@ -0,0 +1,212 @@
+package configs
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// fileSuffix turns the name of a secret into the name of the setting holding
+// the path of a file to read it from.
+const fileSuffix = "_FILE"
+
+// setting is a field of Config as it is named in every source.
+type setting struct {
+	index  int
+	env    string
+	secret bool
+}
+
+func (s setting) key() string {
+	return strings.ToLower(s.env)
+}
+
+func (s setting) flag() string {
+	return strings.ReplaceAll(s.key(), "_", "-")
+}
+
+var settings = configSettings()
+
+func configSettings() []setting {
+	t := reflect.TypeOf(Config{})
+
+	var settings []setting
+	for i := 0; i < t.NumField(); i++ {
+		env := t.Field(i).Tag.Get("env")
+		if env == "" || env == "-" {
+			continue
+		}
+
+		settings = append(settings, setting{index: i, env: env, secret: t.Field(i).Tag.Get("secret") == "true"})
+	}
+
+	return settings
+}
+
+// ValidationError lists every invalid setting of a configuration.
+type ValidationError struct {
+	Errs []error
+}
+
+func (e *ValidationError) Error() string {
+	return errors.Join(e.Errs...).Error()
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return e.Errs
+}
+
+// Load builds the configuration from, in increasing precedence, the defaults,
+// the YAML or TOML file given by the --config flag or CONFIG_FILE, the
+// environment, including a .env file, and the command line flags in args. It
+// returns the arguments left after the flags. Invalid settings are reported
+// together in a *ValidationError, so all of them can be fixed at once, with
+// the configuration still returned.
+func Load(args []string) (Config, []string, error) {
+	flags := flag.NewFlagSet("ecom_go", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	configFile := flags.String("config", "", "path of a YAML or TOML config file")
+	for _, s := range settings {
+		flags.String(s.flag(), "", s.env)
+		if s.secret {
+			flags.String(s.flag()+"-file", "", s.env+fileSuffix)
+		}
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return Config{}, nil, err
+	}
+
+	godotenv.Load()
+
+	cfg := Default()
+	var errs []error
+	add := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if *configFile == "" {
+		*configFile = os.Getenv("CONFIG_FILE")
+	}
+	if *configFile != "" {
+		values, err := readFile(*configFile)
+		add(err)
+		for _, key := range slices.Sorted(maps.Keys(values)) {
+			add(cfg.set(strings.ToUpper(key), values[key], *configFile))
+		}
+	}
+
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env); ok {
+			add(cfg.set(s.env, value, "environment"))
+		}
+		if value, ok := os.LookupEnv(s.env + fileSuffix); ok && s.secret {
+			add(cfg.set(s.env+fileSuffix, value, "environment"))
+		}
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "config" {
+			return
+		}
+		env := strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		add(cfg.set(env, f.Value.String(), "flag --"+f.Name))
+	})
+
+	cfg.DBAddress = fmt.Sprintf("%s:%s", cfg.DBHost, cfg.DBPort)
+
+	errs = append(errs, cfg.validate()...)
+
+	if len(errs) > 0 {
+		return cfg, flags.Args(), &ValidationError{Errs: errs}
+	}
+
+	return cfg, flags.Args(), nil
+}
+
+// set assigns the setting named env from source. A secret named with the
+// _FILE suffix is read from the file value names.
+func (c *Config) set(env string, value string, source string) error {
+	name, fromFile := strings.CutSuffix(env, fileSuffix)
+
+	for _, s := range settings {
+		if s.env != name || (fromFile && !s.secret) {
+			continue
+		}
+
+		if fromFile {
+			content, err := os.ReadFile(value)
+			if err != nil {
+				return fmt.Errorf("%s from %s: %w", env, source, err)
+			}
+			value = strings.TrimRight(string(content), "\r\n")
+		}
+
+		field := reflect.ValueOf(c).Elem().Field(s.index)
+		switch field.Kind() {
+		case reflect.Int64:
+			i, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return fmt.Errorf("%s from %s: %q is not an integer", s.env, source, value)
+			}
+			field.SetInt(i)
+		default:
+			field.SetString(value)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("unknown setting %s in %s", strings.ToLower(env), source)
+}
+
+// readFile reads a flat YAML or TOML file, chosen by its extension, into the
+// string form of its values.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	raw := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("config file %s must be .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		switch value.(type) {
+		case map[string]any, []any:
+			return nil, fmt.Errorf("config file %s: %s must be a single value", path, key)
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = fmt.Sprint(value)
+		}
+	}
+
+	return values, nil
+}
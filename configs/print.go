@@ -0,0 +1,42 @@
+package configs
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "[REDACTED]"
+
+// Print writes the configuration as a YAML config file with the secrets that
+// are set replaced by a placeholder.
+func (c Config) Print(w io.Writer) error {
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+
+	value := reflect.ValueOf(c)
+	for _, s := range settings {
+		field := value.Field(s.index)
+
+		str := fmt.Sprint(field.Interface())
+		if s.secret && str != "" {
+			str = redacted
+		}
+
+		// Strings are encoded so that those looking like numbers are quoted.
+		node := &yaml.Node{Kind: yaml.ScalarNode, Value: str}
+		if field.Kind() == reflect.String {
+			if err := node.Encode(str); err != nil {
+				return err
+			}
+		}
+
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: s.key()}, node)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	defer encoder.Close()
+
+	return encoder.Encode(doc)
+}
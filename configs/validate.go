@@ -0,0 +1,115 @@
+package configs
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5"
+)
+
+// validate returns every problem with the configuration.
+func (c *Config) validate() []error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	oneOf := func(name, value string, allowed ...string) {
+		for _, a := range allowed {
+			if value == a {
+				return
+			}
+		}
+		check(false, "%s must be one of %s, got %q", name, strings.Join(allowed, ", "), value)
+	}
+	port := func(name, value string) {
+		n, err := strconv.Atoi(value)
+		check(err == nil && n > 0 && n < 65536, "%s must be a port number, got %q", name, value)
+	}
+
+	oneOf("APP_ENV", c.Environment, EnvDevelopment, EnvProduction)
+
+	port("PORT", c.Port)
+	if c.MetricsPort != "" {
+		port("METRICS_PORT", c.MetricsPort)
+		check(c.MetricsPort != c.Port, "METRICS_PORT must differ from PORT")
+	}
+	_, err := url.ParseRequestURI(c.PublicHost)
+	check(err == nil, "PUBLIC_HOST must be a URL, got %q", c.PublicHost)
+
+	for _, setting := range []struct {
+		name  string
+		value int64
+	}{
+		{"HTTP_READ_TIMEOUT_SECONDS", c.HTTPReadTimeoutSecs},
+		{"HTTP_READ_HEADER_TIMEOUT_SECONDS", c.HTTPReadHeaderTimeoutSecs},
+		{"HTTP_WRITE_TIMEOUT_SECONDS", c.HTTPWriteTimeoutSecs},
+		{"HTTP_IDLE_TIMEOUT_SECONDS", c.HTTPIdleTimeoutSecs},
+		{"HTTP_MAX_HEADER_BYTES", c.HTTPMaxHeaderBytes},
+		{"SHUTDOWN_TIMEOUT_SECONDS", c.ShutdownTimeoutSecs},
+		{"DB_CONNECT_MAX_BACKOFF_SECONDS", c.DBConnectMaxBackoffSecs},
+		{"DB_MAX_OPEN_CONNS", c.DBMaxOpenConns},
+		{"DB_MAX_IDLE_CONNS", c.DBMaxIdleConns},
+		{"DB_CONN_MAX_LIFETIME_SECONDS", c.DBConnMaxLifetimeSecs},
+		{"DB_CONN_MAX_IDLE_TIME_SECONDS", c.DBConnMaxIdleTimeSecs},
+		{"HEALTH_CHECK_TIMEOUT_MS", c.HealthCheckTimeoutMs},
+		{"JWT_EXPIRATION_IN_SECONDS", c.JWTExpirationInSeconds},
+		{"JWT_REFRESH_EXPIRATION_IN_SECONDS", c.JWTRefreshExpirationInSeconds},
+		{"IMAGE_QUEUE_SIZE", c.ImageQueueSize},
+		{"TRASH_PURGE_INTERVAL_MINUTES", c.TrashPurgeIntervalMinutes},
+		{"RESERVATION_TTL_MINUTES", c.ReservationTTLMinutes},
+		{"RESERVATION_EXPIRY_INTERVAL_SECONDS", c.ReservationExpiryIntervalSecs},
+		{"IMPORT_MAX_BYTES", c.ImportMaxBytes},
+		{"IMPORT_QUEUE_SIZE", c.ImportQueueSize},
+	} {
+		check(setting.value > 0, "%s must be positive, got %d", setting.name, setting.value)
+	}
+	check(c.QueryTimeoutMs >= 0, "QUERY_TIMEOUT_MS must not be negative, got %d", c.QueryTimeoutMs)
+	check(c.TrashRetentionDays >= 0, "TRASH_RETENTION_DAYS must not be negative, got %d", c.TrashRetentionDays)
+
+	oneOf("LOG_FORMAT", c.LogFormat, "text", "json")
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.LogLevel)) == nil, "LOG_LEVEL must be debug, info, warn or error, got %q", c.LogLevel)
+	oneOf("TRACING_EXPORTER", c.TracingExporter, "none", "stdout", "otlp")
+	oneOf("GEOCODER", c.Geocoder, "offline", "nominatim")
+	oneOf("TAX_ROUNDING", c.TaxRounding, "half_up", "half_even", "down")
+
+	oneOf("DB_DRIVER", c.DBDriver, "mysql", "postgres", "sqlite")
+	if c.DBDriver == "sqlite" {
+		check(c.DBPath != "", "DB_PATH must be set")
+		check(len(c.ReplicaDSNs()) == 0, "DB_REPLICA_DSNS is not supported with DB_DRIVER=sqlite")
+	} else {
+		check(c.DBHost != "", "DB_HOST must be set")
+		port("DB_PORT", c.DBPort)
+		check(c.DBName != "", "DB_NAME must be set")
+	}
+	check(c.DBMaxIdleConns <= c.DBMaxOpenConns, "DB_MAX_IDLE_CONNS must not exceed DB_MAX_OPEN_CONNS")
+	oneOf("DB_TLS", c.DBTLS, "false", "true", "skip-verify", "preferred", "custom")
+	check(c.DBTLS != "custom" || c.DBTLSCAFile != "" || c.DBTLSCertFile != "", "DB_TLS=custom needs DB_TLS_CA_FILE or DB_TLS_CERT_FILE")
+	check((c.DBTLSCertFile == "") == (c.DBTLSKeyFile == ""), "DB_TLS_CERT_FILE and DB_TLS_KEY_FILE must be set together")
+	for _, dsn := range c.ReplicaDSNs() {
+		var err error
+		if c.DBDriver == "postgres" {
+			_, err = pgx.ParseConfig(dsn)
+		} else {
+			_, err = mysql.ParseDSN(dsn)
+		}
+		check(err == nil, "DB_REPLICA_DSNS has an invalid DSN: %v", err)
+	}
+	check(c.JWTSecret != "", "JWT_SECRET must be set")
+
+	// The defaults are public, anyone could sign tokens or log in to the
+	// database with them.
+	if c.Environment != EnvDevelopment {
+		defaults := Default()
+		check(c.JWTSecret != defaults.JWTSecret, "JWT_SECRET must be changed from the default outside development")
+		check(c.DBDriver == "sqlite" || c.DBPassword != defaults.DBPassword, "DB_PASSWORD must be changed from the default outside development")
+	}
+
+	return errs
+}
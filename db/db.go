@@ -1,17 +1,294 @@
 package db
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
-	"log"
+	"database/sql/driver"
+	"ecom_go/services/tracing"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/stdlib"
+	"modernc.org/sqlite"
 )
 
-func NewMySQLStorage(cfg mysql.Config) (*sql.DB, error) {
-	db, err := sql.Open("mysql", cfg.FormatDSN())
+// TLSConfigName is the name the TLS configuration built from custom
+// certificates is registered under, usable as tls=custom in DSNs.
+const TLSConfigName = "custom"
+
+// PoolOptions bounds the connections of a pool. Zero values keep the driver
+// defaults.
+type PoolOptions struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// Open opens a connection pool to the database of the dialect named by dsn:
+// a MySQL DSN, a PostgreSQL URL or keyword/value string, or the path of a
+// SQLite file. Every statement run on it is rewritten for the dialect and
+// traced.
+func Open(dialect Dialect, dsn string, pool PoolOptions) (*sql.DB, error) {
+	connector, err := connect(dialect, dsn)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
+	}
+
+	connector = tracing.WrapConnector(connector, dialect.system())
+	if dialect != MySQL {
+		connector = rebindConnector(connector, dialect)
+	}
+
+	db := sql.OpenDB(connector)
+	if pool.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(pool.MaxOpenConns)
+	}
+	if pool.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(pool.MaxIdleConns)
+	}
+	if pool.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	}
+	if pool.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
 	}
 
 	return db, nil
 }
+
+// connect returns the driver connector of the dialect. MySQL times are always
+// parsed into time.Time. SQLite enforces foreign keys, waits for locks instead
+// of failing, takes the write lock when a transaction begins, so two
+// transactions cannot deadlock upgrading their locks, and stores times in a
+// format it can compare.
+func connect(dialect Dialect, dsn string) (driver.Connector, error) {
+	switch dialect {
+	case MySQL:
+		cfg, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ParseTime = true
+
+		return mysql.NewConnector(cfg)
+	case Postgres:
+		return stdlib.GetDefaultDriver().(driver.DriverContext).OpenConnector(dsn)
+	case SQLite:
+		if !strings.HasPrefix(dsn, "file:") {
+			dsn = "file:" + dsn
+		}
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		dsn += separator + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate&_time_format=sqlite"
+
+		return dsnConnector{dsn: dsn, driver: &sqlite.Driver{}}, nil
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", dialect)
+	}
+}
+
+// dsnConnector connects with drivers that do not provide a connector.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// RegisterTLS registers the TLS configuration verifying the server against
+// the CA certificate in caFile and, when certFile and keyFile are set,
+// authenticating with that client certificate.
+func RegisterTLS(caFile, certFile, keyFile string) error {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates in %s", caFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return mysql.RegisterTLSConfig(TLSConfigName, config)
+}
+
+// DB routes statements between the primary database and its read replicas.
+// Queries made while serving a request go to a replica until the request
+// writes anything, from then on they go to the primary so the request reads
+// its own writes. Writes, transactions and queries made outside requests,
+// such as those of background workers, always go to the primary.
+type DB struct {
+	dialect  Dialect
+	primary  *sql.DB
+	replicas []*sql.DB
+	next     atomic.Uint32
+}
+
+func New(dialect Dialect, primary *sql.DB, replicas ...*sql.DB) *DB {
+	return &DB{dialect: dialect, primary: primary, replicas: replicas}
+}
+
+func (d *DB) Dialect() Dialect {
+	return d.dialect
+}
+
+func (d *DB) Primary() *sql.DB {
+	return d.primary
+}
+
+func (d *DB) Replicas() []*sql.DB {
+	return d.replicas
+}
+
+type contextKey string
+
+const routingKey contextKey = "dbRouting"
+
+// routing tracks whether a request wrote to the primary.
+type routing struct {
+	wrote atomic.Bool
+}
+
+// Middleware lets the queries of each request be routed to the replicas.
+func (d *DB) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(d.replicas) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), routingKey, &routing{})))
+	})
+}
+
+// reader returns the pool to send a query made with ctx to, spreading queries
+// over the replicas round-robin.
+func (d *DB) reader(ctx context.Context) *sql.DB {
+	state, ok := ctx.Value(routingKey).(*routing)
+	if !ok || state.wrote.Load() {
+		return d.primary
+	}
+
+	return d.replicas[d.next.Add(1)%uint32(len(d.replicas))]
+}
+
+// writer returns the primary and, for requests, sends all their later
+// queries there too.
+func (d *DB) writer(ctx context.Context) *sql.DB {
+	if state, ok := ctx.Value(routingKey).(*routing); ok {
+		state.wrote.Store(true)
+	}
+
+	return d.primary
+}
+
+func (d *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return d.reader(ctx).QueryContext(ctx, query, args...)
+}
+
+func (d *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return d.reader(ctx).QueryRowContext(ctx, query, args...)
+}
+
+func (d *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return d.writer(ctx).ExecContext(ctx, query, args...)
+}
+
+func (d *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return d.writer(ctx).BeginTx(ctx, opts)
+}
+
+// Scanner reads the columns of a row. It is implemented by both *sql.Row and
+// *sql.Rows, so one function scans a row of a lookup and of a listing alike.
+type Scanner interface {
+	Scan(dest ...any) error
+}
+
+// execQueryer runs statements on a pool or in a transaction.
+type execQueryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Insert runs the INSERT statement query on the primary and returns the id of
+// the row it inserted.
+func (d *DB) Insert(ctx context.Context, query string, args ...any) (int64, error) {
+	return d.insert(ctx, d.writer(ctx), query, args...)
+}
+
+// InsertTx runs the INSERT statement query in tx and returns the id of the row
+// it inserted.
+func (d *DB) InsertTx(ctx context.Context, tx *sql.Tx, query string, args ...any) (int64, error) {
+	return d.insert(ctx, tx, query, args...)
+}
+
+// insert reads the id back with RETURNING on PostgreSQL, whose driver has no
+// LastInsertId.
+func (d *DB) insert(ctx context.Context, q execQueryer, query string, args ...any) (int64, error) {
+	if d.dialect == Postgres {
+		var id int64
+		err := q.QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id)
+		return id, err
+	}
+
+	result, err := q.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// PingContext checks the primary and every replica.
+func (d *DB) PingContext(ctx context.Context) error {
+	if err := d.primary.PingContext(ctx); err != nil {
+		return err
+	}
+
+	for i, replica := range d.replicas {
+		if err := replica.PingContext(ctx); err != nil {
+			return fmt.Errorf("replica %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (d *DB) Close() error {
+	errs := []error{d.primary.Close()}
+	for _, replica := range d.replicas {
+		errs = append(errs, replica.Close())
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Dialect is the SQL database the stores run on. Stores are written in MySQL
+// syntax, with ? placeholders, and their statements are rewritten for the
+// other dialects.
+type Dialect string
+
+const (
+	MySQL    Dialect = "mysql"
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+// system is the name of the dialect in traces.
+func (d Dialect) system() string {
+	if d == Postgres {
+		return "postgresql"
+	}
+
+	return string(d)
+}
+
+var forUpdate = regexp.MustCompile(`(?i)\s+FOR\s+UPDATE\s*$`)
+
+// Rebind rewrites a statement written for MySQL into the dialect. PostgreSQL
+// numbers its placeholders and quotes identifiers with double quotes. SQLite
+// locks the whole database for a write transaction and has no row locks, so
+// FOR UPDATE is dropped.
+func (d Dialect) Rebind(query string) string {
+	switch d {
+	case Postgres:
+		var b strings.Builder
+		b.Grow(len(query) + 8)
+
+		n := 0
+		var quote rune
+		for _, r := range query {
+			switch {
+			case quote != 0:
+				if r == quote {
+					quote = 0
+				}
+			case r == '\'' || r == '"':
+				quote = r
+			case r == '`':
+				r = '"'
+			case r == '?':
+				n++
+				b.WriteByte('$')
+				b.WriteString(strconv.Itoa(n))
+				continue
+			}
+			b.WriteRune(r)
+		}
+
+		return b.String()
+	case SQLite:
+		return forUpdate.ReplaceAllString(query, "")
+	default:
+		return query
+	}
+}
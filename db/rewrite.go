@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+// rebindConnector returns a connector whose connections rewrite every
+// statement into the dialect before running it. Time arguments are passed in
+// UTC, as the MySQL driver does, so SQLite, which stores times as text,
+// compares them in the same zone.
+func rebindConnector(connector driver.Connector, dialect Dialect) driver.Connector {
+	return &dialectConnector{Connector: connector, dialect: dialect}
+}
+
+type dialectConnector struct {
+	driver.Connector
+	dialect Dialect
+}
+
+func (c *dialectConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dialectConn{conn: conn, dialect: c.dialect}, nil
+}
+
+type dialectConn struct {
+	conn    driver.Conn
+	dialect Dialect
+}
+
+func (c *dialectConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *dialectConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	query = c.dialect.Rebind(query)
+	if preparer, ok := c.conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+
+	return c.conn.Prepare(query)
+}
+
+func (c *dialectConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *dialectConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *dialectConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+
+	return c.conn.Begin()
+}
+
+func (c *dialectConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if queryer, ok := c.conn.(driver.QueryerContext); ok {
+		return queryer.QueryContext(ctx, c.dialect.Rebind(query), args)
+	}
+
+	return nil, driver.ErrSkip
+}
+
+func (c *dialectConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if execer, ok := c.conn.(driver.ExecerContext); ok {
+		return execer.ExecContext(ctx, c.dialect.Rebind(query), args)
+	}
+
+	return nil, driver.ErrSkip
+}
+
+func (c *dialectConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c *dialectConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+func (c *dialectConn) IsValid() bool {
+	if validator, ok := c.conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+
+	return true
+}
+
+// CheckNamedValue converts arguments like database/sql would, then moves
+// times to UTC.
+func (c *dialectConn) CheckNamedValue(value *driver.NamedValue) error {
+	err := driver.ErrSkip
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		err = checker.CheckNamedValue(value)
+	}
+	if errors.Is(err, driver.ErrSkip) {
+		value.Value, err = driver.DefaultParameterConverter.ConvertValue(value.Value)
+	}
+
+	if t, ok := value.Value.(time.Time); ok && err == nil {
+		value.Value = t.UTC()
+	}
+
+	return err
+}
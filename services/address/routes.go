@@ -0,0 +1,208 @@
+package address
+
+import (
+	"ecom_go/services/auth"
+	"ecom_go/types"
+	"ecom_go/utils"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+const maxAddressesPerUser = 20
+
+type Handler struct {
+	store     types.UserAddressStore
+	userStore types.UserStore
+}
+
+func NewHandler(store types.UserAddressStore, userStore types.UserStore) *Handler {
+	return &Handler{store: store, userStore: userStore}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/me/addresses", auth.WithJWTAuth(h.handleGetAddresses, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/me/addresses", auth.WithJWTAuth(h.handleCreateAddress, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/me/addresses/{address_id}", auth.WithJWTAuth(h.handleGetAddress, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/me/addresses/{address_id}", auth.WithJWTAuth(h.handleUpdateAddress, h.userStore)).Methods(http.MethodPut)
+	router.HandleFunc("/me/addresses/{address_id}", auth.WithJWTAuth(h.handleDeleteAddress, h.userStore)).Methods(http.MethodDelete)
+	router.HandleFunc("/me/addresses/{address_id}/default", auth.WithJWTAuth(h.handleSetDefaultAddress, h.userStore)).Methods(http.MethodPut)
+}
+
+// handleGetAddresses lists the address book of the user, default address
+// first.
+func (h *Handler) handleGetAddresses(w http.ResponseWriter, r *http.Request) {
+	addresses, err := h.store.GetUserAddresses(r.Context(), auth.GetUserIDFromContext(r.Context()))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, addresses)
+}
+
+func (h *Handler) handleCreateAddress(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	address, ok := parseAddress(w, r)
+	if !ok {
+		return
+	}
+
+	addresses, err := h.store.GetUserAddresses(r.Context(), userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if len(addresses) >= maxAddressesPerUser {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("an address book holds at most %d addresses", maxAddressesPerUser))
+		return
+	}
+
+	addressID, err := h.store.CreateUserAddress(r.Context(), userID, address)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	createdAddress, _ := h.store.GetUserAddressByID(r.Context(), addressID)
+	utils.WriteJSON(w, http.StatusCreated, createdAddress)
+}
+
+func (h *Handler) handleGetAddress(w http.ResponseWriter, r *http.Request) {
+	address, ok := h.getOwnAddress(w, r)
+	if !ok {
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, address)
+}
+
+func (h *Handler) handleUpdateAddress(w http.ResponseWriter, r *http.Request) {
+	existingAddress, ok := h.getOwnAddress(w, r)
+	if !ok {
+		return
+	}
+
+	address, ok := parseAddress(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.UpdateUserAddress(r.Context(), existingAddress.ID, address); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	updatedAddress, _ := h.store.GetUserAddressByID(r.Context(), existingAddress.ID)
+	utils.WriteJSON(w, http.StatusOK, updatedAddress)
+}
+
+func (h *Handler) handleDeleteAddress(w http.ResponseWriter, r *http.Request) {
+	existingAddress, ok := h.getOwnAddress(w, r)
+	if !ok {
+		return
+	}
+
+	rowsAffected, err := h.store.DeleteUserAddress(r.Context(), existingAddress.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to delete address: %w", err))
+		return
+	}
+
+	if rowsAffected == 0 {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("address not found"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleSetDefaultAddress(w http.ResponseWriter, r *http.Request) {
+	existingAddress, ok := h.getOwnAddress(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.SetDefaultUserAddress(r.Context(), existingAddress.ID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	updatedAddress, _ := h.store.GetUserAddressByID(r.Context(), existingAddress.ID)
+	utils.WriteJSON(w, http.StatusOK, updatedAddress)
+}
+
+func (h *Handler) getOwnAddress(w http.ResponseWriter, r *http.Request) (*types.UserAddress, bool) {
+	vars := mux.Vars(r)
+	str, ok := vars["address_id"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing address ID"))
+		return nil, false
+	}
+
+	addressID, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid address ID"))
+		return nil, false
+	}
+
+	address, err := h.store.GetUserAddressByID(r.Context(), addressID)
+	if err != nil || address.UserID != auth.GetUserIDFromContext(r.Context()) {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("address not found"))
+		return nil, false
+	}
+
+	return address, true
+}
+
+// parseAddress reads and validates the address in the request body, returning
+// it normalized with the phone number in E.164 format.
+func parseAddress(w http.ResponseWriter, r *http.Request) (types.CreateUpdateUserAddressPayload, bool) {
+	var payload types.CreateUpdateUserAddressPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return payload, false
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return payload, false
+	}
+
+	address := Normalize(types.Address{
+		Street:     payload.Street,
+		Street2:    payload.Street2,
+		City:       payload.City,
+		Region:     payload.Region,
+		PostalCode: payload.PostalCode,
+		Country:    payload.Country,
+	})
+	if err := Validate(address); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", err))
+		return payload, false
+	}
+
+	payload.Street = address.Street
+	payload.Street2 = address.Street2
+	payload.City = address.City
+	payload.Region = address.Region
+	payload.PostalCode = address.PostalCode
+	payload.Country = address.Country
+
+	if payload.Phone != nil {
+		phone, err := NormalizePhone(address.Country, *payload.Phone)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", err))
+			return payload, false
+		}
+		payload.Phone = &phone
+	}
+
+	return payload, true
+}
@@ -0,0 +1,205 @@
+package address
+
+import (
+	"context"
+	"database/sql"
+	"ecom_go/db"
+	"ecom_go/types"
+)
+
+type Store struct {
+	db *db.DB
+}
+
+func NewStore(db *db.DB) *Store {
+	return &Store{db: db}
+}
+
+const userAddressColumns = `id, user_id, label, full_name, phone, street, street2, city, region, postal_code, country,
+	is_default, created_at, updated_at`
+
+func (s *Store) GetUserAddresses(ctx context.Context, userID int) ([]types.UserAddress, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+userAddressColumns+" FROM user_addresses WHERE user_id = ? ORDER BY is_default DESC, id DESC", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	addresses := []types.UserAddress{}
+	for rows.Next() {
+		address, err := scanUserAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		addresses = append(addresses, *address)
+	}
+
+	return addresses, rows.Err()
+}
+
+func (s *Store) GetUserAddressByID(ctx context.Context, addressID int) (*types.UserAddress, error) {
+	address, err := scanUserAddress(s.db.QueryRowContext(ctx, "SELECT "+userAddressColumns+" FROM user_addresses WHERE id = ?", addressID))
+	if err != nil {
+		return nil, types.NotFound("address", err)
+	}
+
+	return address, nil
+}
+
+// CreateUserAddress adds an address to the address book of the user. The
+// first address of a user always becomes the default one.
+func (s *Store) CreateUserAddress(ctx context.Context, userID int, address types.CreateUpdateUserAddressPayload) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM user_addresses WHERE user_id = ?", userID).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	isDefault := address.IsDefault || count == 0
+	if isDefault {
+		if _, err := tx.ExecContext(ctx, "UPDATE user_addresses SET is_default = FALSE WHERE user_id = ?", userID); err != nil {
+			return 0, err
+		}
+	}
+
+	addressID, err := s.db.InsertTx(ctx, tx,
+		"INSERT INTO user_addresses (user_id, label, full_name, phone, street, street2, city, region, postal_code, country, is_default) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		userID, address.Label, address.FullName, address.Phone, address.Street, address.Street2, address.City,
+		address.Region, address.PostalCode, address.Country, isDefault)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(addressID), tx.Commit()
+}
+
+// UpdateUserAddress replaces the address. An address only stops being the
+// default one when another address is made the default.
+func (s *Store) UpdateUserAddress(ctx context.Context, addressID int, address types.CreateUpdateUserAddressPayload) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE user_addresses SET label = ?, full_name = ?, phone = ?, street = ?, street2 = ?, city = ?, region = ?, postal_code = ?, country = ? WHERE id = ?",
+		address.Label, address.FullName, address.Phone, address.Street, address.Street2, address.City,
+		address.Region, address.PostalCode, address.Country, addressID); err != nil {
+		return err
+	}
+
+	if address.IsDefault {
+		if err := setDefault(ctx, tx, addressID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteUserAddress removes the address. When it was the default address the
+// most recently added remaining address becomes the default.
+func (s *Store) DeleteUserAddress(ctx context.Context, addressID int) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var userID int
+	var isDefault bool
+	err = tx.QueryRowContext(ctx, "SELECT user_id, is_default FROM user_addresses WHERE id = ?", addressID).Scan(&userID, &isDefault)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM user_addresses WHERE id = ?", addressID)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if isDefault {
+		var nextID int
+		err := tx.QueryRowContext(ctx, "SELECT id FROM user_addresses WHERE user_id = ? ORDER BY id DESC LIMIT 1", userID).Scan(&nextID)
+		if err != nil && err != sql.ErrNoRows {
+			return 0, err
+		}
+
+		if err == nil {
+			if _, err := tx.ExecContext(ctx, "UPDATE user_addresses SET is_default = TRUE WHERE id = ?", nextID); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return rowsAffected, tx.Commit()
+}
+
+func (s *Store) SetDefaultUserAddress(ctx context.Context, addressID int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := setDefault(ctx, tx, addressID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// setDefault makes the address the only default address of its user.
+func setDefault(ctx context.Context, tx *sql.Tx, addressID int) error {
+	var userID int
+	if err := tx.QueryRowContext(ctx, "SELECT user_id FROM user_addresses WHERE id = ?", addressID).Scan(&userID); err != nil {
+		return types.NotFound("address", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE user_addresses SET is_default = (id = ?) WHERE user_id = ?", addressID, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func scanUserAddress(row db.Scanner) (*types.UserAddress, error) {
+	address := new(types.UserAddress)
+
+	err := row.Scan(
+		&address.ID,
+		&address.UserID,
+		&address.Label,
+		&address.FullName,
+		&address.Phone,
+		&address.Street,
+		&address.Street2,
+		&address.City,
+		&address.Region,
+		&address.PostalCode,
+		&address.Country,
+		&address.IsDefault,
+		&address.CreatedAt,
+		&address.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return address, nil
+}
@@ -0,0 +1,207 @@
+package address
+
+import (
+	"ecom_go/types"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// postalFormat describes the postal codes of a country. Pattern matches the
+// compact form of a code, upper cased without spaces or dashes. Separator is
+// put back at position At, counted from the end when negative.
+type postalFormat struct {
+	Pattern   *regexp.Regexp
+	Separator string
+	At        int
+}
+
+var (
+	fourDigits  = postalFormat{Pattern: regexp.MustCompile(`^\d{4}$`)}
+	fiveDigits  = postalFormat{Pattern: regexp.MustCompile(`^\d{5}$`)}
+	sixDigits   = postalFormat{Pattern: regexp.MustCompile(`^\d{6}$`)}
+	postalCodes = map[string]postalFormat{
+		"AT": fourDigits,
+		"AU": fourDigits,
+		"BE": fourDigits,
+		"BR": {Pattern: regexp.MustCompile(`^\d{8}$`), Separator: "-", At: 5},
+		"BY": sixDigits,
+		"CA": {Pattern: regexp.MustCompile(`^[A-Z]\d[A-Z]\d[A-Z]\d$`), Separator: " ", At: 3},
+		"CH": fourDigits,
+		"CN": sixDigits,
+		"DE": fiveDigits,
+		"DK": fourDigits,
+		"ES": fiveDigits,
+		"FI": fiveDigits,
+		"FR": fiveDigits,
+		"GB": {Pattern: regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]?\d[A-Z]{2}$`), Separator: " ", At: -3},
+		"IN": sixDigits,
+		"IT": fiveDigits,
+		"JP": {Pattern: regexp.MustCompile(`^\d{7}$`), Separator: "-", At: 3},
+		"KG": sixDigits,
+		"KZ": {Pattern: regexp.MustCompile(`^(\d{6}|[A-Z]\d{2}[A-Z]\d[A-Z]\d)$`)},
+		"MX": fiveDigits,
+		"NL": {Pattern: regexp.MustCompile(`^\d{4}[A-Z]{2}$`), Separator: " ", At: 4},
+		"NO": fourDigits,
+		"NZ": fourDigits,
+		"PL": {Pattern: regexp.MustCompile(`^\d{5}$`), Separator: "-", At: 2},
+		"PT": {Pattern: regexp.MustCompile(`^\d{7}$`), Separator: "-", At: 4},
+		"RU": sixDigits,
+		"SE": {Pattern: regexp.MustCompile(`^\d{5}$`), Separator: " ", At: 3},
+		"TR": fiveDigits,
+		"UA": fiveDigits,
+		"US": {Pattern: regexp.MustCompile(`^\d{5}(\d{4})?$`), Separator: "-", At: 5},
+		"ZA": fourDigits,
+	}
+)
+
+// phoneFormat describes the phone numbers of a country: its calling code,
+// the length range of national numbers and the trunk prefix dialled before
+// them inside the country.
+type phoneFormat struct {
+	CallingCode string
+	MinLength   int
+	MaxLength   int
+	Trunk       string
+}
+
+var phoneNumbers = map[string]phoneFormat{
+	"AT": {CallingCode: "43", MinLength: 4, MaxLength: 13, Trunk: "0"},
+	"AU": {CallingCode: "61", MinLength: 9, MaxLength: 9, Trunk: "0"},
+	"BE": {CallingCode: "32", MinLength: 8, MaxLength: 9, Trunk: "0"},
+	"BR": {CallingCode: "55", MinLength: 10, MaxLength: 11, Trunk: "0"},
+	"CA": {CallingCode: "1", MinLength: 10, MaxLength: 10, Trunk: "1"},
+	"CH": {CallingCode: "41", MinLength: 9, MaxLength: 9, Trunk: "0"},
+	"CN": {CallingCode: "86", MinLength: 9, MaxLength: 11, Trunk: "0"},
+	"DE": {CallingCode: "49", MinLength: 6, MaxLength: 13, Trunk: "0"},
+	"ES": {CallingCode: "34", MinLength: 9, MaxLength: 9},
+	"FR": {CallingCode: "33", MinLength: 9, MaxLength: 9, Trunk: "0"},
+	"GB": {CallingCode: "44", MinLength: 9, MaxLength: 10, Trunk: "0"},
+	"IN": {CallingCode: "91", MinLength: 10, MaxLength: 10, Trunk: "0"},
+	"IT": {CallingCode: "39", MinLength: 6, MaxLength: 11},
+	"JP": {CallingCode: "81", MinLength: 9, MaxLength: 10, Trunk: "0"},
+	"KG": {CallingCode: "996", MinLength: 9, MaxLength: 9, Trunk: "0"},
+	"KZ": {CallingCode: "7", MinLength: 10, MaxLength: 10, Trunk: "8"},
+	"NL": {CallingCode: "31", MinLength: 9, MaxLength: 9, Trunk: "0"},
+	"PL": {CallingCode: "48", MinLength: 9, MaxLength: 9},
+	"RU": {CallingCode: "7", MinLength: 10, MaxLength: 10, Trunk: "8"},
+	"SE": {CallingCode: "46", MinLength: 7, MaxLength: 9, Trunk: "0"},
+	"TR": {CallingCode: "90", MinLength: 10, MaxLength: 10, Trunk: "0"},
+	"UA": {CallingCode: "380", MinLength: 9, MaxLength: 9, Trunk: "0"},
+	"US": {CallingCode: "1", MinLength: 10, MaxLength: 10, Trunk: "1"},
+}
+
+// Normalize trims the fields of the address, upper cases the country and
+// writes the postal code the way its country does. Postal codes of countries
+// without a known format are only trimmed and upper cased.
+func Normalize(address types.Address) types.Address {
+	address.Street = collapseSpaces(address.Street)
+	address.Street2 = collapseSpaces(address.Street2)
+	address.City = collapseSpaces(address.City)
+	address.Region = collapseSpaces(address.Region)
+	address.Country = strings.ToUpper(strings.TrimSpace(address.Country))
+	address.PostalCode = NormalizePostalCode(address.Country, address.PostalCode)
+
+	return address
+}
+
+// NormalizePostalCode formats the postal code the way the country writes it.
+func NormalizePostalCode(country, code string) string {
+	code = strings.ToUpper(collapseSpaces(code))
+
+	format, ok := postalCodes[strings.ToUpper(country)]
+	if !ok {
+		return code
+	}
+
+	compact := strings.NewReplacer(" ", "", "-", "").Replace(code)
+	if !format.Pattern.MatchString(compact) {
+		return code
+	}
+
+	at := format.At
+	if at < 0 {
+		at += len(compact)
+	}
+	if format.Separator == "" || at <= 0 || at >= len(compact) {
+		return compact
+	}
+
+	return compact[:at] + format.Separator + compact[at:]
+}
+
+// Validate checks a normalized address. Countries with a known postal code
+// format require a postal code in that format.
+func Validate(address types.Address) error {
+	return ValidatePostalCode(address.Country, address.PostalCode)
+}
+
+// ValidatePostalCode checks the postal code against the format of the country.
+func ValidatePostalCode(country, code string) error {
+	format, ok := postalCodes[strings.ToUpper(country)]
+	if !ok {
+		return nil
+	}
+
+	if code == "" {
+		return fmt.Errorf("a postal code is required for %s", strings.ToUpper(country))
+	}
+
+	if !format.Pattern.MatchString(strings.NewReplacer(" ", "", "-", "").Replace(strings.ToUpper(code))) {
+		return fmt.Errorf("%q is not a valid postal code for %s", code, strings.ToUpper(country))
+	}
+
+	return nil
+}
+
+// NormalizePhone returns the phone number in E.164 format. Numbers written in
+// international format are accepted for any country, national numbers only
+// for countries with a known phone format.
+func NormalizePhone(country, phone string) (string, error) {
+	digits := strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "", "/", "").Replace(strings.TrimSpace(phone))
+	if strings.HasPrefix(digits, "00") {
+		digits = "+" + digits[2:]
+	}
+
+	international := strings.HasPrefix(digits, "+")
+	digits = strings.TrimPrefix(digits, "+")
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return "", fmt.Errorf("%q is not a valid phone number", phone)
+	}
+
+	format, known := phoneNumbers[strings.ToUpper(country)]
+
+	if international {
+		if len(digits) < 8 || len(digits) > 15 {
+			return "", fmt.Errorf("%q is not a valid phone number", phone)
+		}
+
+		if known && strings.HasPrefix(digits, format.CallingCode) {
+			if national := len(digits) - len(format.CallingCode); national < format.MinLength || national > format.MaxLength {
+				return "", fmt.Errorf("%q is not a valid phone number for %s", phone, strings.ToUpper(country))
+			}
+		}
+
+		return "+" + digits, nil
+	}
+
+	if !known {
+		return "", fmt.Errorf("phone numbers for %s must be written in international format, starting with +", strings.ToUpper(country))
+	}
+
+	if format.Trunk != "" && len(digits) > format.MaxLength && strings.HasPrefix(digits, format.Trunk) {
+		digits = strings.TrimPrefix(digits, format.Trunk)
+	} else if format.Trunk == "0" && strings.HasPrefix(digits, "0") {
+		digits = digits[1:]
+	}
+
+	if len(digits) < format.MinLength || len(digits) > format.MaxLength {
+		return "", fmt.Errorf("%q is not a valid phone number for %s", phone, strings.ToUpper(country))
+	}
+
+	return "+" + format.CallingCode + digits, nil
+}
+
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -3,15 +3,17 @@ package auth
 import (
 	"context"
 	"ecom_go/configs"
+	"ecom_go/services/logging"
+	"ecom_go/services/tracing"
 	"ecom_go/types"
 	"ecom_go/utils"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type contextKey string
@@ -20,39 +22,27 @@ const UserKey contextKey = "userID"
 
 func WithJWTAuth(handlerFunc http.HandlerFunc, store types.UserStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		tokenString := utils.GetTokenFromRequest(r)
+		logger := logging.FromContext(r.Context())
 
-		token, err := ValidateJWT(tokenString)
+		userID, err := validateRequestToken(r)
 		if err != nil {
-			log.Printf("failed to validate token: %v", err)
+			logger.Warn("failed to validate token", "error", err)
 			permissionDenied(w)
 			return
 		}
 
-		if !token.Valid {
-			log.Println("invalid token")
-			permissionDenied(w)
+		u, err := store.GetUserByID(r.Context(), userID)
+		if utils.IsContextError(err) {
+			utils.WriteError(w, http.StatusInternalServerError, err)
 			return
 		}
-
-		claims := token.Claims.(jwt.MapClaims)
-		str := claims["userID"].(string)
-
-		userID, err := strconv.Atoi(str)
 		if err != nil {
-			log.Printf("failed to convert userID to int: %v", err)
+			logger.Warn("failed to get user of token", "user_id", userID, "error", err)
 			permissionDenied(w)
 			return
 		}
 
-		u, err := store.GetUserByID(userID)
-		if err != nil {
-			log.Printf("failed to get user by id: %v", err)
-			permissionDenied(w)
-			return
-		}
-
-		ctx := r.Context()
+		ctx := logging.SetUser(r.Context(), u.ID)
 		ctx = context.WithValue(ctx, UserKey, u.ID)
 		r = r.WithContext(ctx)
 
@@ -62,44 +52,32 @@ func WithJWTAuth(handlerFunc http.HandlerFunc, store types.UserStore) http.Handl
 
 func WithAdminJWTAuth(handlerFunc http.HandlerFunc, store types.UserStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		tokenString := utils.GetTokenFromRequest(r)
+		logger := logging.FromContext(r.Context())
 
-		token, err := ValidateJWT(tokenString)
+		userID, err := validateRequestToken(r)
 		if err != nil {
-			log.Printf("failed to validate token: %v", err)
-			permissionDenied(w)
-			return
-		}
-
-		if !token.Valid {
-			log.Println("invalid token")
+			logger.Warn("failed to validate token", "error", err)
 			permissionDenied(w)
 			return
 		}
 
-		claims := token.Claims.(jwt.MapClaims)
-		str := claims["userID"].(string)
-
-		userID, err := strconv.Atoi(str)
-		if err != nil {
-			log.Printf("failed to convert userID to int: %v", err)
-			permissionDenied(w)
+		u, err := store.GetUserByID(r.Context(), userID)
+		if utils.IsContextError(err) {
+			utils.WriteError(w, http.StatusInternalServerError, err)
 			return
 		}
-
-		u, err := store.GetUserByID(userID)
 		if err != nil {
-			log.Printf("failed to get user by id: %v", err)
+			logger.Warn("failed to get user of token", "user_id", userID, "error", err)
 			permissionDenied(w)
 			return
 		}
 		if u.Role != "admin" {
-			log.Printf("permission denied")
+			logger.Warn("permission denied, user is not an admin", "user_id", u.ID)
 			permissionDenied(w)
 			return
 		}
 
-		ctx := r.Context()
+		ctx := logging.SetUser(r.Context(), u.ID)
 		ctx = context.WithValue(ctx, UserKey, u.ID)
 		r = r.WithContext(ctx)
 
@@ -131,6 +109,47 @@ func ValidateJWT(tokenString string) (*jwt.Token, error) {
 	})
 }
 
+// validateRequestToken validates the JWT of the request and returns the ID of
+// the user it was issued to.
+func validateRequestToken(r *http.Request) (int, error) {
+	_, span := tracing.Start(r.Context(), "auth.ValidateJWT")
+	defer span.End()
+
+	userID, err := userIDFromToken(utils.GetTokenFromRequest(r))
+	if err != nil {
+		tracing.RecordError(span, err)
+		return 0, err
+	}
+
+	span.SetAttributes(attribute.Int("user.id", userID))
+
+	return userID, nil
+}
+
+func userIDFromToken(tokenString string) (int, error) {
+	token, err := ValidateJWT(tokenString)
+	if err != nil {
+		return 0, err
+	}
+
+	if !token.Valid {
+		return 0, fmt.Errorf("invalid token")
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	str, ok := claims["userID"].(string)
+	if !ok {
+		return 0, fmt.Errorf("missing user ID in token")
+	}
+
+	userID, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("invalid user ID in token: %w", err)
+	}
+
+	return userID, nil
+}
+
 func permissionDenied(w http.ResponseWriter) {
 	utils.WriteError(w, http.StatusForbidden, fmt.Errorf("permission denied"))
 }
@@ -0,0 +1,299 @@
+package catalog
+
+import (
+	"bufio"
+	"bytes"
+	"ecom_go/types"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Columns are the fields of a product in import and export files, in the
+// order they are exported.
+var Columns = []string{
+	"sku", "title", "description", "category_id", "quantity", "price_cents", "tax_class",
+	"weight_grams", "length_mm", "width_mm", "height_mm", "image",
+}
+
+// Row is a product read from an import file. Fields missing from the row are
+// nil and keep their current value when the product already exists.
+type Row struct {
+	SKU         string  `json:"sku"`
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+	CategoryID  *int    `json:"category_id,omitempty"`
+	Quantity    *int    `json:"quantity,omitempty"`
+	PriceCents  *int64  `json:"price_cents,omitempty"`
+	TaxClass    *string `json:"tax_class,omitempty"`
+	WeightGrams *int    `json:"weight_grams,omitempty"`
+	LengthMm    *int    `json:"length_mm,omitempty"`
+	WidthMm     *int    `json:"width_mm,omitempty"`
+	HeightMm    *int    `json:"height_mm,omitempty"`
+	Image       *string `json:"image,omitempty"`
+}
+
+// maxLineBytes is the longest JSON Lines row that is read.
+const maxLineBytes = 1 << 20
+
+// ReadRows calls fn for each row of the file, numbering rows from 1. A row
+// that cannot be parsed is passed with its error and reading goes on. The
+// returned error means the file as a whole cannot be read, such as a CSV
+// header with unknown columns.
+func ReadRows(format string, data []byte, fn func(line int, row Row, err error)) error {
+	switch format {
+	case types.ImportFormatCSV:
+		return readCSV(bytes.NewReader(data), fn)
+	case types.ImportFormatJSONL:
+		return readJSONL(bytes.NewReader(data), fn)
+	default:
+		return fmt.Errorf("unsupported format %s", format)
+	}
+}
+
+func readCSV(r io.Reader, fn func(line int, row Row, err error)) error {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return fmt.Errorf("file is empty")
+	}
+	if err != nil {
+		return fmt.Errorf("invalid header: %v", err)
+	}
+
+	// Spreadsheets often save CSV files with a byte order mark.
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+
+	seen := map[string]bool{}
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if !isColumn(column) {
+			return fmt.Errorf("invalid header: unknown column %q", column)
+		}
+		if seen[column] {
+			return fmt.Errorf("invalid header: duplicate column %q", column)
+		}
+
+		seen[column] = true
+		header[i] = column
+	}
+
+	if !seen["sku"] {
+		return fmt.Errorf("invalid header: missing column \"sku\"")
+	}
+
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		line++
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			fn(line, Row{}, parseErr.Err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		row, err := parseRecord(header, record)
+		fn(line, row, err)
+	}
+}
+
+// parseRecord maps a CSV record onto a row. Empty cells are left out, so they
+// keep the current value of an existing product.
+func parseRecord(header []string, record []string) (Row, error) {
+	var row Row
+	for i, column := range header {
+		value := strings.TrimSpace(record[i])
+		if column == "sku" {
+			row.SKU = value
+			continue
+		}
+		if value == "" {
+			continue
+		}
+
+		var err error
+		switch column {
+		case "title":
+			row.Title = &value
+		case "description":
+			row.Description = &value
+		case "category_id":
+			row.CategoryID, err = parseInt(value)
+		case "quantity":
+			row.Quantity, err = parseInt(value)
+		case "price_cents":
+			var price int64
+			price, err = strconv.ParseInt(value, 10, 64)
+			row.PriceCents = &price
+		case "tax_class":
+			row.TaxClass = &value
+		case "weight_grams":
+			row.WeightGrams, err = parseInt(value)
+		case "length_mm":
+			row.LengthMm, err = parseInt(value)
+		case "width_mm":
+			row.WidthMm, err = parseInt(value)
+		case "height_mm":
+			row.HeightMm, err = parseInt(value)
+		case "image":
+			row.Image = &value
+		}
+
+		if err != nil {
+			return row, fmt.Errorf("invalid %s %q", column, value)
+		}
+	}
+
+	return row, nil
+}
+
+func parseInt(value string) (*int, error) {
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &i, nil
+}
+
+func isColumn(column string) bool {
+	for _, c := range Columns {
+		if c == column {
+			return true
+		}
+	}
+
+	return false
+}
+
+func readJSONL(r io.Reader, fn func(line int, row Row, err error)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+
+	line := 0
+	for scanner.Scan() {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		line++
+
+		var row Row
+		decoder := json.NewDecoder(bytes.NewReader(text))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row); err != nil {
+			fn(line, Row{}, err)
+			continue
+		}
+
+		row.SKU = strings.TrimSpace(row.SKU)
+		fn(line, row, nil)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("row %d: %v", line+1, err)
+	}
+
+	return nil
+}
+
+// RowWriter writes products to an export file.
+type RowWriter interface {
+	Write(product *types.Product) error
+	Flush() error
+}
+
+func NewRowWriter(format string, w io.Writer) (RowWriter, error) {
+	switch format {
+	case types.ImportFormatCSV:
+		return newCSVWriter(w)
+	case types.ImportFormatJSONL:
+		return &jsonlWriter{encoder: json.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %s", format)
+	}
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(Columns); err != nil {
+		return nil, err
+	}
+
+	return &csvWriter{writer: writer}, nil
+}
+
+func (c *csvWriter) Write(product *types.Product) error {
+	sku := ""
+	if product.SKU != nil {
+		sku = *product.SKU
+	}
+
+	return c.writer.Write([]string{
+		sku,
+		product.Title,
+		product.Description,
+		strconv.Itoa(product.CategoryID),
+		strconv.Itoa(product.Quantity),
+		strconv.FormatInt(product.PriceCents, 10),
+		product.TaxClass,
+		strconv.Itoa(product.WeightGrams),
+		strconv.Itoa(product.LengthMm),
+		strconv.Itoa(product.WidthMm),
+		strconv.Itoa(product.HeightMm),
+		product.Image,
+	})
+}
+
+func (c *csvWriter) Flush() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+type jsonlWriter struct {
+	encoder *json.Encoder
+}
+
+func (j *jsonlWriter) Write(product *types.Product) error {
+	row := Row{
+		Title:       &product.Title,
+		Description: &product.Description,
+		CategoryID:  &product.CategoryID,
+		Quantity:    &product.Quantity,
+		PriceCents:  &product.PriceCents,
+		TaxClass:    &product.TaxClass,
+		WeightGrams: &product.WeightGrams,
+		LengthMm:    &product.LengthMm,
+		WidthMm:     &product.WidthMm,
+		HeightMm:    &product.HeightMm,
+		Image:       &product.Image,
+	}
+	if product.SKU != nil {
+		row.SKU = *product.SKU
+	}
+
+	return j.encoder.Encode(row)
+}
+
+func (j *jsonlWriter) Flush() error {
+	return nil
+}
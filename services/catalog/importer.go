@@ -0,0 +1,392 @@
+package catalog
+
+import (
+	"context"
+	"ecom_go/types"
+	"ecom_go/utils"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// maxRowErrors caps the row errors kept for a job, so a file that is wrong
+// throughout does not flood the database. The failed row count stays exact.
+const maxRowErrors = 1000
+
+// Importer runs product import jobs in the background, one at a time. Jobs
+// that were queued or interrupted when the server stopped are run again on
+// start; rows are upserted by SKU, so running a job twice is harmless.
+type Importer struct {
+	store          types.ProductImportStore
+	productStore   types.ProductStore
+	categoryStore  types.ProductCategoryStore
+	inventoryStore types.InventoryStore
+	alerter        types.StockAlerter
+	images         types.ImageProcessor
+	jobs           chan int
+	stop           chan struct{}
+	wg             sync.WaitGroup
+	running        atomic.Bool
+}
+
+func NewImporter(store types.ProductImportStore, productStore types.ProductStore, categoryStore types.ProductCategoryStore, inventoryStore types.InventoryStore, alerter types.StockAlerter, images types.ImageProcessor, queueSize int) *Importer {
+	return &Importer{
+		store:          store,
+		productStore:   productStore,
+		categoryStore:  categoryStore,
+		inventoryStore: inventoryStore,
+		alerter:        alerter,
+		images:         images,
+		jobs:           make(chan int, queueSize),
+		stop:           make(chan struct{}),
+	}
+}
+
+func (i *Importer) Start() {
+	i.running.Store(true)
+	i.wg.Add(1)
+	go func() {
+		defer i.wg.Done()
+		defer i.running.Store(false)
+
+		ctx := context.Background()
+
+		unfinished, err := i.store.GetUnfinishedImportJobIDs(ctx)
+		if err != nil {
+			slog.Error("failed to load unfinished import jobs", "error", err)
+		}
+		for _, jobID := range unfinished {
+			i.run(ctx, jobID)
+		}
+
+		for {
+			select {
+			case jobID := <-i.jobs:
+				i.run(ctx, jobID)
+			case <-i.stop:
+				i.drain(ctx)
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops accepting new jobs and waits for queued ones to finish. The
+// jobs channel is never closed, so late calls to Enqueue from requests still
+// running are safe.
+func (i *Importer) Stop() {
+	close(i.stop)
+	i.wg.Wait()
+}
+
+// drain runs the jobs queued before Stop.
+func (i *Importer) drain(ctx context.Context) {
+	for {
+		select {
+		case jobID := <-i.jobs:
+			i.run(ctx, jobID)
+		default:
+			return
+		}
+	}
+}
+
+func (i *Importer) Name() string {
+	return "product_importer"
+}
+
+// Check reports whether the product importer is running.
+func (i *Importer) Check(ctx context.Context) error {
+	if !i.running.Load() {
+		return fmt.Errorf("product importer is not running")
+	}
+
+	return nil
+}
+
+// Enqueue schedules the job without blocking the caller. It reports false
+// when the queue is full or the importer is stopped.
+func (i *Importer) Enqueue(jobID int) bool {
+	select {
+	case <-i.stop:
+		return false
+	default:
+	}
+
+	select {
+	case i.jobs <- jobID:
+		return true
+	default:
+		return false
+	}
+}
+
+func (i *Importer) run(ctx context.Context, jobID int) {
+	job, err := i.store.GetImportJobByID(ctx, jobID)
+	if err != nil {
+		slog.Error("failed to load import job", "job_id", jobID, "error", err)
+		return
+	}
+
+	if job.Status != types.ImportStatusPending && job.Status != types.ImportStatusRunning {
+		return
+	}
+
+	if err := i.store.StartImportJob(ctx, job.ID); err != nil {
+		slog.Error("failed to start import job", "job_id", job.ID, "error", err)
+		return
+	}
+
+	i.process(ctx, job)
+
+	if err := i.store.FinishImportJob(ctx, *job); err != nil {
+		slog.Error("failed to finish import job", "job_id", job.ID, "error", err)
+	}
+}
+
+// process reads the file of the job and applies its rows, recording the
+// outcome on the job.
+func (i *Importer) process(ctx context.Context, job *types.ProductImportJob) {
+	job.TotalRows, job.CreatedRows, job.UpdatedRows, job.FailedRows = 0, 0, 0, 0
+	job.RowErrors = []types.ImportRowError{}
+
+	data, err := i.store.GetImportJobData(ctx, job.ID)
+	if err == nil {
+		run := &importRun{ctx: ctx, importer: i, job: job, lines: map[string]int{}, categories: map[int]bool{}}
+		err = ReadRows(job.Format, data, run.apply)
+	}
+
+	if err != nil {
+		message := err.Error()
+		job.Status = types.ImportStatusFailed
+		job.Error = &message
+		return
+	}
+
+	job.Status = types.ImportStatusCompleted
+}
+
+// importRun holds the state of a single run over the rows of a job.
+type importRun struct {
+	ctx      context.Context
+	importer *Importer
+	job      *types.ProductImportJob
+	// lines maps the SKUs seen so far to the row they were first seen in.
+	lines      map[string]int
+	categories map[int]bool
+}
+
+func (r *importRun) apply(line int, row Row, err error) {
+	r.job.TotalRows++
+
+	if err == nil {
+		err = r.validateSKU(line, row.SKU)
+	}
+	if err == nil {
+		var created bool
+		created, err = r.upsert(row)
+		if err == nil && created {
+			r.job.CreatedRows++
+		} else if err == nil {
+			r.job.UpdatedRows++
+		}
+	}
+
+	if err != nil {
+		r.job.FailedRows++
+		if len(r.job.RowErrors) < maxRowErrors {
+			r.job.RowErrors = append(r.job.RowErrors, types.ImportRowError{Row: line, SKU: row.SKU, Message: err.Error()})
+		}
+	}
+}
+
+func (r *importRun) validateSKU(line int, sku string) error {
+	if sku == "" {
+		return fmt.Errorf("missing SKU")
+	}
+
+	if err := utils.Validate.Var(sku, "max=64,printascii"); err != nil {
+		return fmt.Errorf("invalid SKU")
+	}
+
+	if first, ok := r.lines[sku]; ok {
+		return fmt.Errorf("duplicate SKU, first used in row %d", first)
+	}
+	r.lines[sku] = line
+
+	return nil
+}
+
+// upsert updates the product of the shop with the SKU of the row, or creates
+// it when there is none. It reports whether the product was created.
+func (r *importRun) upsert(row Row) (bool, error) {
+	existing, err := r.importer.productStore.GetProductBySKU(r.ctx, r.job.ShopID, row.SKU)
+	if errors.Is(err, types.ErrNotFound) {
+		return true, r.create(row)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return false, r.update(existing, row)
+}
+
+func (r *importRun) create(row Row) error {
+	product := types.CreateProductPayload{
+		UserID:   r.job.UserID,
+		ShopID:   r.job.ShopID,
+		SKU:      &row.SKU,
+		TaxClass: types.TaxClassStandard,
+	}
+
+	setString(&product.Title, row.Title)
+	setString(&product.Description, row.Description)
+	setInt(&product.CategoryID, row.CategoryID)
+	setInt(&product.Quantity, row.Quantity)
+	if row.PriceCents != nil {
+		product.PriceCents = *row.PriceCents
+	}
+	setString(&product.TaxClass, row.TaxClass)
+	setInt(&product.WeightGrams, row.WeightGrams)
+	setInt(&product.LengthMm, row.LengthMm)
+	setInt(&product.WidthMm, row.WidthMm)
+	setInt(&product.HeightMm, row.HeightMm)
+	setString(&product.Image, row.Image)
+
+	if err := utils.Validate.Struct(product); err != nil {
+		return invalidRow(err)
+	}
+
+	if err := r.checkCategory(product.CategoryID); err != nil {
+		return err
+	}
+
+	if r.job.DryRun {
+		return nil
+	}
+
+	if _, err := r.importer.productStore.CreateProduct(r.ctx, product); err != nil {
+		return fmt.Errorf("failed to create product: %v", err)
+	}
+
+	r.importer.images.Enqueue(product.Image)
+
+	return nil
+}
+
+func (r *importRun) update(existing *types.Product, row Row) error {
+	product := types.UpdateProductPayload{
+		SKU:         existing.SKU,
+		Title:       orDefault(row.Title, existing.Title),
+		Description: orDefault(row.Description, existing.Description),
+		CategoryID:  orDefault(row.CategoryID, existing.CategoryID),
+		Quantity:    orDefault(row.Quantity, existing.Quantity),
+		PriceCents:  orDefault(row.PriceCents, existing.PriceCents),
+		TaxClass:    orDefault(row.TaxClass, existing.TaxClass),
+		WeightGrams: orDefault(row.WeightGrams, existing.WeightGrams),
+		LengthMm:    orDefault(row.LengthMm, existing.LengthMm),
+		WidthMm:     orDefault(row.WidthMm, existing.WidthMm),
+		HeightMm:    orDefault(row.HeightMm, existing.HeightMm),
+		Image:       orDefaultNonEmpty(row.Image, existing.Image),
+	}
+
+	if err := utils.Validate.Struct(product); err != nil {
+		return invalidRow(err)
+	}
+
+	if *product.CategoryID != existing.CategoryID {
+		if err := r.checkCategory(*product.CategoryID); err != nil {
+			return err
+		}
+	}
+
+	if r.job.DryRun {
+		return nil
+	}
+
+	if err := r.importer.productStore.UpdateProduct(r.ctx, existing.ID, product); err != nil {
+		return fmt.Errorf("failed to update product: %v", err)
+	}
+
+	// Like a quantity set through the product API, an imported quantity is
+	// booked as an adjustment in the stock ledger.
+	if *product.Quantity != existing.Quantity {
+		change, err := r.importer.inventoryStore.SetStockQuantity(r.ctx, existing.ID, *product.Quantity, r.job.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to set stock quantity: %v", err)
+		}
+
+		r.importer.alerter.CheckStock(r.ctx, *change)
+	}
+
+	if product.Image != nil && *product.Image != existing.Image {
+		r.importer.images.Enqueue(*product.Image)
+	}
+
+	return nil
+}
+
+// checkCategory makes sure the product category exists, looking each
+// category up only once per run.
+func (r *importRun) checkCategory(categoryID int) error {
+	exists, ok := r.categories[categoryID]
+	if !ok {
+		_, err := r.importer.categoryStore.GetProductCategoryByID(r.ctx, categoryID)
+		if err != nil && !errors.Is(err, types.ErrNotFound) {
+			return err
+		}
+		exists = err == nil
+		r.categories[categoryID] = exists
+	}
+
+	if !exists {
+		return fmt.Errorf("product category %d not found", categoryID)
+	}
+
+	return nil
+}
+
+func invalidRow(err error) error {
+	if errors, ok := err.(validator.ValidationErrors); ok {
+		return fmt.Errorf("invalid row: %v", errors)
+	}
+
+	return err
+}
+
+func setString(dst *string, value *string) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+func setInt(dst *int, value *int) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+func orDefault[T any](value *T, fallback T) *T {
+	if value != nil {
+		return value
+	}
+
+	return &fallback
+}
+
+// orDefaultNonEmpty is orDefault for optional strings with format
+// validation: an empty result stays nil so that "omitempty" skips it.
+func orDefaultNonEmpty(value *string, fallback string) *string {
+	if value != nil && *value != "" {
+		return value
+	}
+	if fallback == "" {
+		return nil
+	}
+
+	return &fallback
+}
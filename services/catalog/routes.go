@@ -0,0 +1,257 @@
+package catalog
+
+import (
+	"ecom_go/services/auth"
+	"ecom_go/services/logging"
+	"ecom_go/services/shopmember"
+	"ecom_go/types"
+	"ecom_go/utils"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// flushEvery is the number of exported products written between flushes
+	// of the response.
+	flushEvery = 100
+	// batchTimeout bounds the time to write one batch of an export. The
+	// server write timeout would cut off large exports, so it is extended by
+	// this much after each flushed batch instead.
+	batchTimeout = 30 * time.Second
+)
+
+type Handler struct {
+	store        types.ProductImportStore
+	importer     types.ProductImporter
+	productStore types.ProductStore
+	shopStore    types.ShopStore
+	memberStore  types.ShopMemberStore
+	userStore    types.UserStore
+	maxBytes     int64
+}
+
+func NewHandler(store types.ProductImportStore, importer types.ProductImporter, productStore types.ProductStore, shopStore types.ShopStore, memberStore types.ShopMemberStore, userStore types.UserStore, maxBytes int64) *Handler {
+	return &Handler{
+		store:        store,
+		importer:     importer,
+		productStore: productStore,
+		shopStore:    shopStore,
+		memberStore:  memberStore,
+		userStore:    userStore,
+		maxBytes:     maxBytes,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/{shop_id}/products/import", auth.WithJWTAuth(h.handleImportProducts, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/{shop_id}/products/import/{job_id}", auth.WithJWTAuth(h.handleGetImportJob, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/{shop_id}/products/export", auth.WithJWTAuth(h.handleExportProducts, h.userStore)).Methods(http.MethodGet)
+}
+
+// handleImportProducts queues an import of the CSV or JSON Lines file in the
+// request body. The format is taken from the format query parameter or else
+// from the content type; dry_run=true only validates the rows.
+func (h *Handler) handleImportProducts(w http.ResponseWriter, r *http.Request) {
+	shopID, ok := h.getManagedShopID(w, r)
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = formatFromContentType(r.Header.Get("Content-Type"))
+	}
+	if format != types.ImportFormatCSV && format != types.ImportFormatJSONL {
+		utils.WriteError(w, http.StatusUnsupportedMediaType, fmt.Errorf("import file must be CSV or JSON Lines"))
+		return
+	}
+
+	dryRun := false
+	if str := r.URL.Query().Get("dry_run"); str != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(str); err != nil {
+			utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid dry_run"))
+			return
+		}
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.WriteError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("import file exceeds %d bytes", h.maxBytes))
+			return
+		}
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if len(data) == 0 {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing import file"))
+		return
+	}
+
+	jobID, err := h.store.CreateImportJob(r.Context(), types.ProductImportJob{
+		ShopID: shopID,
+		UserID: auth.GetUserIDFromContext(r.Context()),
+		Format: format,
+		DryRun: dryRun,
+	}, data)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !h.importer.Enqueue(jobID) {
+		message := "import queue is full"
+		if err := h.store.FinishImportJob(r.Context(), types.ProductImportJob{ID: jobID, Status: types.ImportStatusFailed, Error: &message}); err != nil {
+			logging.FromContext(r.Context()).Error("failed to mark import job as failed", "job_id", jobID, "error", err)
+		}
+
+		utils.WriteError(w, http.StatusServiceUnavailable, fmt.Errorf("too many imports are running, try again later"))
+		return
+	}
+
+	job, _ := h.store.GetImportJobByID(r.Context(), jobID)
+	utils.WriteJSON(w, http.StatusAccepted, job)
+}
+
+func (h *Handler) handleGetImportJob(w http.ResponseWriter, r *http.Request) {
+	shopID, ok := h.getManagedShopID(w, r)
+	if !ok {
+		return
+	}
+
+	jobID, err := strconv.Atoi(mux.Vars(r)["job_id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid import job ID"))
+		return
+	}
+
+	job, err := h.store.GetImportJobByID(r.Context(), jobID)
+	if err != nil || job.ShopID != shopID {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("import job not found"))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, job)
+}
+
+// handleExportProducts streams the products of the shop as CSV or JSON Lines,
+// in the same format the import accepts.
+func (h *Handler) handleExportProducts(w http.ResponseWriter, r *http.Request) {
+	shopID, ok := h.getManagedShopID(w, r)
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = types.ImportFormatCSV
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == types.ImportFormatJSONL {
+		contentType = "application/x-ndjson"
+	} else if format != types.ImportFormatCSV {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("format must be %s or %s", types.ImportFormatCSV, types.ImportFormatJSONL))
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="shop-%d-products.%s"`, shopID, format))
+	w.WriteHeader(http.StatusOK)
+
+	writer, err := NewRowWriter(format, w)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("failed to export products", "shop_id", shopID, "error", err)
+		return
+	}
+
+	controller := http.NewResponseController(w)
+	controller.SetWriteDeadline(time.Now().Add(batchTimeout))
+	written := 0
+
+	err = h.productStore.StreamProductsByShopID(r.Context(), shopID, func(product *types.Product) error {
+		if err := writer.Write(product); err != nil {
+			return err
+		}
+
+		written++
+		if written%flushEvery == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			if err := controller.Flush(); err != nil {
+				return err
+			}
+			controller.SetWriteDeadline(time.Now().Add(batchTimeout))
+		}
+
+		return nil
+	})
+	if err == nil {
+		err = writer.Flush()
+	}
+
+	// The status has been sent already, all that is left is to log the
+	// truncated export.
+	if err != nil {
+		logging.FromContext(r.Context()).Error("failed to export products", "shop_id", shopID, "error", err)
+	}
+}
+
+// getManagedShopID reads the shop from the request path and makes sure the
+// authenticated user is an owner or manager of it.
+func (h *Handler) getManagedShopID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	vars := mux.Vars(r)
+	str, ok := vars["shop_id"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing shop ID"))
+		return 0, false
+	}
+
+	shopID, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid shop ID"))
+		return 0, false
+	}
+
+	if _, err := h.shopStore.GetShopByID(r.Context(), shopID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return 0, false
+	}
+
+	isManager, err := shopmember.HasRole(r.Context(), h.memberStore, shopID, auth.GetUserIDFromContext(r.Context()), types.ShopRoleManager)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return 0, false
+	}
+
+	if !isManager {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you do not have permission to manage products of this shop"))
+		return 0, false
+	}
+
+	return shopID, true
+}
+
+func formatFromContentType(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch mediaType {
+	case "text/csv":
+		return types.ImportFormatCSV
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return types.ImportFormatJSONL
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,166 @@
+package catalog
+
+import (
+	"context"
+	"ecom_go/db"
+	"ecom_go/types"
+	"time"
+)
+
+type Store struct {
+	db *db.DB
+}
+
+func NewStore(db *db.DB) *Store {
+	return &Store{db: db}
+}
+
+// The uploaded file is left out, it is only read by the importer.
+const jobColumns = `id, shop_id, user_id, format, dry_run, status, total_rows, created_rows, updated_rows, failed_rows,
+	error, started_at, finished_at, created_at, updated_at`
+
+func (s *Store) GetImportJobByID(ctx context.Context, jobID int) (*types.ProductImportJob, error) {
+	job, err := scanImportJob(s.db.QueryRowContext(ctx, "SELECT "+jobColumns+" FROM product_import_jobs WHERE id = ?", jobID))
+	if err != nil {
+		return nil, types.NotFound("import job", err)
+	}
+
+	errorRows, err := s.db.QueryContext(ctx, "SELECT line, sku, message FROM product_import_errors WHERE job_id = ? ORDER BY line", jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer errorRows.Close()
+
+	job.RowErrors = []types.ImportRowError{}
+	for errorRows.Next() {
+		var rowError types.ImportRowError
+		if err := errorRows.Scan(&rowError.Row, &rowError.SKU, &rowError.Message); err != nil {
+			return nil, err
+		}
+
+		job.RowErrors = append(job.RowErrors, rowError)
+	}
+
+	return job, errorRows.Err()
+}
+
+func (s *Store) GetImportJobData(ctx context.Context, jobID int) ([]byte, error) {
+	var data []byte
+	if err := s.db.QueryRowContext(ctx, "SELECT data FROM product_import_jobs WHERE id = ?", jobID).Scan(&data); err != nil {
+		return nil, types.NotFound("import job", err)
+	}
+
+	return data, nil
+}
+
+// GetUnfinishedImportJobIDs returns the jobs that are still queued or were
+// interrupted while running, oldest first.
+func (s *Store) GetUnfinishedImportJobIDs(ctx context.Context) ([]int, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT id FROM product_import_jobs WHERE status IN (?, ?) ORDER BY id",
+		types.ImportStatusPending, types.ImportStatusRunning)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobIDs := []int{}
+	for rows.Next() {
+		var jobID int
+		if err := rows.Scan(&jobID); err != nil {
+			return nil, err
+		}
+
+		jobIDs = append(jobIDs, jobID)
+	}
+
+	return jobIDs, rows.Err()
+}
+
+func (s *Store) CreateImportJob(ctx context.Context, job types.ProductImportJob, data []byte) (int, error) {
+	id, err := s.db.Insert(ctx, "INSERT INTO product_import_jobs (shop_id, user_id, format, dry_run, status, data) VALUES (?, ?, ?, ?, ?, ?)",
+		job.ShopID, job.UserID, job.Format, job.DryRun, types.ImportStatusPending, data)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+func (s *Store) StartImportJob(ctx context.Context, jobID int) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE product_import_jobs SET status = ?, started_at = ? WHERE id = ?",
+		types.ImportStatusRunning, time.Now().UTC(), jobID)
+
+	return err
+}
+
+// FinishImportJob stores the outcome of the job and its row errors, and drops
+// the uploaded file. Row errors of an earlier, interrupted run are replaced.
+func (s *Store) FinishImportJob(ctx context.Context, job types.ProductImportJob) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if job.Error != nil {
+		message := truncate(*job.Error, 255)
+		job.Error = &message
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE product_import_jobs SET status = ?, total_rows = ?, created_rows = ?, updated_rows = ?, failed_rows = ?,
+			error = ?, data = NULL, finished_at = ?
+		WHERE id = ?`,
+		job.Status, job.TotalRows, job.CreatedRows, job.UpdatedRows, job.FailedRows,
+		job.Error, time.Now().UTC(), job.ID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM product_import_errors WHERE job_id = ?", job.ID); err != nil {
+		return err
+	}
+
+	for _, rowError := range job.RowErrors {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO product_import_errors (job_id, line, sku, message) VALUES (?, ?, ?, ?)",
+			job.ID, rowError.Row, rowError.SKU, truncate(rowError.Message, 512)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	return s[:n]
+}
+
+func scanImportJob(row db.Scanner) (*types.ProductImportJob, error) {
+	job := new(types.ProductImportJob)
+
+	err := row.Scan(
+		&job.ID,
+		&job.ShopID,
+		&job.UserID,
+		&job.Format,
+		&job.DryRun,
+		&job.Status,
+		&job.TotalRows,
+		&job.CreatedRows,
+		&job.UpdatedRows,
+		&job.FailedRows,
+		&job.Error,
+		&job.StartedAt,
+		&job.FinishedAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
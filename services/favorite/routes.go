@@ -0,0 +1,147 @@
+package favorite
+
+import (
+	"ecom_go/services/auth"
+	"ecom_go/services/shopmember"
+	"ecom_go/types"
+	"ecom_go/utils"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	store       types.FavoriteStore
+	shopStore   types.ShopStore
+	memberStore types.ShopMemberStore
+	userStore   types.UserStore
+}
+
+func NewHandler(store types.FavoriteStore, shopStore types.ShopStore, memberStore types.ShopMemberStore, userStore types.UserStore) *Handler {
+	return &Handler{
+		store:       store,
+		shopStore:   shopStore,
+		memberStore: memberStore,
+		userStore:   userStore,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/me/favorites/shops", auth.WithJWTAuth(h.handleGetFavoriteShops, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/me/favorites/shops", auth.WithJWTAuth(h.handleAddFavoriteShop, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/me/favorites/shops/{shop_id}", auth.WithJWTAuth(h.handleRemoveFavoriteShop, h.userStore)).Methods(http.MethodDelete)
+}
+
+func (h *Handler) handleGetFavoriteShops(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	shopIDs, err := h.store.GetFavoriteShopIDs(r.Context(), userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Followed shops that were deleted or are no longer visible are left out.
+	isFollowed := true
+	shops := []types.Shop{}
+	for _, shopID := range shopIDs {
+		shop, err := h.shopStore.GetShopByID(r.Context(), shopID)
+		if err != nil {
+			continue
+		}
+
+		visible, err := shopmember.CanViewShop(r.Context(), h.memberStore, h.userStore, shop, userID)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if !visible {
+			continue
+		}
+
+		shop.IsFollowed = &isFollowed
+		shops = append(shops, *shop)
+	}
+
+	utils.WriteJSON(w, http.StatusOK, shops)
+}
+
+func (h *Handler) handleAddFavoriteShop(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var favorite types.AddFavoriteShopPayload
+	if err := utils.ParseJSON(r, &favorite); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(favorite); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	shop, err := h.shopStore.GetShopByID(r.Context(), favorite.ShopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("shop not found"))
+		return
+	}
+
+	visible, err := shopmember.CanViewShop(r.Context(), h.memberStore, h.userStore, shop, userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !visible {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("shop not found"))
+		return
+	}
+
+	added, err := h.store.AddFavoriteShop(r.Context(), userID, shop.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	status := http.StatusOK
+	if added {
+		status = http.StatusCreated
+	}
+
+	isFollowed := true
+	shop.IsFollowed = &isFollowed
+	utils.WriteJSON(w, status, shop)
+}
+
+func (h *Handler) handleRemoveFavoriteShop(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	str, ok := vars["shop_id"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing shop ID"))
+		return
+	}
+
+	shopID, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid shop ID"))
+		return
+	}
+
+	rowsAffected, err := h.store.RemoveFavoriteShop(r.Context(), auth.GetUserIDFromContext(r.Context()), shopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to unfollow shop: %w", err))
+		return
+	}
+
+	if rowsAffected == 0 {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("shop is not in favorites"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
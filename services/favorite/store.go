@@ -0,0 +1,101 @@
+package favorite
+
+import (
+	"context"
+	"ecom_go/db"
+	"strings"
+)
+
+type Store struct {
+	db *db.DB
+}
+
+func NewStore(db *db.DB) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) GetFavoriteShopIDs(ctx context.Context, userID int) ([]int, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT shop_id FROM favorite_shops WHERE user_id = ? ORDER BY id DESC", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shopIDs := []int{}
+	for rows.Next() {
+		var shopID int
+		if err := rows.Scan(&shopID); err != nil {
+			return nil, err
+		}
+
+		shopIDs = append(shopIDs, shopID)
+	}
+
+	return shopIDs, rows.Err()
+}
+
+// AddFavoriteShop makes the user follow the shop and reports whether they
+// were not following it yet.
+func (s *Store) AddFavoriteShop(ctx context.Context, userID int, shopID int) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM favorite_shops WHERE user_id = ? AND shop_id = ?",
+		userID, shopID).Scan(&count); err != nil {
+		return false, err
+	}
+
+	if count > 0 {
+		return false, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO favorite_shops (user_id, shop_id) VALUES (?, ?)", userID, shopID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (s *Store) RemoveFavoriteShop(ctx context.Context, userID int, shopID int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM favorite_shops WHERE user_id = ? AND shop_id = ?", userID, shopID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// GetFavoriteShopSet reports which of shopIDs the user follows.
+func (s *Store) GetFavoriteShopSet(ctx context.Context, userID int, shopIDs []int) (map[int]bool, error) {
+	set := map[int]bool{}
+	if len(shopIDs) == 0 {
+		return set, nil
+	}
+
+	args := []any{userID}
+	for _, id := range shopIDs {
+		args = append(args, id)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(shopIDs)), ", ")
+	rows, err := s.db.QueryContext(ctx, "SELECT shop_id FROM favorite_shops WHERE user_id = ? AND shop_id IN ("+placeholders+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shopID int
+		if err := rows.Scan(&shopID); err != nil {
+			return nil, err
+		}
+
+		set[shopID] = true
+	}
+
+	return set, rows.Err()
+}
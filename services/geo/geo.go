@@ -0,0 +1,72 @@
+package geo
+
+import (
+	"ecom_go/types"
+	"math"
+)
+
+const earthRadiusKm = 6371.0088
+
+// DistanceKm returns the great-circle distance between two points.
+func DistanceKm(a, b types.GeoPoint) float64 {
+	lat1 := radians(a.Latitude)
+	lat2 := radians(b.Latitude)
+	dLat := lat2 - lat1
+	dLng := radians(b.Longitude - a.Longitude)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Box is the area between a south-west and a north-east corner. The
+// longitude of SW is never greater than that of NE.
+type Box struct {
+	SW types.GeoPoint
+	NE types.GeoPoint
+}
+
+// BoundingBoxes returns boxes that together contain every point within
+// radiusKm of center. They are used to narrow down candidates before
+// computing exact distances. An area crossing the antimeridian is split into
+// one box on either side of it, and one reaching a pole spans every
+// longitude.
+func BoundingBoxes(center types.GeoPoint, radiusKm float64) []Box {
+	dLat := degrees(radiusKm / earthRadiusKm)
+	south := math.Max(-90, center.Latitude-dLat)
+	north := math.Min(90, center.Latitude+dLat)
+
+	dLng := 180.0
+	if cos := math.Cos(radians(center.Latitude)); cos > 1e-9 && south > -90 && north < 90 {
+		dLng = math.Min(180, dLat/cos)
+	}
+
+	west := center.Longitude - dLng
+	east := center.Longitude + dLng
+
+	box := func(west, east float64) Box {
+		return Box{
+			SW: types.GeoPoint{Latitude: south, Longitude: west},
+			NE: types.GeoPoint{Latitude: north, Longitude: east},
+		}
+	}
+
+	switch {
+	case dLng >= 180:
+		return []Box{box(-180, 180)}
+	case west < -180:
+		return []Box{box(west+360, 180), box(-180, east)}
+	case east > 180:
+		return []Box{box(west, 180), box(-180, east-360)}
+	default:
+		return []Box{box(west, east)}
+	}
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
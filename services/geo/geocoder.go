@@ -0,0 +1,141 @@
+package geo
+
+import (
+	"context"
+	"ecom_go/types"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewGeocoder returns the geocoder selected by name. The offline geocoder is
+// used for anything other than "nominatim".
+func NewGeocoder(name, baseURL, userAgent string) types.Geocoder {
+	if name == "nominatim" {
+		return NewNominatimGeocoder(baseURL, userAgent)
+	}
+
+	return NewOfflineGeocoder(nil)
+}
+
+// NominatimGeocoder resolves addresses through an OpenStreetMap Nominatim
+// compatible search API.
+type NominatimGeocoder struct {
+	client    *http.Client
+	baseURL   string
+	userAgent string
+}
+
+func NewNominatimGeocoder(baseURL, userAgent string) *NominatimGeocoder {
+	return &NominatimGeocoder{
+		client:    &http.Client{Timeout: 5 * time.Second},
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		userAgent: userAgent,
+	}
+}
+
+func (g *NominatimGeocoder) Geocode(ctx context.Context, address types.Address) (*types.GeoPoint, error) {
+	query := url.Values{}
+	query.Set("format", "jsonv2")
+	query.Set("limit", "1")
+	if address.Street != "" {
+		query.Set("street", address.Street)
+	}
+	if address.City != "" {
+		query.Set("city", address.City)
+	}
+	if address.PostalCode != "" {
+		query.Set("postalcode", address.PostalCode)
+	}
+	if address.Country != "" {
+		query.Set("countrycodes", strings.ToLower(address.Country))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+"/search?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", g.userAgent)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("geocoder returned status %d", resp.StatusCode)
+	}
+
+	var results []struct {
+		Lat string `json:"lat"`
+		Lon string `json:"lon"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("address not found")
+	}
+
+	lat, err := strconv.ParseFloat(results[0].Lat, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	lng, err := strconv.ParseFloat(results[0].Lon, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.GeoPoint{Latitude: lat, Longitude: lng}, nil
+}
+
+// OfflineGeocoder resolves addresses from a fixed table without any network
+// access. It stands in for a real geocoder in development and tests.
+type OfflineGeocoder struct {
+	points map[string]types.GeoPoint
+}
+
+// NewOfflineGeocoder builds a geocoder from points keyed by AddressKey.
+func NewOfflineGeocoder(points map[string]types.GeoPoint) *OfflineGeocoder {
+	if points == nil {
+		points = map[string]types.GeoPoint{}
+	}
+
+	return &OfflineGeocoder{points: points}
+}
+
+// Add registers the coordinates returned for address.
+func (g *OfflineGeocoder) Add(address types.Address, point types.GeoPoint) {
+	g.points[AddressKey(address)] = point
+}
+
+func (g *OfflineGeocoder) Geocode(ctx context.Context, address types.Address) (*types.GeoPoint, error) {
+	if point, ok := g.points[AddressKey(address)]; ok {
+		return &point, nil
+	}
+
+	// Fall back to a coarser lookup by city when the exact address is unknown.
+	if point, ok := g.points[AddressKey(types.Address{City: address.City, Country: address.Country})]; ok {
+		return &point, nil
+	}
+
+	return nil, fmt.Errorf("address not found")
+}
+
+// AddressKey normalizes an address into the lookup key used by
+// OfflineGeocoder.
+func AddressKey(address types.Address) string {
+	parts := []string{address.Street, address.City, address.PostalCode, address.Country}
+	for i, part := range parts {
+		parts[i] = strings.ToLower(strings.Join(strings.Fields(part), " "))
+	}
+
+	return strings.Join(parts, "|")
+}
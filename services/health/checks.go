@@ -0,0 +1,96 @@
+package health
+
+import (
+	"context"
+	"ecom_go/types"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type databaseCheck struct {
+	store types.HealthStore
+}
+
+// NewDatabaseCheck checks that the database answers.
+func NewDatabaseCheck(store types.HealthStore) types.HealthCheck {
+	return &databaseCheck{store: store}
+}
+
+func (c *databaseCheck) Name() string {
+	return "database"
+}
+
+func (c *databaseCheck) Check(ctx context.Context) error {
+	return c.store.Ping(ctx)
+}
+
+type migrationCheck struct {
+	store    types.HealthStore
+	expected uint
+	err      error
+}
+
+// NewMigrationCheck checks that the database schema is at the version of the
+// newest migration in dir, the one this build of the API was written against.
+func NewMigrationCheck(store types.HealthStore, dir string) types.HealthCheck {
+	expected, err := LatestMigration(dir)
+
+	return &migrationCheck{store: store, expected: expected, err: err}
+}
+
+func (c *migrationCheck) Name() string {
+	return "migrations"
+}
+
+func (c *migrationCheck) Check(ctx context.Context) error {
+	if c.err != nil {
+		return fmt.Errorf("failed to read the expected migration version: %w", c.err)
+	}
+
+	version, dirty, err := c.store.GetMigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return fmt.Errorf("migration %d failed and left the schema dirty", version)
+	}
+
+	if version != c.expected {
+		return fmt.Errorf("schema is at version %d, expected %d", version, c.expected)
+	}
+
+	return nil
+}
+
+// LatestMigration returns the version of the newest migration in dir, taken
+// from the "<version>_<name>.up.sql" file names.
+func LatestMigration(dir string) (uint, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".up.sql") {
+			continue
+		}
+
+		prefix, _, _ := strings.Cut(entry.Name(), "_")
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+
+		latest = max(latest, uint(version))
+	}
+
+	if latest == 0 {
+		return 0, fmt.Errorf("no migrations in %s", dir)
+	}
+
+	return latest, nil
+}
@@ -0,0 +1,94 @@
+package health
+
+import (
+	"context"
+	"ecom_go/types"
+	"ecom_go/utils"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	checks  []types.HealthCheck
+	timeout time.Duration
+}
+
+// NewHandler returns a handler whose readiness checks each get timeout to
+// complete.
+func NewHandler(timeout time.Duration) *Handler {
+	return &Handler{timeout: timeout}
+}
+
+// Register adds checks the API must pass to be ready.
+func (h *Handler) Register(checks ...types.HealthCheck) {
+	h.checks = append(h.checks, checks...)
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/healthz", h.handleLiveness).Methods(http.MethodGet)
+	router.HandleFunc("/readyz", h.handleReadiness).Methods(http.MethodGet)
+}
+
+// handleLiveness reports that the process is up and serving requests, without
+// looking at any dependency.
+func (h *Handler) handleLiveness(w http.ResponseWriter, r *http.Request) {
+	utils.WriteJSON(w, http.StatusOK, map[string]string{"status": types.HealthStatusOK})
+}
+
+// handleReadiness runs every check concurrently and reports each outcome. The
+// status is 503 when any check failed.
+func (h *Handler) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	report := types.HealthReport{
+		Status: types.HealthStatusOK,
+		Checks: make(map[string]types.HealthCheckResult, len(h.checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result := h.run(r.Context(), check)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[check.Name()] = result
+			if result.Status != types.HealthStatusOK {
+				report.Status = types.HealthStatusUnavailable
+			}
+		}()
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if report.Status != types.HealthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	utils.WriteJSON(w, status, report)
+}
+
+func (h *Handler) run(ctx context.Context, check types.HealthCheck) types.HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Check(ctx)
+
+	result := types.HealthCheckResult{
+		Status:     types.HealthStatusOK,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = types.HealthStatusUnavailable
+		result.Error = err.Error()
+	}
+
+	return result
+}
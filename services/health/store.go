@@ -0,0 +1,37 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"ecom_go/db"
+)
+
+type Store struct {
+	db *db.DB
+}
+
+func NewStore(db *db.DB) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// GetMigrationVersion returns the version of the last migration applied to the
+// primary and whether it failed halfway, leaving the schema dirty. A database
+// no migration ran on is at version 0.
+func (s *Store) GetMigrationVersion(ctx context.Context) (uint, bool, error) {
+	var version uint
+	var dirty bool
+
+	err := s.db.Primary().QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return version, dirty, nil
+}
@@ -0,0 +1,281 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"ecom_go/types"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	_ "image/gif"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const variantsDir = "variants"
+
+type variantSize struct {
+	name     string
+	maxWidth int
+}
+
+var sizes = []variantSize{
+	{name: "thumbnail", maxWidth: 150},
+	{name: "medium", maxWidth: 600},
+	{name: "large", maxWidth: 1200},
+}
+
+var formats = []string{"webp", "jpeg", "png"}
+
+// Processor generates resized variants of uploaded images in the background.
+// Images are decoded and re-encoded from raw pixels, which also drops any
+// EXIF or other metadata embedded in the original file.
+type Processor struct {
+	store     types.ImageStore
+	staticDir string
+	jobs      chan string
+	stop      chan struct{}
+	wg        sync.WaitGroup
+	running   atomic.Bool
+}
+
+func NewProcessor(store types.ImageStore, staticDir string, queueSize int) *Processor {
+	return &Processor{
+		store:     store,
+		staticDir: staticDir,
+		jobs:      make(chan string, queueSize),
+		stop:      make(chan struct{}),
+	}
+}
+
+func (p *Processor) Start() {
+	p.running.Store(true)
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer p.running.Store(false)
+		for {
+			select {
+			case source := <-p.jobs:
+				p.generate(source)
+			case <-p.stop:
+				p.drain()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops accepting new images and waits for queued ones to be processed.
+// The jobs channel is never closed, so late calls to Enqueue from requests
+// still running are safe.
+func (p *Processor) Stop() {
+	close(p.stop)
+	p.wg.Wait()
+}
+
+// drain processes the images queued before Stop.
+func (p *Processor) drain() {
+	for {
+		select {
+		case source := <-p.jobs:
+			p.generate(source)
+		default:
+			return
+		}
+	}
+}
+
+func (p *Processor) generate(source string) {
+	if err := p.process(source); err != nil {
+		slog.Error("failed to generate image variants", "source", source, "error", err)
+	}
+}
+
+func (p *Processor) Name() string {
+	return "image_processor"
+}
+
+// Check reports whether the image processor is running.
+func (p *Processor) Check(ctx context.Context) error {
+	if !p.running.Load() {
+		return fmt.Errorf("image processor is not running")
+	}
+
+	return nil
+}
+
+// Enqueue schedules variant generation for source without blocking the
+// caller. When the queue is full or the processor is stopped the image is
+// skipped.
+func (p *Processor) Enqueue(source string) {
+	if source == "" {
+		return
+	}
+
+	select {
+	case <-p.stop:
+		slog.Warn("image processor is stopped, skipping image", "source", source)
+		return
+	default:
+	}
+
+	select {
+	case p.jobs <- source:
+	default:
+		slog.Warn("image queue is full, skipping image", "source", source)
+	}
+}
+
+func (p *Processor) process(source string) error {
+	u, err := url.Parse(source)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(filepath.Join(p.staticDir, filepath.FromSlash(path.Clean("/"+u.Path))))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	src, _, err := image.Decode(file)
+	if err != nil {
+		return err
+	}
+
+	sum := sha1.Sum([]byte(source))
+	name := hex.EncodeToString(sum[:8])
+
+	outDir := filepath.Join(p.staticDir, variantsDir)
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return err
+	}
+
+	variants := []types.ImageVariant{}
+	for _, size := range sizes {
+		resized := resize(src, size.maxWidth)
+		bounds := resized.Bounds()
+
+		for _, format := range formats {
+			filename := fmt.Sprintf("%s_%s.%s", name, size.name, extension(format))
+			if err := writeVariant(filepath.Join(outDir, filename), resized, format); err != nil {
+				return err
+			}
+
+			variantURL := url.URL{Scheme: u.Scheme, Host: u.Host, Path: path.Join("/", variantsDir, filename)}
+			variants = append(variants, types.ImageVariant{
+				Source: source,
+				Size:   size.name,
+				Format: format,
+				Width:  bounds.Dx(),
+				Height: bounds.Dy(),
+				URL:    variantURL.String(),
+			})
+		}
+	}
+
+	return p.store.ReplaceImageVariants(context.Background(), source, variants)
+}
+
+// resize scales src down to maxWidth keeping its aspect ratio. Images that are
+// already small enough are never upscaled.
+func resize(src image.Image, maxWidth int) *image.NRGBA {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxWidth {
+		height = max(1, height*maxWidth/width)
+		width = maxWidth
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+
+	return dst
+}
+
+func writeVariant(filename string, img *image.NRGBA, format string) error {
+	var buf bytes.Buffer
+
+	var err error
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, flatten(img), &jpeg.Options{Quality: 85})
+	case "png":
+		err = png.Encode(&buf, img)
+	case "webp":
+		err = nativewebp.Encode(&buf, img, nil)
+	default:
+		err = fmt.Errorf("unsupported image format: %s", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, filename)
+}
+
+// flatten draws img onto a white background since JPEG has no alpha channel.
+func flatten(img image.Image) image.Image {
+	dst := image.NewRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	return dst
+}
+
+func extension(format string) string {
+	if format == "jpeg" {
+		return "jpg"
+	}
+
+	return format
+}
+
+// NewImageSet groups stored variants by size for API responses.
+func NewImageSet(variants []types.ImageVariant) *types.ImageSet {
+	if len(variants) == 0 {
+		return nil
+	}
+
+	set := new(types.ImageSet)
+	for _, v := range variants {
+		var target **types.ImageSize
+		switch v.Size {
+		case "thumbnail":
+			target = &set.Thumbnail
+		case "medium":
+			target = &set.Medium
+		case "large":
+			target = &set.Large
+		default:
+			continue
+		}
+
+		if *target == nil {
+			*target = &types.ImageSize{Width: v.Width, Height: v.Height, Formats: map[string]string{}}
+		}
+		(*target).Formats[v.Format] = v.URL
+	}
+
+	return set
+}
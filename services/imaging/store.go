@@ -0,0 +1,81 @@
+package imaging
+
+import (
+	"context"
+	"ecom_go/db"
+	"ecom_go/types"
+)
+
+type Store struct {
+	db *db.DB
+}
+
+func NewStore(db *db.DB) *Store {
+	return &Store{db: db}
+}
+
+const imageVariantColumns = "id, source, size, format, width, height, url, created_at, updated_at"
+
+func (s *Store) GetImageVariants(ctx context.Context, source string) ([]types.ImageVariant, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+imageVariantColumns+" FROM image_variants WHERE source = ?", source)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := []types.ImageVariant{}
+	for rows.Next() {
+		variant, err := scanImageVariant(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, *variant)
+	}
+
+	return variants, rows.Err()
+}
+
+func (s *Store) ReplaceImageVariants(ctx context.Context, source string, variants []types.ImageVariant) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM image_variants WHERE source = ?", source); err != nil {
+		return err
+	}
+
+	for _, v := range variants {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO image_variants (source, size, format, width, height, url) VALUES (?, ?, ?, ?, ?, ?)",
+			source, v.Size, v.Format, v.Width, v.Height, v.URL)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func scanImageVariant(row db.Scanner) (*types.ImageVariant, error) {
+	variant := new(types.ImageVariant)
+
+	err := row.Scan(
+		&variant.ID,
+		&variant.Source,
+		&variant.Size,
+		&variant.Format,
+		&variant.Width,
+		&variant.Height,
+		&variant.URL,
+		&variant.CreatedAt,
+		&variant.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return variant, nil
+}
@@ -0,0 +1,74 @@
+package inventory
+
+import (
+	"context"
+	"ecom_go/services/logging"
+	"ecom_go/types"
+	"fmt"
+)
+
+// Alerter emails the owner of a shop when the available stock of one of its
+// products drops to or below the low stock threshold of the product. Only the
+// change that crosses the threshold sends an alert, not every change below it.
+type Alerter struct {
+	productStore types.ProductStore
+	shopStore    types.ShopStore
+	userStore    types.UserStore
+	notifier     types.Notifier
+}
+
+func NewAlerter(productStore types.ProductStore, shopStore types.ShopStore, userStore types.UserStore, notifier types.Notifier) *Alerter {
+	return &Alerter{
+		productStore: productStore,
+		shopStore:    shopStore,
+		userStore:    userStore,
+		notifier:     notifier,
+	}
+}
+
+func (a *Alerter) CheckStock(ctx context.Context, changes ...types.StockChange) {
+	for _, change := range changes {
+		if CrossesThreshold(change) {
+			a.alert(ctx, change)
+		}
+	}
+}
+
+// CrossesThreshold reports whether the change brings the available stock from
+// above the low stock threshold to or below it.
+func CrossesThreshold(change types.StockChange) bool {
+	if change.LowStockThreshold == nil {
+		return false
+	}
+
+	threshold := *change.LowStockThreshold
+
+	return change.AvailableBefore > threshold && change.AvailableAfter <= threshold
+}
+
+func (a *Alerter) alert(ctx context.Context, change types.StockChange) {
+	product, err := a.productStore.GetProductByID(ctx, change.ProductID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to load product for low stock alert", "product_id", change.ProductID, "error", err)
+		return
+	}
+
+	shop, err := a.shopStore.GetShopByID(ctx, product.ShopID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to load shop for low stock alert", "shop_id", product.ShopID, "error", err)
+		return
+	}
+
+	owner, err := a.userStore.GetUserByID(ctx, shop.UserID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to load shop owner", "shop_id", shop.ID, "error", err)
+		return
+	}
+
+	body := fmt.Sprintf("Only %d of %s are left available in your shop %s (low stock threshold: %d).",
+		max(change.AvailableAfter, 0), product.Title, shop.Name, *change.LowStockThreshold)
+
+	if err := a.notifier.Notify(owner.Email, fmt.Sprintf("Low stock: %s", product.Title), body); err != nil {
+		logging.FromContext(ctx).Error("failed to send low stock alert", "product_id", product.ID, "error", err)
+	}
+}
@@ -0,0 +1,67 @@
+package inventory
+
+import (
+	"ecom_go/services/geo"
+	"ecom_go/types"
+	"math"
+	"sort"
+)
+
+// Candidate is a location that can supply stock for a reservation.
+type Candidate struct {
+	LocationID int
+	Available  int
+	Point      *types.GeoPoint
+}
+
+type Allocation struct {
+	LocationID int
+	Quantity   int
+}
+
+// Allocate splits the quantity over the candidate locations, filling each
+// location before moving on to the next. With the nearest strategy locations
+// are taken in order of their distance to the buyer, locations without
+// coordinates last; without a buyer location it falls back to the highest
+// stock strategy, which takes the locations with the most available stock
+// first. It reports false when the locations do not hold enough stock.
+func Allocate(strategy string, candidates []Candidate, quantity int, buyer *types.GeoPoint) ([]Allocation, bool) {
+	ordered := make([]Candidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.Available > 0 {
+			ordered = append(ordered, candidate)
+		}
+	}
+
+	distance := func(c Candidate) float64 {
+		if strategy != types.AllocationNearest || buyer == nil || c.Point == nil {
+			return math.Inf(1)
+		}
+		return geo.DistanceKm(*buyer, *c.Point)
+	}
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		di, dj := distance(ordered[i]), distance(ordered[j])
+		if di != dj {
+			return di < dj
+		}
+		if ordered[i].Available != ordered[j].Available {
+			return ordered[i].Available > ordered[j].Available
+		}
+		return ordered[i].LocationID < ordered[j].LocationID
+	})
+
+	allocations := []Allocation{}
+	remaining := quantity
+	for _, candidate := range ordered {
+		if remaining == 0 {
+			break
+		}
+
+		take := min(candidate.Available, remaining)
+		allocations = append(allocations, Allocation{LocationID: candidate.LocationID, Quantity: take})
+		remaining -= take
+	}
+
+	return allocations, remaining == 0
+}
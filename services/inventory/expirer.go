@@ -0,0 +1,83 @@
+package inventory
+
+import (
+	"context"
+	"ecom_go/types"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Expirer periodically marks stock reservations past their expiry as
+// expired. Expired reservations stop holding stock as soon as they expire;
+// the expirer only brings their status up to date.
+type Expirer struct {
+	store    types.InventoryStore
+	interval time.Duration
+	stop     chan struct{}
+	wg       sync.WaitGroup
+	running  atomic.Bool
+}
+
+func NewExpirer(store types.InventoryStore, interval time.Duration) *Expirer {
+	return &Expirer{
+		store:    store,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+func (e *Expirer) Start() {
+	e.running.Store(true)
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer e.running.Store(false)
+
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+
+		for {
+			e.expire()
+
+			select {
+			case <-ticker.C:
+			case <-e.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop waits for a running expiry to finish.
+func (e *Expirer) Stop() {
+	close(e.stop)
+	e.wg.Wait()
+}
+
+func (e *Expirer) Name() string {
+	return "reservation_expirer"
+}
+
+// Check reports whether the reservation expirer is running.
+func (e *Expirer) Check(ctx context.Context) error {
+	if !e.running.Load() {
+		return fmt.Errorf("reservation expirer is not running")
+	}
+
+	return nil
+}
+
+func (e *Expirer) expire() {
+	n, err := e.store.ExpireStockReservations(context.Background(), time.Now().UTC())
+	if err != nil {
+		slog.Error("failed to expire stock reservations", "error", err)
+		return
+	}
+
+	if n > 0 {
+		slog.Info("expired stock reservations", "count", n)
+	}
+}
@@ -0,0 +1,613 @@
+package inventory
+
+import (
+	"context"
+	"ecom_go/services/address"
+	"ecom_go/services/auth"
+	"ecom_go/services/shopmember"
+	"ecom_go/types"
+	"ecom_go/utils"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+type Handler struct {
+	store          types.InventoryStore
+	alerter        types.StockAlerter
+	productStore   types.ProductStore
+	shopStore      types.ShopStore
+	memberStore    types.ShopMemberStore
+	userStore      types.UserStore
+	reservationTTL time.Duration
+}
+
+func NewHandler(store types.InventoryStore, alerter types.StockAlerter, productStore types.ProductStore, shopStore types.ShopStore, memberStore types.ShopMemberStore, userStore types.UserStore, reservationTTL time.Duration) *Handler {
+	return &Handler{
+		store:          store,
+		alerter:        alerter,
+		productStore:   productStore,
+		shopStore:      shopStore,
+		memberStore:    memberStore,
+		userStore:      userStore,
+		reservationTTL: reservationTTL,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/products/{product_id}", auth.WithJWTAuth(h.handleGetStockLevel, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/products/{product_id}/movements", auth.WithJWTAuth(h.handleGetMovements, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/products/{product_id}/movements", auth.WithJWTAuth(h.handleRecordMovement, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/products/{product_id}/transfers", auth.WithJWTAuth(h.handleTransferStock, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/products/{product_id}/threshold", auth.WithJWTAuth(h.handleSetThreshold, h.userStore)).Methods(http.MethodPut)
+	router.HandleFunc("/locations", auth.WithJWTAuth(h.handleGetLocations, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/locations", auth.WithJWTAuth(h.handleCreateLocation, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/locations/{location_id}", auth.WithJWTAuth(h.handleGetLocation, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/locations/{location_id}", auth.WithJWTAuth(h.handleUpdateLocation, h.userStore)).Methods(http.MethodPut)
+	router.HandleFunc("/locations/{location_id}", auth.WithJWTAuth(h.handleDeleteLocation, h.userStore)).Methods(http.MethodDelete)
+	router.HandleFunc("/reservations", auth.WithJWTAuth(h.handleCreateReservation, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/reservations/{reservation_id}", auth.WithJWTAuth(h.handleGetReservation, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/reservations/{reservation_id}", auth.WithJWTAuth(h.handleReleaseReservation, h.userStore)).Methods(http.MethodDelete)
+	router.HandleFunc("/reservations/{reservation_id}/commit", auth.WithJWTAuth(h.handleCommitReservation, h.userStore)).Methods(http.MethodPost)
+}
+
+func (h *Handler) handleGetStockLevel(w http.ResponseWriter, r *http.Request) {
+	product, ok := h.getShopProduct(w, r, types.ShopRoleStaff)
+	if !ok {
+		return
+	}
+
+	level, err := h.store.GetStockLevel(r.Context(), product.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, level)
+}
+
+// handleGetMovements lists the stock ledger of a product, newest first.
+func (h *Handler) handleGetMovements(w http.ResponseWriter, r *http.Request) {
+	product, ok := h.getShopProduct(w, r, types.ShopRoleStaff)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, offset, err := pagination(query.Get("limit"), query.Get("offset"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	movements, err := h.store.GetStockMovements(r.Context(), product.ID, limit, offset)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, movements)
+}
+
+func (h *Handler) handleRecordMovement(w http.ResponseWriter, r *http.Request) {
+	product, ok := h.getShopProduct(w, r, types.ShopRoleStaff)
+	if !ok {
+		return
+	}
+
+	var movement types.RecordStockMovementPayload
+	if err := utils.ParseJSON(r, &movement); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(movement); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	if err := ValidateMovement(movement); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", err))
+		return
+	}
+
+	if movement.LocationID != nil && !h.isShopLocation(r.Context(), *movement.LocationID, product.ShopID) {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("stock location not found"))
+		return
+	}
+
+	movement.ProductID = product.ID
+	movement.ActorID = auth.GetUserIDFromContext(r.Context())
+
+	change, err := h.store.RecordStockMovement(r.Context(), movement)
+	if err != nil {
+		writeStockError(w, err)
+		return
+	}
+
+	h.alerter.CheckStock(r.Context(), *change)
+
+	level, _ := h.store.GetStockLevel(r.Context(), product.ID)
+	utils.WriteJSON(w, http.StatusCreated, level)
+}
+
+// handleTransferStock moves stock of a product between two locations of its
+// shop.
+func (h *Handler) handleTransferStock(w http.ResponseWriter, r *http.Request) {
+	product, ok := h.getShopProduct(w, r, types.ShopRoleStaff)
+	if !ok {
+		return
+	}
+
+	var transfer types.TransferStockPayload
+	if err := utils.ParseJSON(r, &transfer); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(transfer); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	if !h.isShopLocation(r.Context(), transfer.FromLocationID, product.ShopID) || !h.isShopLocation(r.Context(), transfer.ToLocationID, product.ShopID) {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("stock location not found"))
+		return
+	}
+
+	transfer.ProductID = product.ID
+	transfer.ActorID = auth.GetUserIDFromContext(r.Context())
+
+	if err := h.store.TransferStock(r.Context(), transfer); err != nil {
+		writeStockError(w, err)
+		return
+	}
+
+	level, _ := h.store.GetStockLevel(r.Context(), product.ID)
+	utils.WriteJSON(w, http.StatusCreated, level)
+}
+
+func (h *Handler) handleSetThreshold(w http.ResponseWriter, r *http.Request) {
+	product, ok := h.getShopProduct(w, r, types.ShopRoleManager)
+	if !ok {
+		return
+	}
+
+	var payload types.LowStockThresholdPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	if err := h.store.SetLowStockThreshold(r.Context(), product.ID, payload.LowStockThreshold); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	level, _ := h.store.GetStockLevel(r.Context(), product.ID)
+	utils.WriteJSON(w, http.StatusOK, level)
+}
+
+// handleCreateReservation holds stock for a pending checkout. The reservation
+// expires after the configured time unless it is committed or released.
+func (h *Handler) handleCreateReservation(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var payload types.CreateStockReservationPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	for _, item := range payload.Items {
+		product, err := h.productStore.GetProductByID(r.Context(), item.ProductID)
+		if err != nil {
+			utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product %d not found", item.ProductID))
+			return
+		}
+
+		shop, err := h.shopStore.GetShopByID(r.Context(), product.ShopID)
+		if err != nil {
+			utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product %d not found", item.ProductID))
+			return
+		}
+
+		visible, err := shopmember.CanViewShop(r.Context(), h.memberStore, h.userStore, shop, userID)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if !visible {
+			utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product %d not found", item.ProductID))
+			return
+		}
+	}
+
+	var buyer *types.GeoPoint
+	if payload.Latitude != nil && payload.Longitude != nil {
+		buyer = &types.GeoPoint{Latitude: *payload.Latitude, Longitude: *payload.Longitude}
+	}
+
+	reservationID, changes, err := h.store.CreateStockReservation(r.Context(), userID, payload.Items, buyer, time.Now().Add(h.reservationTTL).UTC())
+	if err != nil {
+		writeStockError(w, err)
+		return
+	}
+
+	h.alerter.CheckStock(r.Context(), changes...)
+
+	reservation, _ := h.store.GetStockReservationByID(r.Context(), reservationID)
+	utils.WriteJSON(w, http.StatusCreated, reservation)
+}
+
+func (h *Handler) handleGetReservation(w http.ResponseWriter, r *http.Request) {
+	reservation, ok := h.getOwnReservation(w, r)
+	if !ok {
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, reservation)
+}
+
+// handleCommitReservation turns the reservation into sales once the checkout
+// it was made for goes through.
+func (h *Handler) handleCommitReservation(w http.ResponseWriter, r *http.Request) {
+	reservation, ok := h.getOwnReservation(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.CommitStockReservation(r.Context(), reservation.ID, reservation.UserID); err != nil {
+		writeStockError(w, err)
+		return
+	}
+
+	committedReservation, _ := h.store.GetStockReservationByID(r.Context(), reservation.ID)
+	utils.WriteJSON(w, http.StatusOK, committedReservation)
+}
+
+func (h *Handler) handleReleaseReservation(w http.ResponseWriter, r *http.Request) {
+	reservation, ok := h.getOwnReservation(w, r)
+	if !ok {
+		return
+	}
+
+	rowsAffected, err := h.store.ReleaseStockReservation(r.Context(), reservation.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to release reservation: %w", err))
+		return
+	}
+
+	if rowsAffected == 0 {
+		utils.WriteError(w, http.StatusConflict, ErrReservationNotActive)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetLocations lists the stock locations of a shop, the default
+// location first.
+func (h *Handler) handleGetLocations(w http.ResponseWriter, r *http.Request) {
+	shopID, err := strconv.Atoi(r.URL.Query().Get("shop_id"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing or invalid shop ID"))
+		return
+	}
+
+	allowed, err := shopmember.HasRole(r.Context(), h.memberStore, shopID, auth.GetUserIDFromContext(r.Context()), types.ShopRoleStaff)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !allowed {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you do not have permission to manage the stock of this shop"))
+		return
+	}
+
+	locations, err := h.store.GetStockLocations(r.Context(), shopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, locations)
+}
+
+func (h *Handler) handleCreateLocation(w http.ResponseWriter, r *http.Request) {
+	location, ok := parseLocation(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := h.shopStore.GetShopByID(r.Context(), location.ShopID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	allowed, err := shopmember.HasRole(r.Context(), h.memberStore, location.ShopID, auth.GetUserIDFromContext(r.Context()), types.ShopRoleManager)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !allowed {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you do not have permission to manage the stock of this shop"))
+		return
+	}
+
+	locationID, err := h.store.CreateStockLocation(r.Context(), location)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	createdLocation, _ := h.store.GetStockLocationByID(r.Context(), locationID)
+	utils.WriteJSON(w, http.StatusCreated, createdLocation)
+}
+
+func (h *Handler) handleGetLocation(w http.ResponseWriter, r *http.Request) {
+	location, ok := h.getShopLocation(w, r, types.ShopRoleStaff)
+	if !ok {
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, location)
+}
+
+func (h *Handler) handleUpdateLocation(w http.ResponseWriter, r *http.Request) {
+	existingLocation, ok := h.getShopLocation(w, r, types.ShopRoleManager)
+	if !ok {
+		return
+	}
+
+	location, ok := parseLocation(w, r)
+	if !ok {
+		return
+	}
+
+	// A location stays with the shop it was created for.
+	location.ShopID = existingLocation.ShopID
+
+	if err := h.store.UpdateStockLocation(r.Context(), existingLocation.ID, location); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	updatedLocation, _ := h.store.GetStockLocationByID(r.Context(), existingLocation.ID)
+	utils.WriteJSON(w, http.StatusOK, updatedLocation)
+}
+
+func (h *Handler) handleDeleteLocation(w http.ResponseWriter, r *http.Request) {
+	existingLocation, ok := h.getShopLocation(w, r, types.ShopRoleManager)
+	if !ok {
+		return
+	}
+
+	rowsAffected, err := h.store.DeleteStockLocation(r.Context(), existingLocation.ID)
+	if err != nil {
+		writeStockError(w, err)
+		return
+	}
+
+	if rowsAffected == 0 {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("stock location not found"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getShopLocation loads the location from the request path and makes sure
+// the authenticated user holds at least the given role in its shop.
+func (h *Handler) getShopLocation(w http.ResponseWriter, r *http.Request, role string) (*types.StockLocation, bool) {
+	vars := mux.Vars(r)
+	str, ok := vars["location_id"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing stock location ID"))
+		return nil, false
+	}
+
+	locationID, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid stock location ID"))
+		return nil, false
+	}
+
+	location, err := h.store.GetStockLocationByID(r.Context(), locationID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	allowed, err := shopmember.HasRole(r.Context(), h.memberStore, location.ShopID, auth.GetUserIDFromContext(r.Context()), role)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	if !allowed {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you do not have permission to manage the stock of this shop"))
+		return nil, false
+	}
+
+	return location, true
+}
+
+func (h *Handler) isShopLocation(ctx context.Context, locationID int, shopID int) bool {
+	location, err := h.store.GetStockLocationByID(ctx, locationID)
+
+	return err == nil && location.ShopID == shopID
+}
+
+// getShopProduct loads the product from the request path and makes sure the
+// authenticated user holds at least the given role in its shop.
+func (h *Handler) getShopProduct(w http.ResponseWriter, r *http.Request, role string) (*types.Product, bool) {
+	vars := mux.Vars(r)
+	str, ok := vars["product_id"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing product ID"))
+		return nil, false
+	}
+
+	productID, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid product ID"))
+		return nil, false
+	}
+
+	product, err := h.productStore.GetProductByID(r.Context(), productID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	allowed, err := shopmember.HasRole(r.Context(), h.memberStore, product.ShopID, auth.GetUserIDFromContext(r.Context()), role)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	if !allowed {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you do not have permission to manage the stock of this product"))
+		return nil, false
+	}
+
+	return product, true
+}
+
+func (h *Handler) getOwnReservation(w http.ResponseWriter, r *http.Request) (*types.StockReservation, bool) {
+	vars := mux.Vars(r)
+	str, ok := vars["reservation_id"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing reservation ID"))
+		return nil, false
+	}
+
+	reservationID, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid reservation ID"))
+		return nil, false
+	}
+
+	reservation, err := h.store.GetStockReservationByID(r.Context(), reservationID)
+	if err != nil || reservation.UserID != auth.GetUserIDFromContext(r.Context()) {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("reservation not found"))
+		return nil, false
+	}
+
+	return reservation, true
+}
+
+// parseLocation reads and validates the location in the request body,
+// returning its address normalized.
+func parseLocation(w http.ResponseWriter, r *http.Request) (types.CreateUpdateStockLocationPayload, bool) {
+	var location types.CreateUpdateStockLocationPayload
+	if err := utils.ParseJSON(r, &location); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return location, false
+	}
+
+	if err := utils.Validate.Struct(location); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return location, false
+	}
+
+	normalized := address.Normalize(types.Address{
+		Street:     location.Street,
+		Street2:    location.Street2,
+		City:       location.City,
+		Region:     location.Region,
+		PostalCode: location.PostalCode,
+		Country:    location.Country,
+	})
+
+	if normalized.Country != "" && normalized.PostalCode != "" {
+		if err := address.ValidatePostalCode(normalized.Country, normalized.PostalCode); err != nil {
+			utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", err))
+			return location, false
+		}
+	}
+
+	location.Street = normalized.Street
+	location.Street2 = normalized.Street2
+	location.City = normalized.City
+	location.Region = normalized.Region
+	location.PostalCode = normalized.PostalCode
+	location.Country = normalized.Country
+
+	return location, true
+}
+
+// ValidateMovement checks the rules of a stock movement that struct tags
+// cannot express.
+func ValidateMovement(movement types.RecordStockMovementPayload) error {
+	if movement.Type != types.StockMovementAdjustment && movement.Quantity < 0 {
+		return fmt.Errorf("the quantity of a %s must be positive", movement.Type)
+	}
+
+	if movement.Type == types.StockMovementAdjustment && (movement.Reason == nil || *movement.Reason == "") {
+		return fmt.Errorf("adjustments need a reason")
+	}
+
+	return nil
+}
+
+func writeStockError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInsufficientStock), errors.Is(err, ErrReservationNotActive),
+		errors.Is(err, ErrLocationNotEmpty), errors.Is(err, ErrDefaultLocation):
+		utils.WriteError(w, http.StatusConflict, err)
+	default:
+		utils.WriteError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func pagination(limitStr, offsetStr string) (int, int, error) {
+	limit, offset := defaultPageSize, 0
+
+	if limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n < 1 {
+			return 0, 0, fmt.Errorf("invalid limit")
+		}
+		limit = min(n, maxPageSize)
+	}
+
+	if offsetStr != "" {
+		n, err := strconv.Atoi(offsetStr)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("invalid offset")
+		}
+		offset = n
+	}
+
+	return limit, offset, nil
+}
@@ -0,0 +1,821 @@
+package inventory
+
+import (
+	"context"
+	"database/sql"
+	"ecom_go/db"
+	"ecom_go/types"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var (
+	ErrInsufficientStock    = errors.New("insufficient stock")
+	ErrReservationNotActive = errors.New("reservation is no longer active")
+	ErrLocationNotEmpty     = errors.New("stock location still holds stock")
+	ErrDefaultLocation      = errors.New("the default stock location cannot be deleted")
+)
+
+type Store struct {
+	db *db.DB
+}
+
+func NewStore(db *db.DB) *Store {
+	return &Store{db: db}
+}
+
+// queryer is implemented by both *db.DB and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+const (
+	stockMovementColumns = "id, product_id, type, quantity, location_id, reason, actor_id, reservation_id, created_at, updated_at"
+	stockLocationColumns = `id, shop_id, name, street, street2, city, region, postal_code, country, latitude, longitude,
+		is_default, created_at, updated_at`
+)
+
+// locationStock is the stock of a product at one location of its shop.
+type locationStock struct {
+	types.LocationStockLevel
+	Point *types.GeoPoint
+}
+
+func (s *Store) GetStockLevel(ctx context.Context, productID int) (*types.StockLevel, error) {
+	level := &types.StockLevel{ProductID: productID}
+
+	var shopID int
+	err := s.db.QueryRowContext(ctx, "SELECT shop_id, quantity, low_stock_threshold FROM products WHERE id = ? AND deleted_at IS NULL", productID).
+		Scan(&shopID, &level.OnHand, &level.LowStockThreshold)
+	if err != nil {
+		return nil, types.NotFound("product", err)
+	}
+
+	if err := s.db.QueryRowContext(ctx, "SELECT COALESCE(SUM(quantity), 0) FROM stock_movements WHERE product_id = ?", productID).
+		Scan(&level.LedgerOnHand); err != nil {
+		return nil, err
+	}
+
+	stocks, err := stockByLocation(ctx, s.db, productID, shopID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	locationsOnHand := 0
+	level.Locations = []types.LocationStockLevel{}
+	for _, stock := range stocks {
+		level.Locations = append(level.Locations, stock.LocationStockLevel)
+		level.Reserved += stock.Reserved
+		locationsOnHand += stock.OnHand
+	}
+
+	level.Available = level.OnHand - level.Reserved
+	level.Reconciled = level.OnHand == level.LedgerOnHand && level.OnHand == locationsOnHand
+	level.LowStock = level.LowStockThreshold != nil && level.Available <= *level.LowStockThreshold
+
+	return level, nil
+}
+
+func (s *Store) GetStockMovements(ctx context.Context, productID int, limit int, offset int) ([]types.StockMovement, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+stockMovementColumns+" FROM stock_movements WHERE product_id = ? ORDER BY id DESC LIMIT ? OFFSET ?",
+		productID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := []types.StockMovement{}
+	for rows.Next() {
+		movement, err := scanStockMovement(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		movements = append(movements, *movement)
+	}
+
+	return movements, rows.Err()
+}
+
+// RecordStockMovement appends the movement to the ledger and applies it to
+// the stock at its location, the default location of the shop when none is
+// given. Receipts, returns and sales take a positive quantity, adjustments a
+// signed one. The stock at a location never goes below zero.
+func (s *Store) RecordStockMovement(ctx context.Context, movement types.RecordStockMovementPayload) (*types.StockChange, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	product, err := lockProduct(ctx, tx, movement.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	locationID, err := resolveLocation(ctx, tx, product.shopID, movement.LocationID)
+	if err != nil {
+		return nil, err
+	}
+
+	stocks, err := stockByLocation(ctx, tx, movement.ProductID, product.shopID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	delta := movement.Quantity
+	if movement.Type == types.StockMovementSale {
+		delta = -movement.Quantity
+	}
+
+	stock := findStock(stocks, locationID)
+	if stock.OnHand+delta < 0 {
+		return nil, fmt.Errorf("%w: only %d on hand at %s", ErrInsufficientStock, stock.OnHand, stock.Name)
+	}
+
+	if err := applyMovement(ctx, tx, movement.ProductID, locationID, movement.Type, delta, movement.Reason, &movement.ActorID, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return product.change(totalReserved(stocks), delta), nil
+}
+
+// SetStockQuantity books the difference between the quantity and the stock
+// on hand as adjustments. Added stock goes to the default location, removed
+// stock comes out of the default location first and then out of the
+// locations holding the most.
+func (s *Store) SetStockQuantity(ctx context.Context, productID int, quantity int, actorID int) (*types.StockChange, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	product, err := lockProduct(ctx, tx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	stocks, err := stockByLocation(ctx, tx, productID, product.shopID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	delta := quantity - product.onHand
+	reason := "quantity set on product"
+
+	if delta > 0 {
+		locationID, err := resolveLocation(ctx, tx, product.shopID, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := applyMovement(ctx, tx, productID, locationID, types.StockMovementAdjustment, delta, &reason, &actorID, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if delta < 0 {
+		// stockByLocation lists the default location first, the others are
+		// drained from the one holding the most.
+		rest := stocks[min(1, len(stocks)):]
+		sort.SliceStable(rest, func(i, j int) bool { return rest[i].OnHand > rest[j].OnHand })
+
+		remaining := -delta
+		for _, stock := range stocks {
+			take := min(stock.OnHand, remaining)
+			if take == 0 {
+				continue
+			}
+
+			if err := applyMovement(ctx, tx, productID, stock.LocationID, types.StockMovementAdjustment, -take, &reason, &actorID, nil); err != nil {
+				return nil, err
+			}
+			remaining -= take
+		}
+
+		if remaining > 0 {
+			return nil, fmt.Errorf("stock locations do not add up to the quantity of product %d", productID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return product.change(totalReserved(stocks), delta), nil
+}
+
+// TransferStock moves stock between two locations of the shop. Only stock
+// that is not reserved can be transferred.
+func (s *Store) TransferStock(ctx context.Context, transfer types.TransferStockPayload) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	product, err := lockProduct(ctx, tx, transfer.ProductID)
+	if err != nil {
+		return err
+	}
+
+	for _, locationID := range []int{transfer.FromLocationID, transfer.ToLocationID} {
+		if _, err := resolveLocation(ctx, tx, product.shopID, &locationID); err != nil {
+			return err
+		}
+	}
+
+	stocks, err := stockByLocation(ctx, tx, transfer.ProductID, product.shopID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	from := findStock(stocks, transfer.FromLocationID)
+	if from.Available < transfer.Quantity {
+		return fmt.Errorf("%w: only %d available at %s", ErrInsufficientStock, max(from.Available, 0), from.Name)
+	}
+
+	if err := applyMovement(ctx, tx, transfer.ProductID, transfer.FromLocationID, types.StockMovementTransfer, -transfer.Quantity,
+		transfer.Reason, &transfer.ActorID, nil); err != nil {
+		return err
+	}
+
+	if err := applyMovement(ctx, tx, transfer.ProductID, transfer.ToLocationID, types.StockMovementTransfer, transfer.Quantity,
+		transfer.Reason, &transfer.ActorID, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) SetLowStockThreshold(ctx context.Context, productID int, threshold *int) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE products SET low_stock_threshold = ? WHERE id = ? AND deleted_at IS NULL", threshold, productID)
+	return err
+}
+
+func (s *Store) GetStockLocations(ctx context.Context, shopID int) ([]types.StockLocation, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+stockLocationColumns+" FROM stock_locations WHERE shop_id = ? ORDER BY is_default DESC, id", shopID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := []types.StockLocation{}
+	for rows.Next() {
+		location, err := scanStockLocation(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		locations = append(locations, *location)
+	}
+
+	return locations, rows.Err()
+}
+
+func (s *Store) GetStockLocationByID(ctx context.Context, locationID int) (*types.StockLocation, error) {
+	location, err := scanStockLocation(s.db.QueryRowContext(ctx, "SELECT "+stockLocationColumns+" FROM stock_locations WHERE id = ?", locationID))
+	if err != nil {
+		return nil, types.NotFound("stock location", err)
+	}
+
+	return location, nil
+}
+
+func (s *Store) CreateStockLocation(ctx context.Context, location types.CreateUpdateStockLocationPayload) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	locationID, err := s.db.InsertTx(ctx, tx,
+		"INSERT INTO stock_locations (shop_id, name, street, street2, city, region, postal_code, country, latitude, longitude) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		location.ShopID, location.Name, location.Street, location.Street2, location.City, location.Region, location.PostalCode, location.Country,
+		location.Latitude, location.Longitude)
+	if err != nil {
+		return 0, err
+	}
+
+	if location.IsDefault {
+		if err := setDefaultLocation(ctx, tx, location.ShopID, int(locationID)); err != nil {
+			return 0, err
+		}
+	}
+
+	return int(locationID), tx.Commit()
+}
+
+// UpdateStockLocation updates the location. A location only stops being the
+// default one when another location is made the default.
+func (s *Store) UpdateStockLocation(ctx context.Context, locationID int, location types.CreateUpdateStockLocationPayload) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE stock_locations SET name = ?, street = ?, street2 = ?, city = ?, region = ?, postal_code = ?, country = ?, latitude = ?, longitude = ? WHERE id = ?",
+		location.Name, location.Street, location.Street2, location.City, location.Region, location.PostalCode, location.Country,
+		location.Latitude, location.Longitude, locationID); err != nil {
+		return err
+	}
+
+	if location.IsDefault {
+		if err := setDefaultLocation(ctx, tx, location.ShopID, locationID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteStockLocation removes a location that holds no stock. The default
+// location cannot be removed.
+func (s *Store) DeleteStockLocation(ctx context.Context, locationID int) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var isDefault bool
+	err = tx.QueryRowContext(ctx, "SELECT is_default FROM stock_locations WHERE id = ? FOR UPDATE", locationID).Scan(&isDefault)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if isDefault {
+		return 0, ErrDefaultLocation
+	}
+
+	var quantity int
+	if err := tx.QueryRowContext(ctx, "SELECT COALESCE(SUM(quantity), 0) FROM location_stock WHERE location_id = ?", locationID).Scan(&quantity); err != nil {
+		return 0, err
+	}
+
+	if quantity > 0 {
+		return 0, ErrLocationNotEmpty
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM stock_locations WHERE id = ?", locationID)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, tx.Commit()
+}
+
+func (s *Store) GetStockReservationByID(ctx context.Context, reservationID int) (*types.StockReservation, error) {
+	reservation := new(types.StockReservation)
+
+	err := s.db.QueryRowContext(ctx, "SELECT id, user_id, status, expires_at, created_at, updated_at FROM stock_reservations WHERE id = ?", reservationID).Scan(
+		&reservation.ID,
+		&reservation.UserID,
+		&reservation.Status,
+		&reservation.ExpiresAt,
+		&reservation.CreatedAt,
+		&reservation.UpdatedAt,
+	)
+	if err != nil {
+		return nil, types.NotFound("reservation", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT id, reservation_id, product_id, location_id, quantity, created_at, updated_at FROM stock_reservation_items WHERE reservation_id = ? ORDER BY id", reservationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reservation.Items = []types.StockReservationItem{}
+	for rows.Next() {
+		item := types.StockReservationItem{}
+		if err := rows.Scan(
+			&item.ID,
+			&item.ReservationID,
+			&item.ProductID,
+			&item.LocationID,
+			&item.Quantity,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		reservation.Items = append(reservation.Items, item)
+	}
+
+	return reservation, rows.Err()
+}
+
+// CreateStockReservation holds the items until expiresAt, allocating each
+// of them to the locations of its shop with the allocation strategy of the
+// shop. Either every item is reserved or, when one of them is short on
+// stock, none is.
+func (s *Store) CreateStockReservation(ctx context.Context, userID int, items []types.CartItemPayload, buyer *types.GeoPoint, expiresAt time.Time) (int, []types.StockChange, error) {
+	quantities := map[int]int{}
+	productIDs := []int{}
+	for _, item := range items {
+		if _, ok := quantities[item.ProductID]; !ok {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+
+	// Lock products in a fixed order so concurrent checkouts cannot deadlock.
+	sort.Ints(productIDs)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	changes := []types.StockChange{}
+	allocations := map[int][]Allocation{}
+	for _, productID := range productIDs {
+		product, err := lockProduct(ctx, tx, productID)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		var strategy string
+		if err := tx.QueryRowContext(ctx, "SELECT allocation_strategy FROM shops WHERE id = ?", product.shopID).Scan(&strategy); err != nil {
+			return 0, nil, err
+		}
+
+		stocks, err := stockByLocation(ctx, tx, productID, product.shopID, now)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		candidates := make([]Candidate, len(stocks))
+		for i, stock := range stocks {
+			candidates[i] = Candidate{LocationID: stock.LocationID, Available: stock.Available, Point: stock.Point}
+		}
+
+		allocated, ok := Allocate(strategy, candidates, quantities[productID], buyer)
+		if !ok {
+			available := product.onHand - totalReserved(stocks)
+			return 0, nil, fmt.Errorf("%w: only %d of product %d available", ErrInsufficientStock, max(available, 0), productID)
+		}
+
+		allocations[productID] = allocated
+		changes = append(changes, *product.change(totalReserved(stocks), -quantities[productID]))
+	}
+
+	reservationID, err := s.db.InsertTx(ctx, tx, "INSERT INTO stock_reservations (user_id, status, expires_at) VALUES (?, ?, ?)",
+		userID, types.ReservationStatusActive, expiresAt)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	for _, productID := range productIDs {
+		for _, allocation := range allocations[productID] {
+			if _, err := tx.ExecContext(ctx, "INSERT INTO stock_reservation_items (reservation_id, product_id, location_id, quantity) VALUES (?, ?, ?, ?)",
+				reservationID, productID, allocation.LocationID, allocation.Quantity); err != nil {
+				return 0, nil, err
+			}
+		}
+	}
+
+	return int(reservationID), changes, tx.Commit()
+}
+
+// CommitStockReservation turns an active reservation into sales in the stock
+// ledger, taken from the locations the items were allocated to. It is meant
+// to be called once the order of the checkout is placed.
+func (s *Store) CommitStockReservation(ctx context.Context, reservationID int, actorID int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	var expiresAt time.Time
+	err = tx.QueryRowContext(ctx, "SELECT status, expires_at FROM stock_reservations WHERE id = ? FOR UPDATE", reservationID).Scan(&status, &expiresAt)
+	if err != nil {
+		return types.NotFound("reservation", err)
+	}
+
+	if status != types.ReservationStatusActive || !expiresAt.After(time.Now().UTC()) {
+		return ErrReservationNotActive
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT product_id, location_id, quantity FROM stock_reservation_items WHERE reservation_id = ? ORDER BY product_id, location_id", reservationID)
+	if err != nil {
+		return err
+	}
+
+	items := []types.StockReservationItem{}
+	for rows.Next() {
+		item := types.StockReservationItem{}
+		if err := rows.Scan(&item.ProductID, &item.LocationID, &item.Quantity); err != nil {
+			rows.Close()
+			return err
+		}
+
+		items = append(items, item)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	locked := map[int][]locationStock{}
+	for _, item := range items {
+		if _, ok := locked[item.ProductID]; !ok {
+			product, err := lockProduct(ctx, tx, item.ProductID)
+			if err != nil {
+				return err
+			}
+
+			// The stock held by the reservation is part of what is on
+			// hand at the locations it was allocated to.
+			stocks, err := stockByLocation(ctx, tx, item.ProductID, product.shopID, time.Now().UTC())
+			if err != nil {
+				return err
+			}
+			locked[item.ProductID] = stocks
+		}
+
+		stock := findStock(locked[item.ProductID], item.LocationID)
+		if stock.OnHand < item.Quantity {
+			return fmt.Errorf("%w: only %d of product %d on hand at %s", ErrInsufficientStock, stock.OnHand, item.ProductID, stock.Name)
+		}
+
+		if err := applyMovement(ctx, tx, item.ProductID, item.LocationID, types.StockMovementSale, -item.Quantity, nil, &actorID, &reservationID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE stock_reservations SET status = ? WHERE id = ?", types.ReservationStatusCommitted, reservationID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) ReleaseStockReservation(ctx context.Context, reservationID int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "UPDATE stock_reservations SET status = ? WHERE id = ? AND status = ?",
+		types.ReservationStatusReleased, reservationID, types.ReservationStatusActive)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// ExpireStockReservations marks active reservations past their expiry as
+// expired.
+func (s *Store) ExpireStockReservations(ctx context.Context, now time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "UPDATE stock_reservations SET status = ? WHERE status = ? AND expires_at <= ?",
+		types.ReservationStatusExpired, types.ReservationStatusActive, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// lockedProduct is the stock of a product read under its row lock.
+type lockedProduct struct {
+	id        int
+	shopID    int
+	onHand    int
+	threshold *int
+}
+
+// change reports how a stock change of delta moves the available stock of
+// the product.
+func (p lockedProduct) change(reserved int, delta int) *types.StockChange {
+	return &types.StockChange{
+		ProductID:         p.id,
+		AvailableBefore:   p.onHand - reserved,
+		AvailableAfter:    p.onHand - reserved + delta,
+		LowStockThreshold: p.threshold,
+	}
+}
+
+// lockProduct locks the product row for the rest of the transaction. Every
+// change to the stock of a product starts by locking its row, which
+// serializes the changes to its quantity, its stock at each location and its
+// reservations.
+func lockProduct(ctx context.Context, tx *sql.Tx, productID int) (lockedProduct, error) {
+	product := lockedProduct{id: productID}
+
+	err := tx.QueryRowContext(ctx, "SELECT shop_id, quantity, low_stock_threshold FROM products WHERE id = ? AND deleted_at IS NULL FOR UPDATE", productID).
+		Scan(&product.shopID, &product.onHand, &product.threshold)
+
+	return product, types.NotFound(fmt.Sprintf("product %d", productID), err)
+}
+
+// resolveLocation checks that the location belongs to the shop, defaulting
+// to the default location of the shop.
+func resolveLocation(ctx context.Context, tx *sql.Tx, shopID int, locationID *int) (int, error) {
+	if locationID == nil {
+		var id int
+		err := tx.QueryRowContext(ctx, "SELECT id FROM stock_locations WHERE shop_id = ? AND is_default = TRUE", shopID).Scan(&id)
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("shop has no default stock location")
+		}
+
+		return id, err
+	}
+
+	var locationShopID int
+	err := tx.QueryRowContext(ctx, "SELECT shop_id FROM stock_locations WHERE id = ?", *locationID).Scan(&locationShopID)
+	if err == nil && locationShopID != shopID {
+		// The locations of other shops are as good as missing.
+		err = sql.ErrNoRows
+	}
+
+	return *locationID, types.NotFound(fmt.Sprintf("stock location %d", *locationID), err)
+}
+
+// stockByLocation returns the stock of the product at every location of the
+// shop, the default location first. Reserved stock only counts reservations
+// that are active and not yet expired.
+func stockByLocation(ctx context.Context, q queryer, productID int, shopID int, now time.Time) ([]locationStock, error) {
+	reserved := map[int]int{}
+
+	reservedRows, err := q.QueryContext(ctx,
+		`SELECT i.location_id, SUM(i.quantity) FROM stock_reservation_items i
+			JOIN stock_reservations r ON r.id = i.reservation_id
+		WHERE i.product_id = ? AND r.status = ? AND r.expires_at > ?
+		GROUP BY i.location_id`,
+		productID, types.ReservationStatusActive, now)
+	if err != nil {
+		return nil, err
+	}
+	defer reservedRows.Close()
+
+	for reservedRows.Next() {
+		var locationID, quantity int
+		if err := reservedRows.Scan(&locationID, &quantity); err != nil {
+			return nil, err
+		}
+		reserved[locationID] = quantity
+	}
+
+	if err := reservedRows.Err(); err != nil {
+		return nil, err
+	}
+	reservedRows.Close()
+
+	rows, err := q.QueryContext(ctx,
+		`SELECT l.id, l.name, l.latitude, l.longitude, COALESCE(ls.quantity, 0) FROM stock_locations l
+			LEFT JOIN location_stock ls ON ls.location_id = l.id AND ls.product_id = ?
+		WHERE l.shop_id = ?
+		ORDER BY l.is_default DESC, l.id`,
+		productID, shopID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stocks := []locationStock{}
+	for rows.Next() {
+		stock := locationStock{}
+		var latitude, longitude *float64
+		if err := rows.Scan(&stock.LocationID, &stock.Name, &latitude, &longitude, &stock.OnHand); err != nil {
+			return nil, err
+		}
+
+		if latitude != nil && longitude != nil {
+			stock.Point = &types.GeoPoint{Latitude: *latitude, Longitude: *longitude}
+		}
+		stock.Reserved = reserved[stock.LocationID]
+		stock.Available = stock.OnHand - stock.Reserved
+
+		stocks = append(stocks, stock)
+	}
+
+	return stocks, rows.Err()
+}
+
+func findStock(stocks []locationStock, locationID int) locationStock {
+	for _, stock := range stocks {
+		if stock.LocationID == locationID {
+			return stock
+		}
+	}
+
+	return locationStock{LocationStockLevel: types.LocationStockLevel{LocationID: locationID}}
+}
+
+func totalReserved(stocks []locationStock) int {
+	reserved := 0
+	for _, stock := range stocks {
+		reserved += stock.Reserved
+	}
+
+	return reserved
+}
+
+// applyMovement appends a movement to the ledger and applies it to the stock
+// of the product at the location and to its total quantity.
+func applyMovement(ctx context.Context, tx *sql.Tx, productID int, locationID int, movementType string, delta int, reason *string, actorID *int, reservationID *int) error {
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO stock_movements (product_id, type, quantity, location_id, reason, actor_id, reservation_id) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		productID, movementType, delta, locationID, reason, actorID, reservationID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE products SET quantity = quantity + ? WHERE id = ?", delta, productID); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, "UPDATE location_stock SET quantity = quantity + ? WHERE location_id = ? AND product_id = ?",
+		delta, locationID, productID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		_, err = tx.ExecContext(ctx, "INSERT INTO location_stock (location_id, product_id, quantity) VALUES (?, ?, ?)",
+			locationID, productID, delta)
+	}
+
+	return err
+}
+
+// setDefaultLocation makes the location the only default location of the
+// shop.
+func setDefaultLocation(ctx context.Context, tx *sql.Tx, shopID int, locationID int) error {
+	_, err := tx.ExecContext(ctx, "UPDATE stock_locations SET is_default = (id = ?) WHERE shop_id = ?", locationID, shopID)
+	return err
+}
+
+func scanStockMovement(row db.Scanner) (*types.StockMovement, error) {
+	movement := new(types.StockMovement)
+
+	err := row.Scan(
+		&movement.ID,
+		&movement.ProductID,
+		&movement.Type,
+		&movement.Quantity,
+		&movement.LocationID,
+		&movement.Reason,
+		&movement.ActorID,
+		&movement.ReservationID,
+		&movement.CreatedAt,
+		&movement.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+func scanStockLocation(row db.Scanner) (*types.StockLocation, error) {
+	location := new(types.StockLocation)
+
+	err := row.Scan(
+		&location.ID,
+		&location.ShopID,
+		&location.Name,
+		&location.Street,
+		&location.Street2,
+		&location.City,
+		&location.Region,
+		&location.PostalCode,
+		&location.Country,
+		&location.Latitude,
+		&location.Longitude,
+		&location.IsDefault,
+		&location.CreatedAt,
+		&location.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return location, nil
+}
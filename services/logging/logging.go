@@ -0,0 +1,161 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"ecom_go/utils"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds request IDs taken from clients, longer ones are
+// replaced by a generated ID.
+const maxRequestIDLength = 128
+
+type contextKey string
+
+const (
+	loggerKey  contextKey = "logger"
+	requestKey contextKey = "request"
+)
+
+// New returns a logger writing to w as JSON or, for any other format, as
+// text. The level is one of debug, info, warn or error.
+func New(w io.Writer, format string, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+
+	options := &slog.HandlerOptions{Level: lvl}
+
+	switch strings.ToLower(format) {
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q", format)
+	}
+}
+
+// WithLogger returns a copy of ctx carrying the logger.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// FromContext returns the logger of the request ctx belongs to, which tags
+// every record with the request ID, or the default logger outside requests.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return logger
+	}
+
+	return slog.Default()
+}
+
+// requestInfo collects what the access log reports but is only known deeper
+// down the handler chain.
+type requestInfo struct {
+	route  string
+	userID int
+}
+
+// SetUser records the authenticated user of the request for the access log
+// and returns ctx with a logger that tags records with the user.
+func SetUser(ctx context.Context, userID int) context.Context {
+	if info, ok := ctx.Value(requestKey).(*requestInfo); ok {
+		info.userID = userID
+	}
+
+	return WithLogger(ctx, FromContext(ctx).With("user_id", userID))
+}
+
+// Middleware assigns each request an ID, taken from the X-Request-ID header
+// when the client sent a usable one, echoes it in the response and puts a
+// logger tagged with it in the request context. Once the request is served
+// it writes an access log record.
+func Middleware(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		requestLogger := logger.With("request_id", requestID)
+		info := &requestInfo{}
+
+		ctx := context.WithValue(r.Context(), requestKey, info)
+		ctx = WithLogger(ctx, requestLogger)
+
+		recorder := utils.NewStatusRecorder(w)
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		attrs := []any{
+			"method", r.Method,
+			"route", info.route,
+			"path", r.URL.Path,
+			"status", recorder.Status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"bytes", recorder.Bytes,
+		}
+		if info.userID != 0 {
+			attrs = append(attrs, "user_id", info.userID)
+		}
+
+		level := slog.LevelInfo
+		if recorder.Status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		requestLogger.Log(ctx, level, "request", attrs...)
+	})
+}
+
+// RecordRoute is a router middleware that records the path template of the
+// matched route for the access log. Unmatched requests are logged without
+// one.
+func RecordRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if info, ok := r.Context().Value(requestKey).(*requestInfo); ok {
+			if route := mux.CurrentRoute(r); route != nil {
+				info.route, _ = route.GetPathTemplate()
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
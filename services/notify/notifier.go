@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"ecom_go/types"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// NewNotifier returns an SMTP notifier when host is set and a notifier that
+// only logs messages otherwise.
+func NewNotifier(host, port, username, password, from string) types.Notifier {
+	if host == "" {
+		return &LogNotifier{}
+	}
+
+	return NewSMTPNotifier(host, port, username, password, from)
+}
+
+// LogNotifier writes notifications to the log instead of delivering them. It
+// is meant for local development.
+type LogNotifier struct{}
+
+func (n *LogNotifier) Notify(email, subject, body string) error {
+	slog.Info("notification", "email", email, "subject", subject, "body", body)
+	return nil
+}
+
+type SMTPNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTPNotifier(host, port, username, password, from string) *SMTPNotifier {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPNotifier{
+		addr: net.JoinHostPort(host, port),
+		auth: auth,
+		from: from,
+	}
+}
+
+func (n *SMTPNotifier) Notify(email, subject, body string) error {
+	if strings.ContainsAny(email, "\r\n") || strings.ContainsAny(subject, "\r\n") {
+		return fmt.Errorf("invalid notification header")
+	}
+
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		n.from, email, subject, body)
+
+	return smtp.SendMail(n.addr, n.auth, n.from, []string{email}, []byte(msg))
+}
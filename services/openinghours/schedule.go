@@ -0,0 +1,133 @@
+package openinghours
+
+import (
+	"ecom_go/types"
+	"fmt"
+	"time"
+)
+
+// searchDays bounds how far ahead NextOpening looks for an opening interval,
+// so long closures declared through exceptions are still found.
+const searchDays = 366
+
+type Schedule struct {
+	Location   *time.Location
+	Weekly     []types.OpeningHours
+	Exceptions []types.ShopHoursException
+}
+
+type interval struct {
+	start time.Time
+	end   time.Time
+}
+
+func NewSchedule(timezone string, weekly []types.OpeningHours, exceptions []types.ShopHoursException) (*Schedule, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", timezone, err)
+	}
+
+	return &Schedule{Location: loc, Weekly: weekly, Exceptions: exceptions}, nil
+}
+
+// IsOpen reports whether now falls within any opening interval. The previous
+// day is included so overnight intervals are taken into account.
+func (s *Schedule) IsOpen(now time.Time) bool {
+	local := now.In(s.Location)
+	for day := -1; day <= 0; day++ {
+		for _, iv := range s.intervalsOn(local.AddDate(0, 0, day)) {
+			if !now.Before(iv.start) && now.Before(iv.end) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// NextOpening returns the start of the first opening interval after now.
+func (s *Schedule) NextOpening(now time.Time) (time.Time, bool) {
+	local := now.In(s.Location)
+	for day := 0; day <= searchDays; day++ {
+		for _, iv := range s.intervalsOn(local.AddDate(0, 0, day)) {
+			if iv.start.After(now) {
+				return iv.start, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// intervalsOn returns the opening intervals starting on the calendar day of
+// date, ordered by start time. Exceptions replace the weekly schedule.
+func (s *Schedule) intervalsOn(date time.Time) []interval {
+	day := date.Format(time.DateOnly)
+
+	intervals := []interval{}
+	overridden := false
+	for _, e := range s.Exceptions {
+		if e.Date != day {
+			continue
+		}
+
+		overridden = true
+		if e.OpensAt == nil || e.ClosesAt == nil {
+			continue
+		}
+
+		if iv, ok := s.newInterval(date, *e.OpensAt, *e.ClosesAt); ok {
+			intervals = append(intervals, iv)
+		}
+	}
+
+	if !overridden {
+		for _, h := range s.Weekly {
+			if h.Weekday != int(date.Weekday()) {
+				continue
+			}
+
+			if iv, ok := s.newInterval(date, h.OpensAt, h.ClosesAt); ok {
+				intervals = append(intervals, iv)
+			}
+		}
+	}
+
+	for i := 1; i < len(intervals); i++ {
+		for j := i; j > 0 && intervals[j].start.Before(intervals[j-1].start); j-- {
+			intervals[j], intervals[j-1] = intervals[j-1], intervals[j]
+		}
+	}
+
+	return intervals
+}
+
+func (s *Schedule) newInterval(date time.Time, opensAt, closesAt string) (interval, bool) {
+	openHour, openMinute, err := parseClock(opensAt)
+	if err != nil {
+		return interval{}, false
+	}
+
+	closeHour, closeMinute, err := parseClock(closesAt)
+	if err != nil {
+		return interval{}, false
+	}
+
+	year, month, day := date.Date()
+	start := time.Date(year, month, day, openHour, openMinute, 0, 0, s.Location)
+	end := time.Date(year, month, day, closeHour, closeMinute, 0, 0, s.Location)
+	if !end.After(start) {
+		end = time.Date(year, month, day+1, closeHour, closeMinute, 0, 0, s.Location)
+	}
+
+	return interval{start: start, end: end}, true
+}
+
+func parseClock(value string) (int, int, error) {
+	t, err := time.Parse("15:04", formatClock(value))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return t.Hour(), t.Minute(), nil
+}
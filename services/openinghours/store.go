@@ -0,0 +1,165 @@
+package openinghours
+
+import (
+	"context"
+	"ecom_go/db"
+	"ecom_go/types"
+	"time"
+)
+
+type Store struct {
+	db *db.DB
+}
+
+func NewStore(db *db.DB) *Store {
+	return &Store{db: db}
+}
+
+const (
+	openingHoursColumns       = "id, shop_id, weekday, opens_at, closes_at, created_at, updated_at"
+	shopHoursExceptionColumns = "id, shop_id, date, opens_at, closes_at, note, created_at, updated_at"
+)
+
+func (s *Store) GetOpeningHours(ctx context.Context, shopID int) ([]types.OpeningHours, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+openingHoursColumns+" FROM shop_opening_hours WHERE shop_id = ? ORDER BY weekday, opens_at", shopID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hours := []types.OpeningHours{}
+	for rows.Next() {
+		h, err := scanOpeningHours(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		hours = append(hours, *h)
+	}
+
+	return hours, rows.Err()
+}
+
+func (s *Store) ReplaceOpeningHours(ctx context.Context, shopID int, hours []types.OpeningHoursPayload) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM shop_opening_hours WHERE shop_id = ?", shopID); err != nil {
+		return err
+	}
+
+	for _, h := range hours {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO shop_opening_hours (shop_id, weekday, opens_at, closes_at) VALUES (?, ?, ?, ?)",
+			shopID, h.Weekday, h.OpensAt, h.ClosesAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) GetShopHoursExceptions(ctx context.Context, shopID int, from time.Time) ([]types.ShopHoursException, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+shopHoursExceptionColumns+" FROM shop_hours_exceptions WHERE shop_id = ? AND date >= ? ORDER BY date, opens_at",
+		shopID, from.Format(time.DateOnly))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	exceptions := []types.ShopHoursException{}
+	for rows.Next() {
+		e, err := scanShopHoursException(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		exceptions = append(exceptions, *e)
+	}
+
+	return exceptions, rows.Err()
+}
+
+func (s *Store) CreateShopHoursException(ctx context.Context, shopID int, exception types.CreateShopHoursExceptionPayload) error {
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO shop_hours_exceptions (shop_id, date, opens_at, closes_at, note) VALUES (?, ?, ?, ?, ?)",
+		shopID, exception.Date, exception.OpensAt, exception.ClosesAt, exception.Note)
+
+	return err
+}
+
+func (s *Store) DeleteShopHoursException(ctx context.Context, shopID int, exceptionID int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM shop_hours_exceptions WHERE id = ? AND shop_id = ?", exceptionID, shopID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func scanOpeningHours(row db.Scanner) (*types.OpeningHours, error) {
+	h := new(types.OpeningHours)
+
+	err := row.Scan(
+		&h.ID,
+		&h.ShopID,
+		&h.Weekday,
+		&h.OpensAt,
+		&h.ClosesAt,
+		&h.CreatedAt,
+		&h.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	h.OpensAt = formatClock(h.OpensAt)
+	h.ClosesAt = formatClock(h.ClosesAt)
+
+	return h, nil
+}
+
+func scanShopHoursException(row db.Scanner) (*types.ShopHoursException, error) {
+	e := new(types.ShopHoursException)
+
+	var date time.Time
+	err := row.Scan(
+		&e.ID,
+		&e.ShopID,
+		&date,
+		&e.OpensAt,
+		&e.ClosesAt,
+		&e.Note,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	e.Date = date.Format(time.DateOnly)
+	if e.OpensAt != nil {
+		opensAt := formatClock(*e.OpensAt)
+		e.OpensAt = &opensAt
+	}
+	if e.ClosesAt != nil {
+		closesAt := formatClock(*e.ClosesAt)
+		e.ClosesAt = &closesAt
+	}
+
+	return e, nil
+}
+
+// formatClock trims the seconds MySQL and PostgreSQL add to TIME values.
+func formatClock(value string) string {
+	if len(value) > 5 {
+		return value[:5]
+	}
+
+	return value
+}
@@ -1,32 +1,58 @@
 package product
 
 import (
+	"context"
 	"ecom_go/services/auth"
+	"ecom_go/services/imaging"
+	"ecom_go/services/shopmember"
 	"ecom_go/types"
 	"ecom_go/utils"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
 )
 
 type Handler struct {
-	store types.ProductStore
-	categoryStore types.ProductCategoryStore
-	userStore types.UserStore
+	store          types.ProductStore
+	categoryStore  types.ProductCategoryStore
+	userStore      types.UserStore
+	shopStore      types.ShopStore
+	memberStore    types.ShopMemberStore
+	imageStore     types.ImageStore
+	images         types.ImageProcessor
+	wishlistStore  types.WishlistStore
+	inventoryStore types.InventoryStore
+	alerter        types.StockAlerter
 }
 
-func NewHandler(store types.ProductStore, categoryStore types.ProductCategoryStore, userStore types.UserStore) *Handler {
+func NewHandler(store types.ProductStore, categoryStore types.ProductCategoryStore, userStore types.UserStore, shopStore types.ShopStore, memberStore types.ShopMemberStore, imageStore types.ImageStore, images types.ImageProcessor, wishlistStore types.WishlistStore, inventoryStore types.InventoryStore, alerter types.StockAlerter) *Handler {
 	return &Handler{
-		store: store,
-		categoryStore: categoryStore,
-		userStore: userStore,
+		store:          store,
+		categoryStore:  categoryStore,
+		userStore:      userStore,
+		shopStore:      shopStore,
+		memberStore:    memberStore,
+		imageStore:     imageStore,
+		images:         images,
+		wishlistStore:  wishlistStore,
+		inventoryStore: inventoryStore,
+		alerter:        alerter,
 	}
 }
 
 func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("", auth.WithJWTAuth(h.handleGetProducts, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("", auth.WithJWTAuth(h.handleCreateProduct, h.userStore)).Methods(http.MethodPost)
 	router.HandleFunc("/category", auth.WithAdminJWTAuth(h.handleCreateProductCategory, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/category/{category_id}", auth.WithAdminJWTAuth(h.handleDeleteProductCategory, h.userStore)).Methods(http.MethodDelete)
+	router.HandleFunc("/category/{category_id}/restore", auth.WithAdminJWTAuth(h.handleRestoreProductCategory, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/{product_id}", auth.WithJWTAuth(h.handleGetProduct, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/{product_id}", auth.WithJWTAuth(h.handleUpdateProduct, h.userStore)).Methods(http.MethodPut)
+	router.HandleFunc("/{product_id}", auth.WithJWTAuth(h.handleDeleteProduct, h.userStore)).Methods(http.MethodDelete)
+	router.HandleFunc("/{product_id}/restore", auth.WithAdminJWTAuth(h.handleRestoreProduct, h.userStore)).Methods(http.MethodPost)
 }
 
 func (h *Handler) handleCreateProductCategory(w http.ResponseWriter, r *http.Request) {
@@ -43,10 +69,448 @@ func (h *Handler) handleCreateProductCategory(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if err := h.categoryStore.CreateShopCategory(productCategory); err != nil {
+	if err := h.categoryStore.CreateShopCategory(r.Context(), productCategory); err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
-	
+
 	utils.WriteJSON(w, http.StatusOK, productCategory)
-}
\ No newline at end of file
+}
+
+func (h *Handler) handleDeleteProductCategory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	str, ok := vars["category_id"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing category ID"))
+		return
+	}
+
+	categoryID, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid category ID"))
+		return
+	}
+
+	rowsAffected, err := h.categoryStore.DeleteProductCategory(r.Context(), categoryID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to delete product category: %w", err))
+		return
+	}
+
+	if rowsAffected == 0 {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product category not found"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleRestoreProductCategory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	str, ok := vars["category_id"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing product category ID"))
+		return
+	}
+
+	categoryID, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid product category ID"))
+		return
+	}
+
+	rowsAffected, err := h.categoryStore.RestoreProductCategory(r.Context(), categoryID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to restore product category: %w", err))
+		return
+	}
+
+	if rowsAffected == 0 {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("deleted product category not found"))
+		return
+	}
+
+	restored, _ := h.categoryStore.GetProductCategoryByID(r.Context(), categoryID)
+	utils.WriteJSON(w, http.StatusOK, restored)
+}
+
+func (h *Handler) handleGetProducts(w http.ResponseWriter, r *http.Request) {
+	shopID, err := strconv.Atoi(r.URL.Query().Get("shop_id"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing or invalid shop ID"))
+		return
+	}
+
+	shop, err := h.shopStore.GetShopByID(r.Context(), shopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("shop not found"))
+		return
+	}
+
+	visible, err := shopmember.CanViewShop(r.Context(), h.memberStore, h.userStore, shop, auth.GetUserIDFromContext(r.Context()))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !visible {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("shop not found"))
+		return
+	}
+
+	products, err := h.store.GetProductsByShopID(r.Context(), shopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	wishlisted := make([]*types.Product, len(products))
+	for i := range products {
+		h.attachImages(r.Context(), &products[i])
+		wishlisted[i] = &products[i]
+	}
+	h.attachWishlisted(r.Context(), auth.GetUserIDFromContext(r.Context()), wishlisted...)
+
+	utils.WriteJSON(w, http.StatusOK, products)
+}
+
+func (h *Handler) handleGetProduct(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	str, ok := vars["product_id"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing product ID"))
+		return
+	}
+
+	productID, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid product ID"))
+		return
+	}
+
+	product, err := h.store.GetProductByID(r.Context(), productID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	shop, err := h.shopStore.GetShopByID(r.Context(), product.ShopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product not found"))
+		return
+	}
+
+	visible, err := shopmember.CanViewShop(r.Context(), h.memberStore, h.userStore, shop, auth.GetUserIDFromContext(r.Context()))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !visible {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product not found"))
+		return
+	}
+
+	h.attachImages(r.Context(), product)
+	h.attachWishlisted(r.Context(), auth.GetUserIDFromContext(r.Context()), product)
+
+	utils.WriteJSON(w, http.StatusOK, product)
+}
+
+func (h *Handler) handleCreateProduct(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserIDFromContext(r.Context())
+	if userID == -1 {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("unauthorized"))
+		return
+	}
+
+	var product types.CreateProductPayload
+	if err := utils.ParseJSON(r, &product); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(product); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	if _, err := h.shopStore.GetShopByID(r.Context(), product.ShopID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	allowed, err := shopmember.HasRole(r.Context(), h.memberStore, product.ShopID, userID, types.ShopRoleManager)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !allowed {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you do not have permission to manage products of this shop"))
+		return
+	}
+
+	if _, err := h.categoryStore.GetProductCategoryByID(r.Context(), product.CategoryID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("invalid payload: %w", err))
+		return
+	}
+
+	if product.TaxClass == "" {
+		product.TaxClass = types.TaxClassStandard
+	}
+
+	if !h.checkSKU(r.Context(), w, product.ShopID, 0, product.SKU) {
+		return
+	}
+
+	product.UserID = userID
+
+	productID, err := h.store.CreateProduct(r.Context(), product)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.images.Enqueue(product.Image)
+
+	createdProduct, _ := h.store.GetProductByID(r.Context(), productID)
+	utils.WriteJSON(w, http.StatusCreated, createdProduct)
+}
+
+func (h *Handler) handleUpdateProduct(w http.ResponseWriter, r *http.Request) {
+	var product types.UpdateProductPayload
+
+	existingProduct, ok := h.getManagedProduct(w, r)
+	if !ok {
+		return
+	}
+
+	if err := utils.ParseJSON(r, &product); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(product); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	if product.CategoryID != nil {
+		if _, err := h.categoryStore.GetProductCategoryByID(r.Context(), *product.CategoryID); err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("invalid payload: %w", err))
+			return
+		}
+	}
+
+	if product.SKU == nil {
+		product.SKU = existingProduct.SKU
+	}
+	if !h.checkSKU(r.Context(), w, existingProduct.ShopID, existingProduct.ID, product.SKU) {
+		return
+	}
+	if product.Title == nil {
+		product.Title = &existingProduct.Title
+	}
+	if product.Description == nil {
+		product.Description = &existingProduct.Description
+	}
+	if product.CategoryID == nil {
+		product.CategoryID = &existingProduct.CategoryID
+	}
+	if product.Quantity == nil {
+		product.Quantity = &existingProduct.Quantity
+	}
+	if product.PriceCents == nil {
+		product.PriceCents = &existingProduct.PriceCents
+	}
+	if product.TaxClass == nil {
+		product.TaxClass = &existingProduct.TaxClass
+	}
+	if product.WeightGrams == nil {
+		product.WeightGrams = &existingProduct.WeightGrams
+	}
+	if product.LengthMm == nil {
+		product.LengthMm = &existingProduct.LengthMm
+	}
+	if product.WidthMm == nil {
+		product.WidthMm = &existingProduct.WidthMm
+	}
+	if product.HeightMm == nil {
+		product.HeightMm = &existingProduct.HeightMm
+	}
+	if product.Image == nil {
+		product.Image = &existingProduct.Image
+	}
+
+	if err := h.store.UpdateProduct(r.Context(), existingProduct.ID, product); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// A quantity set on the product is booked as an adjustment in the stock
+	// ledger.
+	if *product.Quantity != existingProduct.Quantity {
+		change, err := h.inventoryStore.SetStockQuantity(r.Context(), existingProduct.ID, *product.Quantity, auth.GetUserIDFromContext(r.Context()))
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		h.alerter.CheckStock(r.Context(), *change)
+	}
+
+	if *product.Image != existingProduct.Image {
+		h.images.Enqueue(*product.Image)
+	}
+
+	updatedProduct, _ := h.store.GetProductByID(r.Context(), existingProduct.ID)
+	h.attachImages(r.Context(), updatedProduct)
+	h.attachWishlisted(r.Context(), auth.GetUserIDFromContext(r.Context()), updatedProduct)
+	utils.WriteJSON(w, http.StatusOK, updatedProduct)
+}
+
+func (h *Handler) handleDeleteProduct(w http.ResponseWriter, r *http.Request) {
+	existingProduct, ok := h.getManagedProduct(w, r)
+	if !ok {
+		return
+	}
+
+	rowsAffected, err := h.store.DeleteProduct(r.Context(), existingProduct.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to delete product: %w", err))
+		return
+	}
+
+	if rowsAffected == 0 {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product not found"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleRestoreProduct(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	str, ok := vars["product_id"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing product ID"))
+		return
+	}
+
+	productID, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid product ID"))
+		return
+	}
+
+	rowsAffected, err := h.store.RestoreProduct(r.Context(), productID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to restore product: %w", err))
+		return
+	}
+
+	if rowsAffected == 0 {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("deleted product not found"))
+		return
+	}
+
+	restored, _ := h.store.GetProductByID(r.Context(), productID)
+	utils.WriteJSON(w, http.StatusOK, restored)
+}
+
+// checkSKU makes sure no other product of the shop uses the SKU, writing a
+// conflict response otherwise.
+func (h *Handler) checkSKU(ctx context.Context, w http.ResponseWriter, shopID int, productID int, sku *string) bool {
+	if sku == nil || *sku == "" {
+		return true
+	}
+
+	if other, err := h.store.GetProductBySKU(ctx, shopID, *sku); err == nil && other.ID != productID {
+		utils.WriteError(w, http.StatusConflict, fmt.Errorf("product with SKU %s already exists", *sku))
+		return false
+	}
+
+	return true
+}
+
+// getManagedProduct loads the product from the request path and makes sure
+// the authenticated user is an owner or manager of its shop, writing an error
+// response otherwise.
+func (h *Handler) getManagedProduct(w http.ResponseWriter, r *http.Request) (*types.Product, bool) {
+	userID := auth.GetUserIDFromContext(r.Context())
+	if userID == -1 {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("unauthorized"))
+		return nil, false
+	}
+
+	vars := mux.Vars(r)
+	str, ok := vars["product_id"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing product ID"))
+		return nil, false
+	}
+
+	productID, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid product ID"))
+		return nil, false
+	}
+
+	product, err := h.store.GetProductByID(r.Context(), productID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	allowed, err := shopmember.HasRole(r.Context(), h.memberStore, product.ShopID, userID, types.ShopRoleManager)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	if !allowed {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you do not have permission to manage products of this shop"))
+		return nil, false
+	}
+
+	return product, true
+}
+
+func (h *Handler) attachImages(ctx context.Context, product *types.Product) {
+	if product == nil || product.Image == "" {
+		return
+	}
+
+	variants, err := h.imageStore.GetImageVariants(ctx, product.Image)
+	if err != nil {
+		return
+	}
+
+	product.Images = imaging.NewImageSet(variants)
+}
+
+// attachWishlisted sets whether each of the products is in one of the
+// wishlists of the user.
+func (h *Handler) attachWishlisted(ctx context.Context, userID int, products ...*types.Product) {
+	productIDs := []int{}
+	for _, product := range products {
+		if product != nil {
+			productIDs = append(productIDs, product.ID)
+		}
+	}
+
+	wishlisted, err := h.wishlistStore.GetWishlistedProductSet(ctx, userID, productIDs)
+	if err != nil {
+		return
+	}
+
+	for _, product := range products {
+		if product != nil {
+			inWishlist := wishlisted[product.ID]
+			product.InWishlist = &inWishlist
+		}
+	}
+}
@@ -1,11 +1,211 @@
 package product
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+	"ecom_go/db"
+	"ecom_go/types"
+	"fmt"
+	"time"
+)
 
 type Store struct {
-	db *sql.DB
+	db *db.DB
 }
 
-func NewStore(db *sql.DB) *Store {
+func NewStore(db *db.DB) *Store {
 	return &Store{db: db}
-}
\ No newline at end of file
+}
+
+const productColumns = `id, shop_id, sku, title, description, category_id, quantity, low_stock_threshold, price_cents, tax_class,
+	weight_grams, length_mm, width_mm, height_mm, image, rating_average, rating_count, created_at, updated_at, deleted_at`
+
+func (s *Store) GetProductByID(ctx context.Context, productID int) (*types.Product, error) {
+	product, err := scanProduct(s.db.QueryRowContext(ctx, "SELECT "+productColumns+" FROM products WHERE id = ? AND deleted_at IS NULL", productID))
+	if err != nil {
+		return nil, types.NotFound("product", err)
+	}
+
+	return product, nil
+}
+
+func (s *Store) GetProductsByShopID(ctx context.Context, shopID int) ([]types.Product, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+productColumns+" FROM products WHERE shop_id = ? AND deleted_at IS NULL ORDER BY id", shopID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []types.Product{}
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		products = append(products, *product)
+	}
+
+	return products, rows.Err()
+}
+
+// GetProductBySKU finds the product of the shop with the given SKU, ignoring
+// deleted products.
+func (s *Store) GetProductBySKU(ctx context.Context, shopID int, sku string) (*types.Product, error) {
+	product, err := scanProduct(s.db.QueryRowContext(ctx,
+		"SELECT "+productColumns+" FROM products WHERE shop_id = ? AND sku = ? AND deleted_at IS NULL", shopID, sku))
+	if err != nil {
+		return nil, types.NotFound("product", err)
+	}
+
+	return product, nil
+}
+
+// StreamProductsByShopID calls fn for each product of the shop without
+// loading them all into memory, stopping at the first error.
+func (s *Store) StreamProductsByShopID(ctx context.Context, shopID int, fn func(*types.Product) error) error {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+productColumns+" FROM products WHERE shop_id = ? AND deleted_at IS NULL ORDER BY id", shopID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(product); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// CreateProduct adds the product and records its initial quantity as a
+// receipt at the default stock location of the shop.
+func (s *Store) CreateProduct(ctx context.Context, product types.CreateProductPayload) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	id, err := s.db.InsertTx(ctx, tx,
+		`INSERT INTO products (shop_id, sku, title, description, category_id, quantity, price_cents, tax_class,
+			weight_grams, length_mm, width_mm, height_mm, image)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		product.ShopID, nullableSKU(product.SKU), product.Title, product.Description, product.CategoryID, product.Quantity, product.PriceCents, product.TaxClass,
+		product.WeightGrams, product.LengthMm, product.WidthMm, product.HeightMm, product.Image)
+	if err != nil {
+		return 0, err
+	}
+
+	if product.Quantity != 0 {
+		var locationID int
+		if err := tx.QueryRowContext(ctx, "SELECT id FROM stock_locations WHERE shop_id = ? AND is_default = TRUE", product.ShopID).Scan(&locationID); err != nil {
+			if err == sql.ErrNoRows {
+				return 0, fmt.Errorf("shop has no default stock location")
+			}
+			return 0, err
+		}
+
+		if _, err := tx.ExecContext(ctx, "INSERT INTO location_stock (location_id, product_id, quantity) VALUES (?, ?, ?)",
+			locationID, id, product.Quantity); err != nil {
+			return 0, err
+		}
+
+		if _, err := tx.ExecContext(ctx, "INSERT INTO stock_movements (product_id, type, quantity, location_id, reason, actor_id) VALUES (?, ?, ?, ?, ?, ?)",
+			id, types.StockMovementReceipt, product.Quantity, locationID, "initial stock", product.UserID); err != nil {
+			return 0, err
+		}
+	}
+
+	return int(id), tx.Commit()
+}
+
+// UpdateProduct updates the product. The quantity is managed through the
+// stock ledger and is left alone.
+func (s *Store) UpdateProduct(ctx context.Context, productID int, product types.UpdateProductPayload) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE products SET sku = ?, title = ?, description = ?, category_id = ?, price_cents = ?, tax_class = ?,
+			weight_grams = ?, length_mm = ?, width_mm = ?, height_mm = ?, image = ?
+		WHERE id = ? AND deleted_at IS NULL`,
+		nullableSKU(product.SKU), product.Title, product.Description, product.CategoryID, product.PriceCents, product.TaxClass,
+		product.WeightGrams, product.LengthMm, product.WidthMm, product.HeightMm, product.Image, productID)
+
+	return err
+}
+
+func (s *Store) DeleteProduct(ctx context.Context, productID int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "UPDATE products SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL", time.Now().UTC(), productID)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, nil
+	}
+
+	return rowsAffected, nil
+}
+
+func (s *Store) RestoreProduct(ctx context.Context, productID int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "UPDATE products SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL", productID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// scanProduct reads a row of productColumns. A NULL description or image is
+// read as an empty string.
+func scanProduct(row db.Scanner) (*types.Product, error) {
+	product := new(types.Product)
+
+	var description, image sql.NullString
+	err := row.Scan(
+		&product.ID,
+		&product.ShopID,
+		&product.SKU,
+		&product.Title,
+		&description,
+		&product.CategoryID,
+		&product.Quantity,
+		&product.LowStockThreshold,
+		&product.PriceCents,
+		&product.TaxClass,
+		&product.WeightGrams,
+		&product.LengthMm,
+		&product.WidthMm,
+		&product.HeightMm,
+		&image,
+		&product.RatingAverage,
+		&product.RatingCount,
+		&product.CreatedAt,
+		&product.UpdatedAt,
+		&product.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	product.Description = description.String
+	product.Image = image.String
+
+	return product, nil
+}
+
+// nullableSKU stores an empty SKU as NULL, so products without a SKU never
+// match a lookup by SKU.
+func nullableSKU(sku *string) *string {
+	if sku == nil || *sku == "" {
+		return nil
+	}
+
+	return sku
+}
@@ -1,24 +1,73 @@
 package productcategory
 
 import (
-	"database/sql"
+	"context"
+	"ecom_go/db"
 	"ecom_go/types"
+	"time"
 )
 
 type Store struct {
-	db *sql.DB
+	db *db.DB
 }
 
-func NewStore(db *sql.DB) *Store {
+func NewStore(db *db.DB) *Store {
 	return &Store{db: db}
 }
 
-func (s *Store) CreateShopCategory(productCategory types.CreateUpdateProductCategoryPayload) error {
-	_, err := s.db.Exec(
+const productCategoryColumns = "id, name, created_at, updated_at, deleted_at"
+
+func (s *Store) GetProductCategoryByID(ctx context.Context, categoryID int) (*types.ProductCategory, error) {
+	productCategory, err := scanProductCategory(s.db.QueryRowContext(ctx,
+		"SELECT "+productCategoryColumns+" FROM productcategories WHERE id = ? AND deleted_at IS NULL", categoryID))
+	if err != nil {
+		return nil, types.NotFound("product category", err)
+	}
+
+	return productCategory, nil
+}
+
+func (s *Store) CreateShopCategory(ctx context.Context, productCategory types.CreateUpdateProductCategoryPayload) error {
+	_, err := s.db.ExecContext(ctx,
 		"INSERT INTO productcategories (name) VALUES (?)", productCategory.Name)
 	if err != nil {
 		return err
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+func (s *Store) DeleteProductCategory(ctx context.Context, categoryID int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "UPDATE productcategories SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL", time.Now().UTC(), categoryID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (s *Store) RestoreProductCategory(ctx context.Context, categoryID int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "UPDATE productcategories SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL", categoryID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func scanProductCategory(row db.Scanner) (*types.ProductCategory, error) {
+	productCategory := new(types.ProductCategory)
+
+	err := row.Scan(
+		&productCategory.ID,
+		&productCategory.Name,
+		&productCategory.CreatedAt,
+		&productCategory.UpdatedAt,
+		&productCategory.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return productCategory, nil
+}
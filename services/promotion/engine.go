@@ -0,0 +1,299 @@
+package promotion
+
+import (
+	"ecom_go/types"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Evaluate works out the discounts the promotions grant on the cart. It does
+// not touch the database, so callers pass in every promotion that might apply
+// with its usage counts filled in.
+//
+// Stackable promotions are applied together in order of priority, each one
+// on the amounts left by the ones before it. A promotion that is not
+// stackable is only ever applied on its own. Whichever of these options saves
+// the customer the most is returned.
+func Evaluate(cart types.Cart, promotions []types.Promotion) []types.Discount {
+	candidates := []types.Promotion{}
+	for _, p := range promotions {
+		if applies(cart, p) {
+			candidates = append(candidates, p)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Priority != candidates[j].Priority {
+			return candidates[i].Priority > candidates[j].Priority
+		}
+		return candidates[i].ID < candidates[j].ID
+	})
+
+	stackable := []types.Promotion{}
+	exclusive := []types.Promotion{}
+	for _, p := range candidates {
+		if p.Stackable {
+			stackable = append(stackable, p)
+		} else {
+			exclusive = append(exclusive, p)
+		}
+	}
+
+	best := apply(cart, stackable)
+	for _, p := range exclusive {
+		if discounts := apply(cart, []types.Promotion{p}); savings(discounts) > savings(best) {
+			best = discounts
+		}
+	}
+
+	return best
+}
+
+// Summarize adds up the cart and the discounts granted on it.
+func Summarize(cart types.Cart, discounts []types.Discount) types.CartEvaluation {
+	evaluation := types.CartEvaluation{
+		ShippingCents: cart.ShippingCents,
+		Discounts:     discounts,
+	}
+
+	for _, line := range cart.Lines {
+		evaluation.SubtotalCents += lineTotal(line)
+	}
+
+	for _, discount := range discounts {
+		evaluation.DiscountCents += discount.AmountCents
+		evaluation.ShippingDiscountCents += discount.ShippingCents
+	}
+
+	evaluation.TotalCents = evaluation.SubtotalCents - evaluation.DiscountCents +
+		evaluation.ShippingCents - evaluation.ShippingDiscountCents
+
+	return evaluation
+}
+
+// ValidatePromotion checks the rules of a promotion that struct tags cannot
+// express.
+func ValidatePromotion(p types.CreateUpdatePromotionPayload) error {
+	switch p.Type {
+	case types.PromotionTypePercentage, types.PromotionTypeBuyXGetY:
+		if p.Value < 1 || p.Value > 100 {
+			return fmt.Errorf("value must be a percentage between 1 and 100")
+		}
+	case types.PromotionTypeFixedAmount:
+		if p.Value < 1 {
+			return fmt.Errorf("value must be an amount in cents")
+		}
+	}
+
+	if p.Type == types.PromotionTypeBuyXGetY && (p.BuyQuantity < 1 || p.GetQuantity < 1) {
+		return fmt.Errorf("buy_quantity and get_quantity are required for buy_x_get_y promotions")
+	}
+
+	if p.Scope == types.PromotionScopeAll && len(p.TargetIDs) > 0 {
+		return fmt.Errorf("target_ids must be empty when scope is all")
+	}
+
+	if p.Scope != types.PromotionScopeAll && len(p.TargetIDs) == 0 {
+		return fmt.Errorf("target_ids are required when scope is %s", p.Scope)
+	}
+
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+
+	return nil
+}
+
+func applies(cart types.Cart, p types.Promotion) bool {
+	if !p.Active {
+		return false
+	}
+
+	if p.StartsAt != nil && cart.At.Before(*p.StartsAt) {
+		return false
+	}
+
+	if p.EndsAt != nil && !cart.At.Before(*p.EndsAt) {
+		return false
+	}
+
+	if p.Code != nil && !slices.ContainsFunc(cart.CouponCodes, func(code string) bool {
+		return strings.EqualFold(strings.TrimSpace(code), *p.Code)
+	}) {
+		return false
+	}
+
+	if p.UsageLimit != nil && p.UsageCount >= *p.UsageLimit {
+		return false
+	}
+
+	if p.UsageLimitPerUser != nil && p.UserUsageCount >= *p.UsageLimitPerUser {
+		return false
+	}
+
+	var subtotal int64
+	for _, line := range cart.Lines {
+		if inScope(p, line) {
+			subtotal += lineTotal(line)
+		}
+	}
+
+	return subtotal > 0 && subtotal >= p.MinSubtotalCents
+}
+
+func inScope(p types.Promotion, line types.CartLine) bool {
+	if p.ShopID != nil && line.ShopID != *p.ShopID {
+		return false
+	}
+
+	switch p.Scope {
+	case types.PromotionScopeShop:
+		return slices.Contains(p.TargetIDs, line.ShopID)
+	case types.PromotionScopeCategory:
+		return slices.Contains(p.TargetIDs, line.CategoryID)
+	case types.PromotionScopeProduct:
+		return slices.Contains(p.TargetIDs, line.ProductID)
+	default:
+		return true
+	}
+}
+
+// apply applies the promotions one after the other, never discounting a line
+// or the shipping below zero.
+func apply(cart types.Cart, promotions []types.Promotion) []types.Discount {
+	remaining := make([]int64, len(cart.Lines))
+	for i, line := range cart.Lines {
+		remaining[i] = lineTotal(line)
+	}
+	shipping := cart.ShippingCents
+
+	discounts := []types.Discount{}
+	for _, p := range promotions {
+		discount := types.Discount{
+			PromotionID: p.ID,
+			Name:        p.Name,
+			Code:        p.Code,
+			Type:        p.Type,
+		}
+
+		if p.Type == types.PromotionTypeFreeShipping {
+			discount.ShippingCents = shipping
+			shipping = 0
+		}
+
+		for i, amount := range lineDiscounts(cart, p, remaining) {
+			amount = min(amount, remaining[i])
+			if amount <= 0 {
+				continue
+			}
+
+			remaining[i] -= amount
+			discount.AmountCents += amount
+			discount.Lines = append(discount.Lines, types.DiscountLine{
+				ProductID:   cart.Lines[i].ProductID,
+				AmountCents: amount,
+			})
+		}
+
+		if discount.AmountCents > 0 || discount.ShippingCents > 0 {
+			discounts = append(discounts, discount)
+		}
+	}
+
+	return discounts
+}
+
+// lineDiscounts returns the discount the promotion grants on each line given
+// the amounts still left on the lines.
+func lineDiscounts(cart types.Cart, p types.Promotion, remaining []int64) []int64 {
+	amounts := make([]int64, len(cart.Lines))
+
+	switch p.Type {
+	case types.PromotionTypePercentage:
+		for i, line := range cart.Lines {
+			if inScope(p, line) {
+				amounts[i] = percentOf(remaining[i], p.Value)
+			}
+		}
+
+	case types.PromotionTypeFixedAmount:
+		var eligible int64
+		last := -1
+		for i, line := range cart.Lines {
+			if inScope(p, line) && remaining[i] > 0 {
+				eligible += remaining[i]
+				last = i
+			}
+		}
+		if last == -1 {
+			break
+		}
+
+		// Spread the amount over the lines in proportion to their value and
+		// put the rounding difference on the last one.
+		amount := min(p.Value, eligible)
+		var spread int64
+		for i, line := range cart.Lines {
+			if !inScope(p, line) || remaining[i] <= 0 {
+				continue
+			}
+
+			if i == last {
+				amounts[i] = amount - spread
+			} else {
+				amounts[i] = amount * remaining[i] / eligible
+				spread += amounts[i]
+			}
+		}
+
+	case types.PromotionTypeBuyXGetY:
+		type unit struct {
+			line  int
+			price int64
+		}
+
+		units := []unit{}
+		for i, line := range cart.Lines {
+			if inScope(p, line) {
+				for range line.Quantity {
+					units = append(units, unit{line: i, price: line.UnitPriceCents})
+				}
+			}
+		}
+
+		// Of every buy+get units in the cart the cheapest get units are
+		// discounted.
+		group := p.BuyQuantity + p.GetQuantity
+		if group <= 0 {
+			break
+		}
+
+		sort.SliceStable(units, func(i, j int) bool { return units[i].price < units[j].price })
+		free := len(units) / group * p.GetQuantity
+		for _, u := range units[:free] {
+			amounts[u.line] += percentOf(u.price, p.Value)
+		}
+	}
+
+	return amounts
+}
+
+func savings(discounts []types.Discount) int64 {
+	var total int64
+	for _, discount := range discounts {
+		total += discount.AmountCents + discount.ShippingCents
+	}
+
+	return total
+}
+
+func lineTotal(line types.CartLine) int64 {
+	return line.UnitPriceCents * int64(line.Quantity)
+}
+
+// percentOf returns percent of amount rounded half up to the nearest cent.
+func percentOf(amount int64, percent int64) int64 {
+	return (amount*percent + 50) / 100
+}
@@ -0,0 +1,385 @@
+package promotion
+
+import (
+	"ecom_go/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2025, 4, 3, 12, 0, 0, 0, time.UTC)
+
+type wantDiscount struct {
+	promotionID   int
+	amountCents   int64
+	shippingCents int64
+	lines         []types.DiscountLine
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name       string
+		cart       types.Cart
+		promotions []types.Promotion
+		want       []wantDiscount
+	}{
+		{
+			name:       "percentage rounds each line half up",
+			cart:       cart(0, line(1, 2, 1000), line(2, 1, 555)),
+			promotions: []types.Promotion{promotion(1, types.PromotionTypePercentage, 10)},
+			want: []wantDiscount{
+				{promotionID: 1, amountCents: 256, lines: []types.DiscountLine{{ProductID: 1, AmountCents: 200}, {ProductID: 2, AmountCents: 56}}},
+			},
+		},
+		{
+			name: "stackable promotions apply by priority on what is left",
+			cart: cart(0, line(1, 1, 2000)),
+			promotions: []types.Promotion{
+				promotion(1, types.PromotionTypePercentage, 10, withPriority(1)),
+				promotion(2, types.PromotionTypeFixedAmount, 500, withPriority(5)),
+			},
+			want: []wantDiscount{
+				{promotionID: 2, amountCents: 500, lines: []types.DiscountLine{{ProductID: 1, AmountCents: 500}}},
+				{promotionID: 1, amountCents: 150, lines: []types.DiscountLine{{ProductID: 1, AmountCents: 150}}},
+			},
+		},
+		{
+			name: "equal priorities apply by id",
+			cart: cart(0, line(1, 1, 2000)),
+			promotions: []types.Promotion{
+				promotion(2, types.PromotionTypePercentage, 10),
+				promotion(1, types.PromotionTypeFixedAmount, 500),
+			},
+			want: []wantDiscount{
+				{promotionID: 1, amountCents: 500, lines: []types.DiscountLine{{ProductID: 1, AmountCents: 500}}},
+				{promotionID: 2, amountCents: 150, lines: []types.DiscountLine{{ProductID: 1, AmountCents: 150}}},
+			},
+		},
+		{
+			name: "exclusive promotion wins when it saves more than the stack",
+			cart: cart(0, line(1, 1, 2000)),
+			promotions: []types.Promotion{
+				promotion(1, types.PromotionTypePercentage, 10),
+				promotion(2, types.PromotionTypeFixedAmount, 100),
+				promotion(3, types.PromotionTypePercentage, 25, exclusive),
+			},
+			want: []wantDiscount{
+				{promotionID: 3, amountCents: 500, lines: []types.DiscountLine{{ProductID: 1, AmountCents: 500}}},
+			},
+		},
+		{
+			name: "stack wins when it saves more than any exclusive promotion",
+			cart: cart(0, line(1, 1, 2000)),
+			promotions: []types.Promotion{
+				promotion(1, types.PromotionTypePercentage, 10),
+				promotion(2, types.PromotionTypeFixedAmount, 300, withPriority(1)),
+				promotion(3, types.PromotionTypePercentage, 5, exclusive, withPriority(10)),
+				promotion(4, types.PromotionTypeFixedAmount, 400, exclusive),
+			},
+			want: []wantDiscount{
+				{promotionID: 2, amountCents: 300, lines: []types.DiscountLine{{ProductID: 1, AmountCents: 300}}},
+				{promotionID: 1, amountCents: 170, lines: []types.DiscountLine{{ProductID: 1, AmountCents: 170}}},
+			},
+		},
+		{
+			name: "stack is kept when an exclusive promotion saves the same",
+			cart: cart(0, line(1, 1, 2000)),
+			promotions: []types.Promotion{
+				promotion(1, types.PromotionTypePercentage, 10),
+				promotion(2, types.PromotionTypeFixedAmount, 200, exclusive),
+			},
+			want: []wantDiscount{
+				{promotionID: 1, amountCents: 200, lines: []types.DiscountLine{{ProductID: 1, AmountCents: 200}}},
+			},
+		},
+		{
+			name: "best of several exclusive promotions",
+			cart: cart(0, line(1, 1, 2000)),
+			promotions: []types.Promotion{
+				promotion(1, types.PromotionTypePercentage, 20, exclusive),
+				promotion(2, types.PromotionTypeFixedAmount, 450, exclusive),
+				promotion(3, types.PromotionTypePercentage, 15, exclusive, withPriority(3)),
+			},
+			want: []wantDiscount{
+				{promotionID: 2, amountCents: 450, lines: []types.DiscountLine{{ProductID: 1, AmountCents: 450}}},
+			},
+		},
+		{
+			name: "fixed amount is spread over scoped lines with the rounding on the last",
+			cart: cart(0, line(1, 1, 1000), line(2, 1, 1000), line(4, 1, 1000), line(3, 1, 1000)),
+			promotions: []types.Promotion{
+				promotion(1, types.PromotionTypeFixedAmount, 100, withTargets(types.PromotionScopeProduct, 1, 2, 3)),
+			},
+			want: []wantDiscount{
+				{promotionID: 1, amountCents: 100, lines: []types.DiscountLine{
+					{ProductID: 1, AmountCents: 33},
+					{ProductID: 2, AmountCents: 33},
+					{ProductID: 3, AmountCents: 34},
+				}},
+			},
+		},
+		{
+			name: "fixed amount is spread in proportion to line values",
+			cart: cart(0, line(1, 1, 300), line(2, 2, 350)),
+			promotions: []types.Promotion{
+				promotion(1, types.PromotionTypeFixedAmount, 250),
+			},
+			want: []wantDiscount{
+				{promotionID: 1, amountCents: 250, lines: []types.DiscountLine{
+					{ProductID: 1, AmountCents: 75},
+					{ProductID: 2, AmountCents: 175},
+				}},
+			},
+		},
+		{
+			name: "fixed amount is capped at the scoped subtotal",
+			cart: cart(0, line(1, 1, 500), line(2, 1, 5000)),
+			promotions: []types.Promotion{
+				promotion(1, types.PromotionTypeFixedAmount, 2000, withTargets(types.PromotionScopeProduct, 1)),
+			},
+			want: []wantDiscount{
+				{promotionID: 1, amountCents: 500, lines: []types.DiscountLine{{ProductID: 1, AmountCents: 500}}},
+			},
+		},
+		{
+			name: "fixed amount skips lines an earlier promotion used up",
+			cart: cart(0, line(1, 1, 500), line(2, 1, 500)),
+			promotions: []types.Promotion{
+				promotion(1, types.PromotionTypePercentage, 100, withPriority(1), withTargets(types.PromotionScopeProduct, 1)),
+				promotion(2, types.PromotionTypeFixedAmount, 300),
+			},
+			want: []wantDiscount{
+				{promotionID: 1, amountCents: 500, lines: []types.DiscountLine{{ProductID: 1, AmountCents: 500}}},
+				{promotionID: 2, amountCents: 300, lines: []types.DiscountLine{{ProductID: 2, AmountCents: 300}}},
+			},
+		},
+		{
+			name: "buy x get y discounts the cheapest units of each group",
+			cart: cart(0, line(1, 4, 1000), line(2, 2, 300)),
+			promotions: []types.Promotion{
+				promotion(1, types.PromotionTypeBuyXGetY, 100, withBuyGet(2, 1)),
+			},
+			want: []wantDiscount{
+				{promotionID: 1, amountCents: 600, lines: []types.DiscountLine{{ProductID: 2, AmountCents: 600}}},
+			},
+		},
+		{
+			name: "buy x get y ignores incomplete groups",
+			cart: cart(0, line(1, 5, 1000)),
+			promotions: []types.Promotion{
+				promotion(1, types.PromotionTypeBuyXGetY, 100, withBuyGet(2, 1)),
+			},
+			want: []wantDiscount{
+				{promotionID: 1, amountCents: 1000, lines: []types.DiscountLine{{ProductID: 1, AmountCents: 1000}}},
+			},
+		},
+		{
+			name: "buy x get y at a percentage rounds each unit",
+			cart: cart(0, line(1, 3, 999)),
+			promotions: []types.Promotion{
+				promotion(1, types.PromotionTypeBuyXGetY, 50, withBuyGet(1, 1)),
+			},
+			want: []wantDiscount{
+				{promotionID: 1, amountCents: 500, lines: []types.DiscountLine{{ProductID: 1, AmountCents: 500}}},
+			},
+		},
+		{
+			name: "buy x get y only groups units in scope",
+			cart: cart(0, line(1, 2, 100), line(2, 1, 2000), line(3, 1, 1500)),
+			promotions: []types.Promotion{
+				promotion(1, types.PromotionTypeBuyXGetY, 100, withBuyGet(1, 1), withTargets(types.PromotionScopeProduct, 2, 3)),
+			},
+			want: []wantDiscount{
+				{promotionID: 1, amountCents: 1500, lines: []types.DiscountLine{{ProductID: 3, AmountCents: 1500}}},
+			},
+		},
+		{
+			name:       "free shipping covers the shipping cost",
+			cart:       cart(499, line(1, 1, 1000)),
+			promotions: []types.Promotion{promotion(1, types.PromotionTypeFreeShipping, 0)},
+			want: []wantDiscount{
+				{promotionID: 1, shippingCents: 499},
+			},
+		},
+		{
+			name: "free shipping is granted only once in a stack",
+			cart: cart(499, line(1, 1, 1000)),
+			promotions: []types.Promotion{
+				promotion(1, types.PromotionTypeFreeShipping, 0),
+				promotion(2, types.PromotionTypeFreeShipping, 0, withPriority(1)),
+				promotion(3, types.PromotionTypePercentage, 10),
+			},
+			want: []wantDiscount{
+				{promotionID: 2, shippingCents: 499},
+				{promotionID: 3, amountCents: 100, lines: []types.DiscountLine{{ProductID: 1, AmountCents: 100}}},
+			},
+		},
+		{
+			name:       "free shipping without shipping cost grants nothing",
+			cart:       cart(0, line(1, 1, 1000)),
+			promotions: []types.Promotion{promotion(1, types.PromotionTypeFreeShipping, 0)},
+			want:       []wantDiscount{},
+		},
+		{
+			name: "exclusive free shipping wins over a smaller stack",
+			cart: cart(800, line(1, 1, 1000)),
+			promotions: []types.Promotion{
+				promotion(1, types.PromotionTypePercentage, 5),
+				promotion(2, types.PromotionTypeFreeShipping, 0, exclusive),
+			},
+			want: []wantDiscount{
+				{promotionID: 2, shippingCents: 800},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := summarizeDiscounts(Evaluate(tt.cart, tt.promotions))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateSkipsPromotionsThatDoNotApply(t *testing.T) {
+	other := 2
+
+	tests := []struct {
+		name      string
+		promotion types.Promotion
+		coupons   []string
+		applies   bool
+	}{
+		{name: "active", promotion: promotion(1, types.PromotionTypePercentage, 10), applies: true},
+		{name: "inactive", promotion: promotion(1, types.PromotionTypePercentage, 10, func(p *types.Promotion) { p.Active = false })},
+		{name: "not started", promotion: promotion(1, types.PromotionTypePercentage, 10, func(p *types.Promotion) { p.StartsAt = ptr(now.Add(time.Hour)) })},
+		{name: "ended", promotion: promotion(1, types.PromotionTypePercentage, 10, func(p *types.Promotion) { p.EndsAt = ptr(now) })},
+		{name: "within window", promotion: promotion(1, types.PromotionTypePercentage, 10, func(p *types.Promotion) {
+			p.StartsAt = ptr(now)
+			p.EndsAt = ptr(now.Add(time.Hour))
+		}), applies: true},
+		{name: "coupon missing", promotion: promotion(1, types.PromotionTypePercentage, 10, withCode("SPRING"))},
+		{name: "coupon entered", promotion: promotion(1, types.PromotionTypePercentage, 10, withCode("SPRING")), coupons: []string{" spring "}, applies: true},
+		{name: "global limit reached", promotion: promotion(1, types.PromotionTypePercentage, 10, func(p *types.Promotion) {
+			p.UsageLimit = ptr(5)
+			p.UsageCount = 5
+		})},
+		{name: "global limit left", promotion: promotion(1, types.PromotionTypePercentage, 10, func(p *types.Promotion) {
+			p.UsageLimit = ptr(5)
+			p.UsageCount = 4
+		}), applies: true},
+		{name: "user limit reached", promotion: promotion(1, types.PromotionTypePercentage, 10, func(p *types.Promotion) {
+			p.UsageLimitPerUser = ptr(1)
+			p.UserUsageCount = 1
+		})},
+		{name: "minimum subtotal not met", promotion: promotion(1, types.PromotionTypePercentage, 10, func(p *types.Promotion) { p.MinSubtotalCents = 2001 })},
+		{name: "minimum subtotal met", promotion: promotion(1, types.PromotionTypePercentage, 10, func(p *types.Promotion) { p.MinSubtotalCents = 2000 }), applies: true},
+		{name: "other shop", promotion: promotion(1, types.PromotionTypePercentage, 10, func(p *types.Promotion) { p.ShopID = &other })},
+		{name: "other category", promotion: promotion(1, types.PromotionTypePercentage, 10, withTargets(types.PromotionScopeCategory, 9))},
+		{name: "category in scope", promotion: promotion(1, types.PromotionTypePercentage, 10, withTargets(types.PromotionScopeCategory, 1)), applies: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cart(0, line(1, 2, 1000))
+			c.CouponCodes = tt.coupons
+
+			got := Evaluate(c, []types.Promotion{tt.promotion})
+			if applies := len(got) > 0; applies != tt.applies {
+				t.Errorf("Evaluate() applied = %v, want %v", applies, tt.applies)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	c := cart(499, line(1, 2, 1000), line(2, 1, 555))
+	discounts := Evaluate(c, []types.Promotion{
+		promotion(1, types.PromotionTypePercentage, 10),
+		promotion(2, types.PromotionTypeFreeShipping, 0),
+	})
+
+	want := types.CartEvaluation{
+		SubtotalCents:         2555,
+		DiscountCents:         256,
+		ShippingCents:         499,
+		ShippingDiscountCents: 499,
+		TotalCents:            2299,
+		Discounts:             discounts,
+	}
+	if got := Summarize(c, discounts); !reflect.DeepEqual(got, want) {
+		t.Errorf("Summarize() = %+v, want %+v", got, want)
+	}
+}
+
+func summarizeDiscounts(discounts []types.Discount) []wantDiscount {
+	got := []wantDiscount{}
+	for _, d := range discounts {
+		got = append(got, wantDiscount{
+			promotionID:   d.PromotionID,
+			amountCents:   d.AmountCents,
+			shippingCents: d.ShippingCents,
+			lines:         d.Lines,
+		})
+	}
+
+	return got
+}
+
+func cart(shippingCents int64, lines ...types.CartLine) types.Cart {
+	return types.Cart{UserID: 1, Lines: lines, ShippingCents: shippingCents, At: now}
+}
+
+// line returns a cart line of shop 1 and category 1.
+func line(productID int, quantity int, unitPriceCents int64) types.CartLine {
+	return types.CartLine{ProductID: productID, ShopID: 1, CategoryID: 1, Quantity: quantity, UnitPriceCents: unitPriceCents}
+}
+
+// promotion returns an active, stackable promotion on the whole cart.
+func promotion(id int, promotionType string, value int64, options ...func(*types.Promotion)) types.Promotion {
+	p := types.Promotion{
+		ID:        id,
+		Type:      promotionType,
+		Value:     value,
+		Scope:     types.PromotionScopeAll,
+		Stackable: true,
+		Active:    true,
+	}
+	for _, option := range options {
+		option(&p)
+	}
+
+	return p
+}
+
+func exclusive(p *types.Promotion) {
+	p.Stackable = false
+}
+
+func withPriority(priority int) func(*types.Promotion) {
+	return func(p *types.Promotion) { p.Priority = priority }
+}
+
+func withTargets(scope string, ids ...int) func(*types.Promotion) {
+	return func(p *types.Promotion) {
+		p.Scope = scope
+		p.TargetIDs = ids
+	}
+}
+
+func withBuyGet(buy int, get int) func(*types.Promotion) {
+	return func(p *types.Promotion) {
+		p.BuyQuantity = buy
+		p.GetQuantity = get
+	}
+}
+
+func withCode(code string) func(*types.Promotion) {
+	return func(p *types.Promotion) { p.Code = &code }
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
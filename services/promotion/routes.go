@@ -0,0 +1,390 @@
+package promotion
+
+import (
+	"context"
+	"ecom_go/services/auth"
+	"ecom_go/services/shopmember"
+	"ecom_go/types"
+	"ecom_go/utils"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	store        types.PromotionStore
+	productStore types.ProductStore
+	shopStore    types.ShopStore
+	memberStore  types.ShopMemberStore
+	userStore    types.UserStore
+}
+
+func NewHandler(store types.PromotionStore, productStore types.ProductStore, shopStore types.ShopStore, memberStore types.ShopMemberStore, userStore types.UserStore) *Handler {
+	return &Handler{
+		store:        store,
+		productStore: productStore,
+		shopStore:    shopStore,
+		memberStore:  memberStore,
+		userStore:    userStore,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("", auth.WithJWTAuth(h.handleGetPromotions, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("", auth.WithJWTAuth(h.handleCreatePromotion, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/evaluate", auth.WithJWTAuth(h.handleEvaluateCart, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/{promotion_id}", auth.WithJWTAuth(h.handleGetPromotion, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/{promotion_id}", auth.WithJWTAuth(h.handleUpdatePromotion, h.userStore)).Methods(http.MethodPut)
+	router.HandleFunc("/{promotion_id}", auth.WithJWTAuth(h.handleDeletePromotion, h.userStore)).Methods(http.MethodDelete)
+}
+
+func (h *Handler) handleGetPromotions(w http.ResponseWriter, r *http.Request) {
+	var shopID *int
+	if str := r.URL.Query().Get("shop_id"); str != "" {
+		id, err := strconv.Atoi(str)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid shop ID"))
+			return
+		}
+		shopID = &id
+	}
+
+	allowed, err := h.canManage(r.Context(), auth.GetUserIDFromContext(r.Context()), shopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !allowed {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you do not have permission to manage these promotions"))
+		return
+	}
+
+	promotions, err := h.store.GetPromotions(r.Context(), shopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, promotions)
+}
+
+func (h *Handler) handleGetPromotion(w http.ResponseWriter, r *http.Request) {
+	promotion, ok := h.getManagedPromotion(w, r)
+	if !ok {
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, promotion)
+}
+
+func (h *Handler) handleCreatePromotion(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	promotion, ok := h.parsePromotion(w, r)
+	if !ok {
+		return
+	}
+
+	if promotion.ShopID != nil {
+		if _, err := h.shopStore.GetShopByID(r.Context(), *promotion.ShopID); err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	allowed, err := h.canManage(r.Context(), userID, promotion.ShopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !allowed {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you do not have permission to manage these promotions"))
+		return
+	}
+
+	if err := h.validateTargets(r.Context(), promotion); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", err))
+		return
+	}
+
+	if !h.checkCode(r.Context(), w, 0, promotion.Code) {
+		return
+	}
+
+	promotionID, err := h.store.CreatePromotion(r.Context(), userID, promotion)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	createdPromotion, _ := h.store.GetPromotionByID(r.Context(), promotionID)
+	utils.WriteJSON(w, http.StatusCreated, createdPromotion)
+}
+
+func (h *Handler) handleUpdatePromotion(w http.ResponseWriter, r *http.Request) {
+	existingPromotion, ok := h.getManagedPromotion(w, r)
+	if !ok {
+		return
+	}
+
+	promotion, ok := h.parsePromotion(w, r)
+	if !ok {
+		return
+	}
+
+	// A promotion stays with the shop it was created for.
+	promotion.ShopID = existingPromotion.ShopID
+
+	if err := h.validateTargets(r.Context(), promotion); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", err))
+		return
+	}
+
+	if !h.checkCode(r.Context(), w, existingPromotion.ID, promotion.Code) {
+		return
+	}
+
+	if err := h.store.UpdatePromotion(r.Context(), existingPromotion.ID, promotion); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	updatedPromotion, _ := h.store.GetPromotionByID(r.Context(), existingPromotion.ID)
+	utils.WriteJSON(w, http.StatusOK, updatedPromotion)
+}
+
+func (h *Handler) handleDeletePromotion(w http.ResponseWriter, r *http.Request) {
+	existingPromotion, ok := h.getManagedPromotion(w, r)
+	if !ok {
+		return
+	}
+
+	rowsAffected, err := h.store.DeletePromotion(r.Context(), existingPromotion.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to delete promotion: %w", err))
+		return
+	}
+
+	if rowsAffected == 0 {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("promotion not found"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleEvaluateCart(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var payload types.EvaluateCartPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	cart := types.Cart{
+		UserID:        userID,
+		ShippingCents: payload.ShippingCents,
+		CouponCodes:   payload.CouponCodes,
+		At:            time.Now().UTC(),
+	}
+
+	for _, item := range payload.Items {
+		product, err := h.productStore.GetProductByID(r.Context(), item.ProductID)
+		if err != nil {
+			utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product %d not found", item.ProductID))
+			return
+		}
+
+		shop, err := h.shopStore.GetShopByID(r.Context(), product.ShopID)
+		if err != nil {
+			utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product %d not found", item.ProductID))
+			return
+		}
+
+		visible, err := shopmember.CanViewShop(r.Context(), h.memberStore, h.userStore, shop, userID)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if !visible {
+			utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product %d not found", item.ProductID))
+			return
+		}
+
+		cart.Lines = append(cart.Lines, types.CartLine{
+			ProductID:      product.ID,
+			ShopID:         product.ShopID,
+			CategoryID:     product.CategoryID,
+			Quantity:       item.Quantity,
+			UnitPriceCents: product.PriceCents,
+		})
+	}
+
+	promotions, err := h.store.GetApplicablePromotions(r.Context(), userID, cart.At)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, Summarize(cart, Evaluate(cart, promotions)))
+}
+
+// parsePromotion decodes and validates a promotion payload, filling in the
+// defaults of the optional fields.
+func (h *Handler) parsePromotion(w http.ResponseWriter, r *http.Request) (types.CreateUpdatePromotionPayload, bool) {
+	var promotion types.CreateUpdatePromotionPayload
+	if err := utils.ParseJSON(r, &promotion); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return promotion, false
+	}
+
+	// Buy X get Y promotions give the Y items away unless a percentage is set.
+	if promotion.Type == types.PromotionTypeBuyXGetY && promotion.Value == 0 {
+		promotion.Value = 100
+	}
+
+	if promotion.Code != nil {
+		code := strings.ToUpper(strings.TrimSpace(*promotion.Code))
+		promotion.Code = &code
+	}
+
+	if err := utils.Validate.Struct(promotion); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return promotion, false
+	}
+
+	if err := ValidatePromotion(promotion); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", err))
+		return promotion, false
+	}
+
+	enabled := true
+	if promotion.Stackable == nil {
+		promotion.Stackable = &enabled
+	}
+	if promotion.Active == nil {
+		promotion.Active = &enabled
+	}
+
+	return promotion, true
+}
+
+// validateTargets makes sure a shop promotion only targets that shop and its
+// own products.
+func (h *Handler) validateTargets(ctx context.Context, promotion types.CreateUpdatePromotionPayload) error {
+	if promotion.ShopID == nil {
+		return nil
+	}
+
+	for _, targetID := range promotion.TargetIDs {
+		switch promotion.Scope {
+		case types.PromotionScopeShop:
+			if targetID != *promotion.ShopID {
+				return fmt.Errorf("a shop promotion can only target its own shop")
+			}
+		case types.PromotionScopeProduct:
+			product, err := h.productStore.GetProductByID(ctx, targetID)
+			if err != nil || product.ShopID != *promotion.ShopID {
+				return fmt.Errorf("product %d does not belong to the shop", targetID)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkCode makes sure no other promotion uses the coupon code, writing a
+// conflict response otherwise. Codes are unique across all shops.
+func (h *Handler) checkCode(ctx context.Context, w http.ResponseWriter, promotionID int, code *string) bool {
+	if code == nil {
+		return true
+	}
+
+	other, err := h.store.GetPromotionByCode(ctx, *code)
+	if err != nil && !errors.Is(err, types.ErrNotFound) {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return false
+	}
+
+	if err == nil && other.ID != promotionID {
+		utils.WriteError(w, http.StatusConflict, fmt.Errorf("coupon code %s is already in use", *code))
+		return false
+	}
+
+	return true
+}
+
+// getManagedPromotion loads the promotion from the request path and makes
+// sure the authenticated user may manage it, writing an error response
+// otherwise.
+func (h *Handler) getManagedPromotion(w http.ResponseWriter, r *http.Request) (*types.Promotion, bool) {
+	vars := mux.Vars(r)
+	str, ok := vars["promotion_id"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing promotion ID"))
+		return nil, false
+	}
+
+	promotionID, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid promotion ID"))
+		return nil, false
+	}
+
+	promotion, err := h.store.GetPromotionByID(r.Context(), promotionID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	allowed, err := h.canManage(r.Context(), auth.GetUserIDFromContext(r.Context()), promotion.ShopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	if !allowed {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you do not have permission to manage this promotion"))
+		return nil, false
+	}
+
+	return promotion, true
+}
+
+// canManage reports whether the user may manage the promotions of the shop.
+// Platform wide promotions, those without a shop, are reserved to admins.
+func (h *Handler) canManage(ctx context.Context, userID int, shopID *int) (bool, error) {
+	if shopID != nil {
+		isManager, err := shopmember.HasRole(ctx, h.memberStore, *shopID, userID, types.ShopRoleManager)
+		if err != nil || isManager {
+			return isManager, err
+		}
+	}
+
+	user, err := h.userStore.GetUserByID(ctx, userID)
+	if errors.Is(err, types.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return user.Role == "admin", nil
+}
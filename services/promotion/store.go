@@ -0,0 +1,347 @@
+package promotion
+
+import (
+	"context"
+	"database/sql"
+	"ecom_go/db"
+	"ecom_go/types"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUsageLimitReached     = errors.New("promotion usage limit reached")
+	ErrUserUsageLimitReached = errors.New("promotion usage limit per user reached")
+)
+
+type Store struct {
+	db *db.DB
+}
+
+func NewStore(db *db.DB) *Store {
+	return &Store{db: db}
+}
+
+const promotionColumns = `id, shop_id, created_by, name, code, type, value, buy_quantity, get_quantity, scope,
+	min_subtotal_cents, starts_at, ends_at, usage_limit, usage_limit_per_user, usage_count, stackable, priority, active,
+	created_at, updated_at`
+
+func (s *Store) GetPromotionByID(ctx context.Context, promotionID int) (*types.Promotion, error) {
+	promotion, err := scanPromotion(s.db.QueryRowContext(ctx, "SELECT "+promotionColumns+" FROM promotions WHERE id = ?", promotionID))
+	if err != nil {
+		return nil, types.NotFound("promotion", err)
+	}
+
+	promotions := []types.Promotion{*promotion}
+	if err := s.attachTargets(ctx, promotions); err != nil {
+		return nil, err
+	}
+
+	return &promotions[0], nil
+}
+
+// GetPromotionByCode finds the promotion with the coupon code. Codes are
+// matched regardless of case, like coupons entered at checkout.
+func (s *Store) GetPromotionByCode(ctx context.Context, code string) (*types.Promotion, error) {
+	promotion, err := scanPromotion(s.db.QueryRowContext(ctx, "SELECT "+promotionColumns+" FROM promotions WHERE LOWER(code) = LOWER(?)", code))
+	if err != nil {
+		return nil, types.NotFound("promotion", err)
+	}
+
+	return promotion, nil
+}
+
+// GetPromotions lists the promotions of a shop, or every promotion when
+// shopID is nil.
+func (s *Store) GetPromotions(ctx context.Context, shopID *int) ([]types.Promotion, error) {
+	if shopID == nil {
+		return s.queryPromotions(ctx, "SELECT "+promotionColumns+" FROM promotions ORDER BY id DESC")
+	}
+
+	return s.queryPromotions(ctx, "SELECT "+promotionColumns+" FROM promotions WHERE shop_id = ? ORDER BY id DESC", *shopID)
+}
+
+// GetApplicablePromotions returns the active promotions valid at the given
+// time, with the number of times the user has redeemed each of them.
+func (s *Store) GetApplicablePromotions(ctx context.Context, userID int, at time.Time) ([]types.Promotion, error) {
+	promotions, err := s.queryPromotions(ctx,
+		"SELECT "+promotionColumns+" FROM promotions WHERE active = TRUE AND (starts_at IS NULL OR starts_at <= ?) AND (ends_at IS NULL OR ends_at > ?)",
+		at, at)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT promotion_id, COUNT(*) FROM promotion_redemptions WHERE user_id = ? GROUP BY promotion_id", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := map[int]int{}
+	for rows.Next() {
+		var promotionID, count int
+		if err := rows.Scan(&promotionID, &count); err != nil {
+			return nil, err
+		}
+
+		usage[promotionID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range promotions {
+		promotions[i].UserUsageCount = usage[promotions[i].ID]
+	}
+
+	return promotions, nil
+}
+
+func (s *Store) CreatePromotion(ctx context.Context, createdBy int, promotion types.CreateUpdatePromotionPayload) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	promotionID, err := s.db.InsertTx(ctx, tx,
+		`INSERT INTO promotions (shop_id, created_by, name, code, type, value, buy_quantity, get_quantity, scope,
+			min_subtotal_cents, starts_at, ends_at, usage_limit, usage_limit_per_user, stackable, priority, active)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		promotion.ShopID, createdBy, promotion.Name, promotion.Code, promotion.Type, promotion.Value,
+		promotion.BuyQuantity, promotion.GetQuantity, promotion.Scope, promotion.MinSubtotalCents,
+		promotion.StartsAt, promotion.EndsAt, promotion.UsageLimit, promotion.UsageLimitPerUser,
+		promotion.Stackable, promotion.Priority, promotion.Active)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := insertTargets(ctx, tx, int(promotionID), promotion.TargetIDs); err != nil {
+		return 0, err
+	}
+
+	return int(promotionID), tx.Commit()
+}
+
+// UpdatePromotion replaces every field of the promotion. Stackable and Active
+// must be set.
+func (s *Store) UpdatePromotion(ctx context.Context, promotionID int, promotion types.CreateUpdatePromotionPayload) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE promotions SET name = ?, code = ?, type = ?, value = ?, buy_quantity = ?, get_quantity = ?, scope = ?,
+			min_subtotal_cents = ?, starts_at = ?, ends_at = ?, usage_limit = ?, usage_limit_per_user = ?,
+			stackable = ?, priority = ?, active = ?
+		WHERE id = ?`,
+		promotion.Name, promotion.Code, promotion.Type, promotion.Value, promotion.BuyQuantity, promotion.GetQuantity,
+		promotion.Scope, promotion.MinSubtotalCents, promotion.StartsAt, promotion.EndsAt, promotion.UsageLimit,
+		promotion.UsageLimitPerUser, promotion.Stackable, promotion.Priority, promotion.Active, promotionID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM promotion_targets WHERE promotion_id = ?", promotionID); err != nil {
+		return err
+	}
+
+	if err := insertTargets(ctx, tx, promotionID, promotion.TargetIDs); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) DeletePromotion(ctx context.Context, promotionID int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM promotions WHERE id = ?", promotionID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// RecordPromotionRedemptions counts a use of each promotion by the user
+// towards its usage limits, either for all of them or for none. It is meant
+// for the checkout once an order using the promotions is placed, and never
+// counts an order twice.
+func (s *Store) RecordPromotionRedemptions(ctx context.Context, userID int, orderID string, promotionIDs []int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Locking the promotions in id order keeps concurrent redemptions of the
+	// same promotions from deadlocking.
+	ids := append([]int(nil), promotionIDs...)
+	sort.Ints(ids)
+
+	for _, promotionID := range ids {
+		if err := redeemPromotion(ctx, tx, promotionID, userID, orderID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// redeemPromotion locks the promotion row while checking its global and
+// per user limits, so that concurrent redemptions cannot both take the last
+// use.
+func redeemPromotion(ctx context.Context, tx *sql.Tx, promotionID int, userID int, orderID string) error {
+	var usageLimit, usageLimitPerUser sql.NullInt64
+	var usageCount int64
+	err := tx.QueryRowContext(ctx, "SELECT usage_limit, usage_limit_per_user, usage_count FROM promotions WHERE id = ? FOR UPDATE", promotionID).
+		Scan(&usageLimit, &usageLimitPerUser, &usageCount)
+	if err != nil {
+		return types.NotFound(fmt.Sprintf("promotion %d", promotionID), err)
+	}
+
+	var recorded int
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM promotion_redemptions WHERE promotion_id = ? AND order_id = ?", promotionID, orderID).
+		Scan(&recorded)
+	if err != nil {
+		return err
+	}
+
+	if recorded > 0 {
+		return nil
+	}
+
+	if usageLimit.Valid && usageCount >= usageLimit.Int64 {
+		return fmt.Errorf("promotion %d: %w", promotionID, ErrUsageLimitReached)
+	}
+
+	if usageLimitPerUser.Valid {
+		var userCount int64
+		err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM promotion_redemptions WHERE promotion_id = ? AND user_id = ?", promotionID, userID).
+			Scan(&userCount)
+		if err != nil {
+			return err
+		}
+
+		if userCount >= usageLimitPerUser.Int64 {
+			return fmt.Errorf("promotion %d: %w", promotionID, ErrUserUsageLimitReached)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE promotions SET usage_count = usage_count + 1 WHERE id = ?", promotionID); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO promotion_redemptions (promotion_id, user_id, order_id) VALUES (?, ?, ?)", promotionID, userID, orderID)
+
+	return err
+}
+
+func (s *Store) queryPromotions(ctx context.Context, query string, args ...any) ([]types.Promotion, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promotions := []types.Promotion{}
+	for rows.Next() {
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		promotions = append(promotions, *promotion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := s.attachTargets(ctx, promotions); err != nil {
+		return nil, err
+	}
+
+	return promotions, nil
+}
+
+func (s *Store) attachTargets(ctx context.Context, promotions []types.Promotion) error {
+	if len(promotions) == 0 {
+		return nil
+	}
+
+	index := map[int]int{}
+	args := make([]any, len(promotions))
+	for i := range promotions {
+		promotions[i].TargetIDs = []int{}
+		index[promotions[i].ID] = i
+		args[i] = promotions[i].ID
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+	rows, err := s.db.QueryContext(ctx, "SELECT promotion_id, target_id FROM promotion_targets WHERE promotion_id IN ("+placeholders+") ORDER BY id", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var promotionID, targetID int
+		if err := rows.Scan(&promotionID, &targetID); err != nil {
+			return err
+		}
+
+		i := index[promotionID]
+		promotions[i].TargetIDs = append(promotions[i].TargetIDs, targetID)
+	}
+
+	return rows.Err()
+}
+
+func insertTargets(ctx context.Context, tx *sql.Tx, promotionID int, targetIDs []int) error {
+	for _, targetID := range targetIDs {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO promotion_targets (promotion_id, target_id) VALUES (?, ?)", promotionID, targetID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func scanPromotion(row db.Scanner) (*types.Promotion, error) {
+	promotion := new(types.Promotion)
+
+	err := row.Scan(
+		&promotion.ID,
+		&promotion.ShopID,
+		&promotion.CreatedBy,
+		&promotion.Name,
+		&promotion.Code,
+		&promotion.Type,
+		&promotion.Value,
+		&promotion.BuyQuantity,
+		&promotion.GetQuantity,
+		&promotion.Scope,
+		&promotion.MinSubtotalCents,
+		&promotion.StartsAt,
+		&promotion.EndsAt,
+		&promotion.UsageLimit,
+		&promotion.UsageLimitPerUser,
+		&promotion.UsageCount,
+		&promotion.Stackable,
+		&promotion.Priority,
+		&promotion.Active,
+		&promotion.CreatedAt,
+		&promotion.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return promotion, nil
+}
@@ -0,0 +1,432 @@
+package review
+
+import (
+	"context"
+	"ecom_go/services/auth"
+	"ecom_go/services/shopmember"
+	"ecom_go/types"
+	"ecom_go/utils"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type Handler struct {
+	store        types.ReviewStore
+	productStore types.ProductStore
+	shopStore    types.ShopStore
+	memberStore  types.ShopMemberStore
+	userStore    types.UserStore
+}
+
+func NewHandler(store types.ReviewStore, productStore types.ProductStore, shopStore types.ShopStore, memberStore types.ShopMemberStore, userStore types.UserStore) *Handler {
+	return &Handler{
+		store:        store,
+		productStore: productStore,
+		shopStore:    shopStore,
+		memberStore:  memberStore,
+		userStore:    userStore,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("", auth.WithJWTAuth(h.handleGetReviews, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("", auth.WithJWTAuth(h.handleCreateReview, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/moderation", auth.WithAdminJWTAuth(h.handleGetModerationQueue, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/{review_id}", auth.WithJWTAuth(h.handleGetReview, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/{review_id}", auth.WithJWTAuth(h.handleUpdateReview, h.userStore)).Methods(http.MethodPut)
+	router.HandleFunc("/{review_id}", auth.WithJWTAuth(h.handleDeleteReview, h.userStore)).Methods(http.MethodDelete)
+	router.HandleFunc("/{review_id}/reply", auth.WithJWTAuth(h.handleReplyToReview, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/{review_id}/helpful", auth.WithJWTAuth(h.handleAddVote, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/{review_id}/helpful", auth.WithJWTAuth(h.handleRemoveVote, h.userStore)).Methods(http.MethodDelete)
+	router.HandleFunc("/{review_id}/moderate", auth.WithAdminJWTAuth(h.handleModerateReview, h.userStore)).Methods(http.MethodPost)
+}
+
+func (h *Handler) handleGetReviews(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	productID, err := strconv.Atoi(query.Get("product_id"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing or invalid product ID"))
+		return
+	}
+
+	sort := query.Get("sort")
+	if sort == "" {
+		sort = "newest"
+	}
+	if _, ok := sortOrders[sort]; !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("sort must be one of newest, highest or most_helpful"))
+		return
+	}
+
+	limit, offset, err := pagination(query.Get("limit"), query.Get("offset"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, ok := h.getVisibleProduct(w, r, productID); !ok {
+		return
+	}
+
+	reviews, err := h.store.GetReviewsByProductID(r.Context(), productID, sort, limit, offset)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, reviews)
+}
+
+func (h *Handler) handleGetModerationQueue(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = types.ReviewStatusFlagged
+	}
+
+	if status != types.ReviewStatusFlagged && status != types.ReviewStatusHidden && status != types.ReviewStatusVisible {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid review status %q", status))
+		return
+	}
+
+	reviews, err := h.store.GetReviewsByStatus(r.Context(), status)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, reviews)
+}
+
+func (h *Handler) handleGetReview(w http.ResponseWriter, r *http.Request) {
+	review, ok := h.getReviewFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	userID := auth.GetUserIDFromContext(r.Context())
+	if review.Status == types.ReviewStatusHidden && review.UserID != userID && !h.isAdmin(r.Context(), userID) {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("review not found"))
+		return
+	}
+
+	if _, ok := h.getVisibleProduct(w, r, review.ProductID); !ok {
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, review)
+}
+
+func (h *Handler) handleCreateReview(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserIDFromContext(r.Context())
+	if userID == -1 {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("unauthorized"))
+		return
+	}
+
+	var review types.CreateReviewPayload
+	if err := utils.ParseJSON(r, &review); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(review); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	product, ok := h.getVisibleProduct(w, r, review.ProductID)
+	if !ok {
+		return
+	}
+
+	isMember, err := shopmember.HasRole(r.Context(), h.memberStore, product.ShopID, userID, types.ShopRoleStaff)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if isMember {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("shop members cannot review their own products"))
+		return
+	}
+
+	if _, err := h.store.GetReviewByProductAndUser(r.Context(), review.ProductID, userID); err == nil {
+		utils.WriteError(w, http.StatusConflict, fmt.Errorf("you have already reviewed this product"))
+		return
+	}
+
+	reviewID, err := h.store.CreateReview(r.Context(), userID, review)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	createdReview, _ := h.store.GetReviewByID(r.Context(), reviewID)
+	utils.WriteJSON(w, http.StatusCreated, createdReview)
+}
+
+func (h *Handler) handleUpdateReview(w http.ResponseWriter, r *http.Request) {
+	existingReview, ok := h.getReviewFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if existingReview.UserID != auth.GetUserIDFromContext(r.Context()) {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you can only edit your own reviews"))
+		return
+	}
+
+	var review types.UpdateReviewPayload
+	if err := utils.ParseJSON(r, &review); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(review); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	if review.Rating == nil {
+		review.Rating = &existingReview.Rating
+	}
+	if review.Body == nil {
+		review.Body = existingReview.Body
+	}
+
+	if err := h.store.UpdateReview(r.Context(), existingReview.ID, review); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	updatedReview, _ := h.store.GetReviewByID(r.Context(), existingReview.ID)
+	utils.WriteJSON(w, http.StatusOK, updatedReview)
+}
+
+func (h *Handler) handleDeleteReview(w http.ResponseWriter, r *http.Request) {
+	existingReview, ok := h.getReviewFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	userID := auth.GetUserIDFromContext(r.Context())
+	if existingReview.UserID != userID && !h.isAdmin(r.Context(), userID) {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you can only delete your own reviews"))
+		return
+	}
+
+	rowsAffected, err := h.store.DeleteReview(r.Context(), existingReview.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to delete review: %w", err))
+		return
+	}
+
+	if rowsAffected == 0 {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("review not found"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleReplyToReview(w http.ResponseWriter, r *http.Request) {
+	existingReview, ok := h.getReviewFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	product, err := h.productStore.GetProductByID(r.Context(), existingReview.ProductID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	allowed, err := shopmember.HasRole(r.Context(), h.memberStore, product.ShopID, auth.GetUserIDFromContext(r.Context()), types.ShopRoleManager)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !allowed {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you do not have permission to reply to reviews of this shop"))
+		return
+	}
+
+	var reply types.ReviewReplyPayload
+	if err := utils.ParseJSON(r, &reply); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(reply); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	if err := h.store.ReplyToReview(r.Context(), existingReview.ID, reply.Reply); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	updatedReview, _ := h.store.GetReviewByID(r.Context(), existingReview.ID)
+	utils.WriteJSON(w, http.StatusOK, updatedReview)
+}
+
+func (h *Handler) handleAddVote(w http.ResponseWriter, r *http.Request) {
+	h.vote(w, r, h.store.AddReviewVote)
+}
+
+func (h *Handler) handleRemoveVote(w http.ResponseWriter, r *http.Request) {
+	h.vote(w, r, h.store.RemoveReviewVote)
+}
+
+// vote applies a helpful vote change for the authenticated user and responds
+// with the updated review. Authors cannot vote on their own reviews.
+func (h *Handler) vote(w http.ResponseWriter, r *http.Request, apply func(ctx context.Context, reviewID int, userID int) (bool, error)) {
+	existingReview, ok := h.getReviewFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	userID := auth.GetUserIDFromContext(r.Context())
+	if existingReview.UserID == userID {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you cannot vote on your own review"))
+		return
+	}
+
+	if existingReview.Status == types.ReviewStatusHidden {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("review not found"))
+		return
+	}
+
+	if _, err := apply(r.Context(), existingReview.ID, userID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	updatedReview, _ := h.store.GetReviewByID(r.Context(), existingReview.ID)
+	utils.WriteJSON(w, http.StatusOK, updatedReview)
+}
+
+func (h *Handler) handleModerateReview(w http.ResponseWriter, r *http.Request) {
+	existingReview, ok := h.getReviewFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var moderation types.ModerateReviewPayload
+	if err := utils.ParseJSON(r, &moderation); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(moderation); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	if err := h.store.ModerateReview(r.Context(), existingReview.ID, moderation.Status, moderation.Reason); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	updatedReview, _ := h.store.GetReviewByID(r.Context(), existingReview.ID)
+	utils.WriteJSON(w, http.StatusOK, updatedReview)
+}
+
+func (h *Handler) getReviewFromPath(w http.ResponseWriter, r *http.Request) (*types.Review, bool) {
+	vars := mux.Vars(r)
+	str, ok := vars["review_id"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing review ID"))
+		return nil, false
+	}
+
+	reviewID, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid review ID"))
+		return nil, false
+	}
+
+	review, err := h.store.GetReviewByID(r.Context(), reviewID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	return review, true
+}
+
+// getVisibleProduct loads the product and makes sure its shop is visible to
+// the authenticated user, writing a not found response otherwise.
+func (h *Handler) getVisibleProduct(w http.ResponseWriter, r *http.Request, productID int) (*types.Product, bool) {
+	product, err := h.productStore.GetProductByID(r.Context(), productID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	shop, err := h.shopStore.GetShopByID(r.Context(), product.ShopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product not found"))
+		return nil, false
+	}
+
+	visible, err := shopmember.CanViewShop(r.Context(), h.memberStore, h.userStore, shop, auth.GetUserIDFromContext(r.Context()))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	if !visible {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product not found"))
+		return nil, false
+	}
+
+	return product, true
+}
+
+func (h *Handler) isAdmin(ctx context.Context, userID int) bool {
+	user, err := h.userStore.GetUserByID(ctx, userID)
+	if err != nil {
+		return false
+	}
+
+	return user.Role == "admin"
+}
+
+func pagination(limitStr, offsetStr string) (int, int, error) {
+	limit, offset := defaultPageSize, 0
+
+	if limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n < 1 {
+			return 0, 0, fmt.Errorf("invalid limit")
+		}
+		limit = min(n, maxPageSize)
+	}
+
+	if offsetStr != "" {
+		n, err := strconv.Atoi(offsetStr)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("invalid offset")
+		}
+		offset = n
+	}
+
+	return limit, offset, nil
+}
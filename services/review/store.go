@@ -0,0 +1,374 @@
+package review
+
+import (
+	"context"
+	"database/sql"
+	"ecom_go/db"
+	"ecom_go/types"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var sortOrders = map[string]string{
+	"newest":       "created_at DESC, id DESC",
+	"highest":      "rating DESC, created_at DESC, id DESC",
+	"most_helpful": "helpful_count DESC, created_at DESC, id DESC",
+}
+
+type Store struct {
+	db *db.DB
+}
+
+func NewStore(db *db.DB) *Store {
+	return &Store{db: db}
+}
+
+const reviewColumns = `id, product_id, user_id, rating, body, status, moderation_reason, owner_reply, owner_replied_at,
+	helpful_count, created_at, updated_at`
+
+func (s *Store) GetReviewByID(ctx context.Context, reviewID int) (*types.Review, error) {
+	review, err := scanReview(s.db.QueryRowContext(ctx, "SELECT "+reviewColumns+" FROM product_reviews WHERE id = ?", reviewID))
+	if err != nil {
+		return nil, types.NotFound("review", err)
+	}
+
+	reviews := []types.Review{*review}
+	if err := s.attachPhotos(ctx, reviews); err != nil {
+		return nil, err
+	}
+
+	return &reviews[0], nil
+}
+
+func (s *Store) GetReviewByProductAndUser(ctx context.Context, productID int, userID int) (*types.Review, error) {
+	review, err := scanReview(s.db.QueryRowContext(ctx,
+		"SELECT "+reviewColumns+" FROM product_reviews WHERE product_id = ? AND user_id = ?", productID, userID))
+	if err != nil {
+		return nil, types.NotFound("review", err)
+	}
+
+	return review, nil
+}
+
+// GetReviewsByProductID lists the reviews of a product that are not hidden.
+// sort is one of newest, highest or most_helpful.
+func (s *Store) GetReviewsByProductID(ctx context.Context, productID int, sort string, limit int, offset int) ([]types.Review, error) {
+	order, ok := sortOrders[sort]
+	if !ok {
+		return nil, fmt.Errorf("invalid sort order %q", sort)
+	}
+
+	return s.queryReviews(ctx,
+		"SELECT "+reviewColumns+" FROM product_reviews WHERE product_id = ? AND status <> ? ORDER BY "+order+" LIMIT ? OFFSET ?",
+		productID, types.ReviewStatusHidden, limit, offset)
+}
+
+func (s *Store) GetReviewsByStatus(ctx context.Context, status string) ([]types.Review, error) {
+	return s.queryReviews(ctx, "SELECT "+reviewColumns+" FROM product_reviews WHERE status = ? ORDER BY updated_at DESC", status)
+}
+
+func (s *Store) CreateReview(ctx context.Context, userID int, review types.CreateReviewPayload) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	reviewID, err := s.db.InsertTx(ctx, tx,
+		"INSERT INTO product_reviews (product_id, user_id, rating, body) VALUES (?, ?, ?, ?)",
+		review.ProductID, userID, review.Rating, review.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := insertPhotos(ctx, tx, int(reviewID), review.Photos); err != nil {
+		return 0, err
+	}
+
+	if err := refreshRatings(ctx, tx, review.ProductID); err != nil {
+		return 0, err
+	}
+
+	return int(reviewID), tx.Commit()
+}
+
+// UpdateReview expects Rating and Body to be set. Photos replaces the photos
+// of the review unless it is nil.
+func (s *Store) UpdateReview(ctx context.Context, reviewID int, review types.UpdateReviewPayload) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE product_reviews SET rating = ?, body = ? WHERE id = ?",
+		review.Rating, review.Body, reviewID); err != nil {
+		return err
+	}
+
+	if review.Photos != nil {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM review_photos WHERE review_id = ?", reviewID); err != nil {
+			return err
+		}
+
+		if err := insertPhotos(ctx, tx, reviewID, *review.Photos); err != nil {
+			return err
+		}
+	}
+
+	if err := refreshRatingsForReview(ctx, tx, reviewID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) DeleteReview(ctx context.Context, reviewID int) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var productID int
+	if err := tx.QueryRowContext(ctx, "SELECT product_id FROM product_reviews WHERE id = ?", reviewID).Scan(&productID); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM product_reviews WHERE id = ?", reviewID)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := refreshRatings(ctx, tx, productID); err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, tx.Commit()
+}
+
+func (s *Store) ReplyToReview(ctx context.Context, reviewID int, reply string) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE product_reviews SET owner_reply = ?, owner_replied_at = ? WHERE id = ?",
+		reply, time.Now().UTC(), reviewID)
+
+	return err
+}
+
+func (s *Store) ModerateReview(ctx context.Context, reviewID int, status string, reason *string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE product_reviews SET status = ?, moderation_reason = ? WHERE id = ?",
+		status, reason, reviewID); err != nil {
+		return err
+	}
+
+	if err := refreshRatingsForReview(ctx, tx, reviewID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AddReviewVote records a helpful vote and reports whether it was new.
+func (s *Store) AddReviewVote(ctx context.Context, reviewID int, userID int) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM review_votes WHERE review_id = ? AND user_id = ?",
+		reviewID, userID).Scan(&count); err != nil {
+		return false, err
+	}
+
+	if count > 0 {
+		return false, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO review_votes (review_id, user_id) VALUES (?, ?)", reviewID, userID); err != nil {
+		return false, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE product_reviews SET helpful_count = helpful_count + 1 WHERE id = ?", reviewID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// RemoveReviewVote withdraws a helpful vote and reports whether one existed.
+func (s *Store) RemoveReviewVote(ctx context.Context, reviewID int, userID int) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM review_votes WHERE review_id = ? AND user_id = ?", reviewID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE product_reviews SET helpful_count = helpful_count - 1 WHERE id = ? AND helpful_count > 0",
+		reviewID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (s *Store) queryReviews(ctx context.Context, query string, args ...any) ([]types.Review, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []types.Review{}
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		reviews = append(reviews, *review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := s.attachPhotos(ctx, reviews); err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+func (s *Store) attachPhotos(ctx context.Context, reviews []types.Review) error {
+	if len(reviews) == 0 {
+		return nil
+	}
+
+	index := map[int]int{}
+	args := make([]any, len(reviews))
+	for i := range reviews {
+		reviews[i].Photos = []types.ReviewPhoto{}
+		index[reviews[i].ID] = i
+		args[i] = reviews[i].ID
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+	rows, err := s.db.QueryContext(ctx, "SELECT id, review_id, url, created_at, updated_at FROM review_photos WHERE review_id IN ("+placeholders+") ORDER BY id", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		photo := types.ReviewPhoto{}
+		if err := rows.Scan(&photo.ID, &photo.ReviewID, &photo.URL, &photo.CreatedAt, &photo.UpdatedAt); err != nil {
+			return err
+		}
+
+		i := index[photo.ReviewID]
+		reviews[i].Photos = append(reviews[i].Photos, photo)
+	}
+
+	return rows.Err()
+}
+
+func insertPhotos(ctx context.Context, tx *sql.Tx, reviewID int, photos []string) error {
+	for _, url := range photos {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO review_photos (review_id, url) VALUES (?, ?)", reviewID, url); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func refreshRatingsForReview(ctx context.Context, tx *sql.Tx, reviewID int) error {
+	var productID int
+	if err := tx.QueryRowContext(ctx, "SELECT product_id FROM product_reviews WHERE id = ?", reviewID).Scan(&productID); err != nil {
+		return err
+	}
+
+	return refreshRatings(ctx, tx, productID)
+}
+
+// refreshRatings recomputes the rating aggregates of a product and of the
+// shop it belongs to from the reviews that are not hidden.
+func refreshRatings(ctx context.Context, tx *sql.Tx, productID int) error {
+	_, err := tx.ExecContext(ctx,
+		`UPDATE products SET
+			rating_average = COALESCE((SELECT AVG(rating) FROM product_reviews WHERE product_id = ? AND status <> ?), 0),
+			rating_count = (SELECT COUNT(*) FROM product_reviews WHERE product_id = ? AND status <> ?)
+		WHERE id = ?`,
+		productID, types.ReviewStatusHidden, productID, types.ReviewStatusHidden, productID)
+	if err != nil {
+		return err
+	}
+
+	var shopID int
+	if err := tx.QueryRowContext(ctx, "SELECT shop_id FROM products WHERE id = ?", productID).Scan(&shopID); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE shops SET
+			rating_average = COALESCE((SELECT AVG(r.rating) FROM product_reviews r
+				JOIN products p ON p.id = r.product_id
+				WHERE p.shop_id = ? AND p.deleted_at IS NULL AND r.status <> ?), 0),
+			rating_count = (SELECT COUNT(*) FROM product_reviews r
+				JOIN products p ON p.id = r.product_id
+				WHERE p.shop_id = ? AND p.deleted_at IS NULL AND r.status <> ?)
+		WHERE id = ?`,
+		shopID, types.ReviewStatusHidden, shopID, types.ReviewStatusHidden, shopID)
+
+	return err
+}
+
+func scanReview(row db.Scanner) (*types.Review, error) {
+	review := new(types.Review)
+
+	err := row.Scan(
+		&review.ID,
+		&review.ProductID,
+		&review.UserID,
+		&review.Rating,
+		&review.Body,
+		&review.Status,
+		&review.ModerationReason,
+		&review.OwnerReply,
+		&review.OwnerRepliedAt,
+		&review.HelpfulCount,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
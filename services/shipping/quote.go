@@ -0,0 +1,193 @@
+package shipping
+
+import (
+	"ecom_go/services/geo"
+	"ecom_go/types"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// volumetricDivisor converts a volume in cubic millimetres into a
+// volumetric weight in grams, the usual 5000 cm³/kg of parcel carriers.
+const volumetricDivisor = 5000
+
+// Parcel is what a shop ships for a quote. Destination coordinates are only
+// needed for radius zones.
+type Parcel struct {
+	WeightGrams   int64
+	SubtotalCents int64
+	Country       string
+	PostalCode    string
+	Location      *types.GeoPoint
+}
+
+// ChargeableWeight returns the weight a product is charged for, the larger
+// of its actual and volumetric weight.
+func ChargeableWeight(product types.Product) int64 {
+	volumetric := int64(product.LengthMm) * int64(product.WidthMm) * int64(product.HeightMm) / volumetricDivisor
+
+	return max(int64(product.WeightGrams), volumetric)
+}
+
+// Quote returns the options the methods of the shop offer for the parcel,
+// cheapest first.
+func Quote(shop *types.Shop, methods []types.ShippingMethod, parcel Parcel) []types.ShippingOption {
+	options := []types.ShippingOption{}
+	for _, method := range methods {
+		if !method.Active || !Delivers(shop, method, parcel) {
+			continue
+		}
+
+		cost, ok := Cost(method, parcel)
+		if !ok {
+			continue
+		}
+
+		options = append(options, types.ShippingOption{
+			MethodID:  method.ID,
+			Name:      method.Name,
+			Type:      method.Type,
+			CostCents: cost,
+		})
+	}
+
+	sort.SliceStable(options, func(i, j int) bool { return options[i].CostCents < options[j].CostCents })
+
+	return options
+}
+
+// Delivers reports whether the method reaches the destination of the parcel.
+func Delivers(shop *types.Shop, method types.ShippingMethod, parcel Parcel) bool {
+	if method.Type == types.ShippingMethodPickup || len(method.Zones) == 0 {
+		return true
+	}
+
+	for _, zone := range method.Zones {
+		switch zone.Type {
+		case types.ShippingZonePostalRange:
+			if parcel.PostalCode == "" || (zone.Country != nil && !strings.EqualFold(*zone.Country, parcel.Country)) {
+				continue
+			}
+			if zone.PostalFrom != nil && zone.PostalTo != nil &&
+				comparePostalCodes(parcel.PostalCode, *zone.PostalFrom) >= 0 &&
+				comparePostalCodes(parcel.PostalCode, *zone.PostalTo) <= 0 {
+				return true
+			}
+
+		case types.ShippingZoneRadius:
+			if zone.RadiusKm == nil || parcel.Location == nil || shop.Latitude == nil || shop.Longitude == nil {
+				continue
+			}
+			origin := types.GeoPoint{Latitude: *shop.Latitude, Longitude: *shop.Longitude}
+			if geo.DistanceKm(origin, *parcel.Location) <= *zone.RadiusKm {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Cost returns what the method charges for the parcel. It reports false when
+// a tiered method has no tier for the parcel.
+func Cost(method types.ShippingMethod, parcel Parcel) (int64, bool) {
+	if method.Type == types.ShippingMethodPickup {
+		return 0, true
+	}
+
+	if method.FreeOverCents != nil && parcel.SubtotalCents >= *method.FreeOverCents {
+		return 0, true
+	}
+
+	switch method.Type {
+	case types.ShippingMethodWeightTiers:
+		return tierRate(method.Tiers, parcel.WeightGrams)
+	case types.ShippingMethodPriceTiers:
+		return tierRate(method.Tiers, parcel.SubtotalCents)
+	default:
+		return method.RateCents, true
+	}
+}
+
+// ValidateMethod checks the rules of a shipping method that struct tags
+// cannot express.
+func ValidateMethod(method types.CreateUpdateShippingMethodPayload) error {
+	tiered := method.Type == types.ShippingMethodWeightTiers || method.Type == types.ShippingMethodPriceTiers
+	if tiered && len(method.Tiers) == 0 {
+		return fmt.Errorf("tiers are required for %s methods", method.Type)
+	}
+
+	if !tiered && len(method.Tiers) > 0 {
+		return fmt.Errorf("tiers are only allowed for weight_tiers and price_tiers methods")
+	}
+
+	if method.Type == types.ShippingMethodPickup && len(method.Zones) > 0 {
+		return fmt.Errorf("pickup methods cannot have zones")
+	}
+
+	seen := map[int64]bool{}
+	for _, tier := range method.Tiers {
+		if seen[tier.MinValue] {
+			return fmt.Errorf("duplicate tier starting at %d", tier.MinValue)
+		}
+		seen[tier.MinValue] = true
+	}
+
+	for _, zone := range method.Zones {
+		switch zone.Type {
+		case types.ShippingZonePostalRange:
+			if zone.Country == nil || zone.PostalFrom == nil || zone.PostalTo == nil {
+				return fmt.Errorf("postal_range zones need a country, postal_from and postal_to")
+			}
+			if comparePostalCodes(*zone.PostalFrom, *zone.PostalTo) > 0 {
+				return fmt.Errorf("postal_from must not be after postal_to")
+			}
+		case types.ShippingZoneRadius:
+			if zone.RadiusKm == nil {
+				return fmt.Errorf("radius zones need a radius_km")
+			}
+		}
+	}
+
+	return nil
+}
+
+func tierRate(tiers []types.ShippingRateTier, value int64) (int64, bool) {
+	var rate, reached int64
+	found := false
+	for _, tier := range tiers {
+		if tier.MinValue <= value && (!found || tier.MinValue >= reached) {
+			rate, reached, found = tier.RateCents, tier.MinValue, true
+		}
+	}
+
+	return rate, found
+}
+
+// comparePostalCodes compares postal codes ignoring case and spaces. Numeric
+// codes compare by value, anything else alphabetically.
+func comparePostalCodes(a, b string) int {
+	a = normalizePostalCode(a)
+	b = normalizePostalCode(b)
+
+	na, errA := strconv.ParseInt(a, 10, 64)
+	nb, errB := strconv.ParseInt(b, 10, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case na < nb:
+			return -1
+		case na > nb:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	return strings.Compare(a, b)
+}
+
+func normalizePostalCode(code string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(code), ""))
+}
@@ -0,0 +1,397 @@
+package shipping
+
+import (
+	"context"
+	"ecom_go/services/auth"
+	"ecom_go/services/logging"
+	"ecom_go/services/shopmember"
+	"ecom_go/types"
+	"ecom_go/utils"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	store        types.ShippingMethodStore
+	productStore types.ProductStore
+	shopStore    types.ShopStore
+	memberStore  types.ShopMemberStore
+	userStore    types.UserStore
+	geocoder     types.Geocoder
+}
+
+func NewHandler(store types.ShippingMethodStore, productStore types.ProductStore, shopStore types.ShopStore, memberStore types.ShopMemberStore, userStore types.UserStore, geocoder types.Geocoder) *Handler {
+	return &Handler{
+		store:        store,
+		productStore: productStore,
+		shopStore:    shopStore,
+		memberStore:  memberStore,
+		userStore:    userStore,
+		geocoder:     geocoder,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/quote", auth.WithJWTAuth(h.handleQuote, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/methods", auth.WithJWTAuth(h.handleGetMethods, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/methods", auth.WithJWTAuth(h.handleCreateMethod, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/methods/{method_id}", auth.WithJWTAuth(h.handleGetMethod, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/methods/{method_id}", auth.WithJWTAuth(h.handleUpdateMethod, h.userStore)).Methods(http.MethodPut)
+	router.HandleFunc("/methods/{method_id}", auth.WithJWTAuth(h.handleDeleteMethod, h.userStore)).Methods(http.MethodDelete)
+}
+
+// handleGetMethods lists the shipping methods of a shop. Inactive methods are
+// only listed for the managers of the shop.
+func (h *Handler) handleGetMethods(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	shopID, err := strconv.Atoi(r.URL.Query().Get("shop_id"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing or invalid shop ID"))
+		return
+	}
+
+	shop, err := h.shopStore.GetShopByID(r.Context(), shopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("shop not found"))
+		return
+	}
+
+	visible, err := shopmember.CanViewShop(r.Context(), h.memberStore, h.userStore, shop, userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !visible {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("shop not found"))
+		return
+	}
+
+	methods, err := h.store.GetShippingMethodsByShopID(r.Context(), shopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	isManager, err := shopmember.HasRole(r.Context(), h.memberStore, shopID, userID, types.ShopRoleManager)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !isManager {
+		active := []types.ShippingMethod{}
+		for _, method := range methods {
+			if method.Active {
+				active = append(active, method)
+			}
+		}
+		methods = active
+	}
+
+	utils.WriteJSON(w, http.StatusOK, methods)
+}
+
+func (h *Handler) handleGetMethod(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	method, ok := h.getMethodFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	shop, err := h.shopStore.GetShopByID(r.Context(), method.ShopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("shipping method not found"))
+		return
+	}
+
+	visible, err := shopmember.CanViewShop(r.Context(), h.memberStore, h.userStore, shop, userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if visible && !method.Active {
+		visible, err = shopmember.HasRole(r.Context(), h.memberStore, method.ShopID, userID, types.ShopRoleManager)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if !visible {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("shipping method not found"))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, method)
+}
+
+func (h *Handler) handleCreateMethod(w http.ResponseWriter, r *http.Request) {
+	method, ok := parseMethod(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := h.shopStore.GetShopByID(r.Context(), method.ShopID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	allowed, err := shopmember.HasRole(r.Context(), h.memberStore, method.ShopID, auth.GetUserIDFromContext(r.Context()), types.ShopRoleManager)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !allowed {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you do not have permission to manage shipping of this shop"))
+		return
+	}
+
+	methodID, err := h.store.CreateShippingMethod(r.Context(), method)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	createdMethod, _ := h.store.GetShippingMethodByID(r.Context(), methodID)
+	utils.WriteJSON(w, http.StatusCreated, createdMethod)
+}
+
+func (h *Handler) handleUpdateMethod(w http.ResponseWriter, r *http.Request) {
+	existingMethod, ok := h.getManagedMethod(w, r)
+	if !ok {
+		return
+	}
+
+	method, ok := parseMethod(w, r)
+	if !ok {
+		return
+	}
+
+	// A method stays with the shop it was created for.
+	method.ShopID = existingMethod.ShopID
+
+	if err := h.store.UpdateShippingMethod(r.Context(), existingMethod.ID, method); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	updatedMethod, _ := h.store.GetShippingMethodByID(r.Context(), existingMethod.ID)
+	utils.WriteJSON(w, http.StatusOK, updatedMethod)
+}
+
+func (h *Handler) handleDeleteMethod(w http.ResponseWriter, r *http.Request) {
+	existingMethod, ok := h.getManagedMethod(w, r)
+	if !ok {
+		return
+	}
+
+	rowsAffected, err := h.store.DeleteShippingMethod(r.Context(), existingMethod.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to delete shipping method: %w", err))
+		return
+	}
+
+	if rowsAffected == 0 {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("shipping method not found"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleQuote returns the shipping options of every shop the items are
+// bought from.
+func (h *Handler) handleQuote(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var payload types.ShippingQuotePayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	shops := map[int]*types.Shop{}
+	quotes := []types.ShippingQuote{}
+	index := map[int]int{}
+	for _, item := range payload.Items {
+		product, err := h.productStore.GetProductByID(r.Context(), item.ProductID)
+		if err != nil {
+			utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product %d not found", item.ProductID))
+			return
+		}
+
+		if _, ok := shops[product.ShopID]; !ok {
+			shop, err := h.shopStore.GetShopByID(r.Context(), product.ShopID)
+			if err != nil {
+				utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product %d not found", item.ProductID))
+				return
+			}
+
+			visible, err := shopmember.CanViewShop(r.Context(), h.memberStore, h.userStore, shop, userID)
+			if err != nil {
+				utils.WriteError(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			if !visible {
+				utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product %d not found", item.ProductID))
+				return
+			}
+
+			shops[product.ShopID] = shop
+			index[product.ShopID] = len(quotes)
+			quotes = append(quotes, types.ShippingQuote{ShopID: product.ShopID})
+		}
+
+		quote := &quotes[index[product.ShopID]]
+		quote.WeightGrams += ChargeableWeight(*product) * int64(item.Quantity)
+		quote.SubtotalCents += product.PriceCents * int64(item.Quantity)
+	}
+
+	destination := payload.Destination
+	var location *types.GeoPoint
+	if destination.Latitude != nil && destination.Longitude != nil {
+		location = &types.GeoPoint{Latitude: *destination.Latitude, Longitude: *destination.Longitude}
+	}
+	geocoded := location != nil
+
+	for i := range quotes {
+		methods, err := h.store.GetShippingMethodsByShopID(r.Context(), quotes[i].ShopID)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		// Only look the destination up when a radius zone needs it.
+		if !geocoded && hasRadiusZone(methods) {
+			location = h.geocode(r.Context(), destination)
+			geocoded = true
+		}
+
+		quotes[i].Options = Quote(shops[quotes[i].ShopID], methods, Parcel{
+			WeightGrams:   quotes[i].WeightGrams,
+			SubtotalCents: quotes[i].SubtotalCents,
+			Country:       destination.Country,
+			PostalCode:    destination.PostalCode,
+			Location:      location,
+		})
+	}
+
+	utils.WriteJSON(w, http.StatusOK, quotes)
+}
+
+// geocode looks up the coordinates of the destination. Failures are logged
+// and leave radius zones out of the quote rather than failing it.
+func (h *Handler) geocode(ctx context.Context, destination types.ShippingDestinationPayload) *types.GeoPoint {
+	point, err := h.geocoder.Geocode(ctx, types.Address{
+		Street:     destination.Street,
+		City:       destination.City,
+		PostalCode: destination.PostalCode,
+		Country:    strings.ToUpper(destination.Country),
+	})
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to geocode shipping destination", "error", err)
+		return nil
+	}
+
+	return point
+}
+
+func (h *Handler) getMethodFromPath(w http.ResponseWriter, r *http.Request) (*types.ShippingMethod, bool) {
+	vars := mux.Vars(r)
+	str, ok := vars["method_id"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing shipping method ID"))
+		return nil, false
+	}
+
+	methodID, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid shipping method ID"))
+		return nil, false
+	}
+
+	method, err := h.store.GetShippingMethodByID(r.Context(), methodID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	return method, true
+}
+
+// getManagedMethod loads the method from the request path and makes sure the
+// authenticated user is an owner or manager of its shop.
+func (h *Handler) getManagedMethod(w http.ResponseWriter, r *http.Request) (*types.ShippingMethod, bool) {
+	method, ok := h.getMethodFromPath(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	allowed, err := shopmember.HasRole(r.Context(), h.memberStore, method.ShopID, auth.GetUserIDFromContext(r.Context()), types.ShopRoleManager)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	if !allowed {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you do not have permission to manage shipping of this shop"))
+		return nil, false
+	}
+
+	return method, true
+}
+
+func parseMethod(w http.ResponseWriter, r *http.Request) (types.CreateUpdateShippingMethodPayload, bool) {
+	var method types.CreateUpdateShippingMethodPayload
+	if err := utils.ParseJSON(r, &method); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return method, false
+	}
+
+	if err := utils.Validate.Struct(method); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return method, false
+	}
+
+	if err := ValidateMethod(method); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", err))
+		return method, false
+	}
+
+	if method.Active == nil {
+		active := true
+		method.Active = &active
+	}
+
+	return method, true
+}
+
+func hasRadiusZone(methods []types.ShippingMethod) bool {
+	for _, method := range methods {
+		for _, zone := range method.Zones {
+			if zone.Type == types.ShippingZoneRadius {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,231 @@
+package shipping
+
+import (
+	"context"
+	"database/sql"
+	"ecom_go/db"
+	"ecom_go/types"
+	"strings"
+)
+
+type Store struct {
+	db *db.DB
+}
+
+func NewStore(db *db.DB) *Store {
+	return &Store{db: db}
+}
+
+const shippingMethodColumns = "id, shop_id, name, type, rate_cents, free_over_cents, active, created_at, updated_at"
+
+func (s *Store) GetShippingMethodByID(ctx context.Context, methodID int) (*types.ShippingMethod, error) {
+	method, err := scanShippingMethod(s.db.QueryRowContext(ctx, "SELECT "+shippingMethodColumns+" FROM shipping_methods WHERE id = ?", methodID))
+	if err != nil {
+		return nil, types.NotFound("shipping method", err)
+	}
+
+	methods := []types.ShippingMethod{*method}
+	if err := s.attachTiersAndZones(ctx, methods); err != nil {
+		return nil, err
+	}
+
+	return &methods[0], nil
+}
+
+func (s *Store) GetShippingMethodsByShopID(ctx context.Context, shopID int) ([]types.ShippingMethod, error) {
+	return s.queryMethods(ctx, "SELECT "+shippingMethodColumns+" FROM shipping_methods WHERE shop_id = ? ORDER BY id", shopID)
+}
+
+func (s *Store) CreateShippingMethod(ctx context.Context, method types.CreateUpdateShippingMethodPayload) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	methodID, err := s.db.InsertTx(ctx, tx,
+		"INSERT INTO shipping_methods (shop_id, name, type, rate_cents, free_over_cents, active) VALUES (?, ?, ?, ?, ?, ?)",
+		method.ShopID, method.Name, method.Type, method.RateCents, method.FreeOverCents, method.Active)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := insertTiersAndZones(ctx, tx, int(methodID), method); err != nil {
+		return 0, err
+	}
+
+	return int(methodID), tx.Commit()
+}
+
+// UpdateShippingMethod replaces the method with its tiers and zones. Active
+// must be set.
+func (s *Store) UpdateShippingMethod(ctx context.Context, methodID int, method types.CreateUpdateShippingMethodPayload) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE shipping_methods SET name = ?, type = ?, rate_cents = ?, free_over_cents = ?, active = ? WHERE id = ?",
+		method.Name, method.Type, method.RateCents, method.FreeOverCents, method.Active, methodID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM shipping_rate_tiers WHERE method_id = ?", methodID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM shipping_zones WHERE method_id = ?", methodID); err != nil {
+		return err
+	}
+
+	if err := insertTiersAndZones(ctx, tx, methodID, method); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) DeleteShippingMethod(ctx context.Context, methodID int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM shipping_methods WHERE id = ?", methodID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (s *Store) queryMethods(ctx context.Context, query string, args ...any) ([]types.ShippingMethod, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	methods := []types.ShippingMethod{}
+	for rows.Next() {
+		method, err := scanShippingMethod(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		methods = append(methods, *method)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := s.attachTiersAndZones(ctx, methods); err != nil {
+		return nil, err
+	}
+
+	return methods, nil
+}
+
+func (s *Store) attachTiersAndZones(ctx context.Context, methods []types.ShippingMethod) error {
+	if len(methods) == 0 {
+		return nil
+	}
+
+	index := map[int]int{}
+	args := make([]any, len(methods))
+	for i := range methods {
+		methods[i].Tiers = []types.ShippingRateTier{}
+		methods[i].Zones = []types.ShippingZone{}
+		index[methods[i].ID] = i
+		args[i] = methods[i].ID
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+
+	tierRows, err := s.db.QueryContext(ctx, "SELECT id, method_id, min_value, rate_cents, created_at, updated_at FROM shipping_rate_tiers WHERE method_id IN ("+placeholders+") ORDER BY min_value", args...)
+	if err != nil {
+		return err
+	}
+	defer tierRows.Close()
+
+	for tierRows.Next() {
+		tier := types.ShippingRateTier{}
+		if err := tierRows.Scan(&tier.ID, &tier.MethodID, &tier.MinValue, &tier.RateCents, &tier.CreatedAt, &tier.UpdatedAt); err != nil {
+			return err
+		}
+
+		i := index[tier.MethodID]
+		methods[i].Tiers = append(methods[i].Tiers, tier)
+	}
+
+	if err := tierRows.Err(); err != nil {
+		return err
+	}
+	tierRows.Close()
+
+	zoneRows, err := s.db.QueryContext(ctx, "SELECT id, method_id, type, country, postal_from, postal_to, radius_km, created_at, updated_at FROM shipping_zones WHERE method_id IN ("+placeholders+") ORDER BY id", args...)
+	if err != nil {
+		return err
+	}
+	defer zoneRows.Close()
+
+	for zoneRows.Next() {
+		zone := types.ShippingZone{}
+		if err := zoneRows.Scan(
+			&zone.ID,
+			&zone.MethodID,
+			&zone.Type,
+			&zone.Country,
+			&zone.PostalFrom,
+			&zone.PostalTo,
+			&zone.RadiusKm,
+			&zone.CreatedAt,
+			&zone.UpdatedAt,
+		); err != nil {
+			return err
+		}
+
+		i := index[zone.MethodID]
+		methods[i].Zones = append(methods[i].Zones, zone)
+	}
+
+	return zoneRows.Err()
+}
+
+func insertTiersAndZones(ctx context.Context, tx *sql.Tx, methodID int, method types.CreateUpdateShippingMethodPayload) error {
+	for _, tier := range method.Tiers {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO shipping_rate_tiers (method_id, min_value, rate_cents) VALUES (?, ?, ?)",
+			methodID, tier.MinValue, tier.RateCents); err != nil {
+			return err
+		}
+	}
+
+	for _, zone := range method.Zones {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO shipping_zones (method_id, type, country, postal_from, postal_to, radius_km) VALUES (?, ?, ?, ?, ?, ?)",
+			methodID, zone.Type, zone.Country, zone.PostalFrom, zone.PostalTo, zone.RadiusKm); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func scanShippingMethod(row db.Scanner) (*types.ShippingMethod, error) {
+	method := new(types.ShippingMethod)
+
+	err := row.Scan(
+		&method.ID,
+		&method.ShopID,
+		&method.Name,
+		&method.Type,
+		&method.RateCents,
+		&method.FreeOverCents,
+		&method.Active,
+		&method.CreatedAt,
+		&method.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return method, nil
+}
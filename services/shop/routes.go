@@ -1,12 +1,20 @@
 package shop
 
 import (
+	"context"
+	"ecom_go/services/address"
 	"ecom_go/services/auth"
+	"ecom_go/services/imaging"
+	"ecom_go/services/logging"
+	"ecom_go/services/metrics"
+	"ecom_go/services/openinghours"
+	"ecom_go/services/shopmember"
 	"ecom_go/types"
 	"ecom_go/utils"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
@@ -16,26 +24,54 @@ type Handler struct {
 	store         types.ShopStore
 	categoryStore types.ShopCategoryStore
 	userStore     types.UserStore
+	imageStore    types.ImageStore
+	images        types.ImageProcessor
+	hoursStore    types.OpeningHoursStore
+	geocoder      types.Geocoder
+	memberStore   types.ShopMemberStore
+	notifier      types.Notifier
+	favoriteStore types.FavoriteStore
 }
 
-func NewHandler(store types.ShopStore, categoryStore types.ShopCategoryStore, userStore types.UserStore) *Handler {
+func NewHandler(store types.ShopStore, categoryStore types.ShopCategoryStore, userStore types.UserStore, imageStore types.ImageStore, images types.ImageProcessor, hoursStore types.OpeningHoursStore, geocoder types.Geocoder, memberStore types.ShopMemberStore, notifier types.Notifier, favoriteStore types.FavoriteStore) *Handler {
 	return &Handler{
 		store:         store,
 		categoryStore: categoryStore,
 		userStore:     userStore,
+		imageStore:    imageStore,
+		images:        images,
+		hoursStore:    hoursStore,
+		geocoder:      geocoder,
+		memberStore:   memberStore,
+		notifier:      notifier,
+		favoriteStore: favoriteStore,
 	}
 }
 
 func (h *Handler) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("", auth.WithJWTAuth(h.handleCreateShop, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/nearby", auth.WithJWTAuth(h.handleGetNearbyShops, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/admin/queue", auth.WithAdminJWTAuth(h.handleGetShopQueue, h.userStore)).Methods(http.MethodGet)
 	router.HandleFunc("/{shop_id}", auth.WithJWTAuth(h.handleGetShop, h.userStore)).Methods(http.MethodGet)
 	router.HandleFunc("/{shop_id}", auth.WithJWTAuth(h.handleUpdateShop, h.userStore)).Methods(http.MethodPut)
 	router.HandleFunc("/{shop_id}", auth.WithJWTAuth(h.handleDeleteShop, h.userStore)).Methods(http.MethodDelete)
 
+	router.HandleFunc("/{shop_id}/submit", auth.WithJWTAuth(h.handleSubmitShop, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/{shop_id}/approve", auth.WithAdminJWTAuth(h.handleApproveShop, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/{shop_id}/reject", auth.WithAdminJWTAuth(h.handleRejectShop, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/{shop_id}/suspend", auth.WithAdminJWTAuth(h.handleSuspendShop, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/{shop_id}/restore", auth.WithAdminJWTAuth(h.handleRestoreShop, h.userStore)).Methods(http.MethodPost)
+
+	router.HandleFunc("/{shop_id}/hours", auth.WithJWTAuth(h.handleGetShopHours, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/{shop_id}/hours", auth.WithJWTAuth(h.handleUpdateShopHours, h.userStore)).Methods(http.MethodPut)
+	router.HandleFunc("/{shop_id}/hours/exceptions", auth.WithJWTAuth(h.handleCreateShopHoursException, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/{shop_id}/hours/exceptions/{exception_id}", auth.WithJWTAuth(h.handleDeleteShopHoursException, h.userStore)).Methods(http.MethodDelete)
+
 	router.HandleFunc("/category", auth.WithAdminJWTAuth(h.handleCreateShopCategory, h.userStore)).Methods(http.MethodPost)
 	router.HandleFunc("/category/{category_id}", auth.WithAdminJWTAuth(h.handleGetShopCategory, h.userStore)).Methods(http.MethodGet)
 	router.HandleFunc("/category/{category_id}", auth.WithAdminJWTAuth(h.handleUpdateShopCategory, h.userStore)).Methods(http.MethodPut)
 	router.HandleFunc("/category/{category_id}", auth.WithAdminJWTAuth(h.handleDeleteShopCategory, h.userStore)).Methods(http.MethodDelete)
+	router.HandleFunc("/category/{category_id}/restore", auth.WithAdminJWTAuth(h.handleRestoreShopCategory, h.userStore)).Methods(http.MethodPost)
 }
 
 func (h *Handler) handleGetShopCategory(w http.ResponseWriter, r *http.Request) {
@@ -52,7 +88,7 @@ func (h *Handler) handleGetShopCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	category, err := h.categoryStore.GetShopCategoryByID(categoryID)
+	category, err := h.categoryStore.GetShopCategoryByID(r.Context(), categoryID)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -74,7 +110,7 @@ func (h *Handler) handleCreateShopCategory(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	err := h.categoryStore.CreateShopCategory(shopCategory)
+	err := h.categoryStore.CreateShopCategory(r.Context(), shopCategory)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -99,9 +135,9 @@ func (h *Handler) handleUpdateShopCategory(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	existingCategory, err := h.categoryStore.GetShopCategoryByID(categoryID)
+	existingCategory, err := h.categoryStore.GetShopCategoryByID(r.Context(), categoryID)
 	if err != nil {
-		utils.WriteError(w, http.StatusNotFound, err)
+		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -116,12 +152,12 @@ func (h *Handler) handleUpdateShopCategory(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	if err := h.categoryStore.UpdateShopCategory(existingCategory.ID, shopCategory); err != nil {
+	if err := h.categoryStore.UpdateShopCategory(r.Context(), existingCategory.ID, shopCategory); err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	updatedCategory, _ := h.categoryStore.GetShopCategoryByID(existingCategory.ID)
+	updatedCategory, _ := h.categoryStore.GetShopCategoryByID(r.Context(), existingCategory.ID)
 
 	utils.WriteJSON(w, http.StatusOK, updatedCategory)
 }
@@ -140,15 +176,15 @@ func (h *Handler) handleDeleteShopCategory(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	existingCategory, err := h.categoryStore.GetShopCategoryByID(categoryID)
+	existingCategory, err := h.categoryStore.GetShopCategoryByID(r.Context(), categoryID)
 	if err != nil {
-		utils.WriteError(w, http.StatusNotFound, err)
+		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	rowsAffected, err := h.categoryStore.DeleteShopCategory(existingCategory.ID)
+	rowsAffected, err := h.categoryStore.DeleteShopCategory(r.Context(), existingCategory.ID)
 	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to delete shop category: %v", err))
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to delete shop category: %w", err))
 		return
 	}
 
@@ -161,6 +197,78 @@ func (h *Handler) handleDeleteShopCategory(w http.ResponseWriter, r *http.Reques
 
 }
 
+// normalizeAddress normalizes the postal address of a shop in place. The
+// postal code is checked against the format of the country when both are set.
+func normalizeAddress(street, city, postalCode, country *string) error {
+	normalized := address.Normalize(shopAddress(street, city, postalCode, country))
+
+	if street != nil {
+		*street = normalized.Street
+	}
+	if city != nil {
+		*city = normalized.City
+	}
+	if country != nil {
+		*country = normalized.Country
+	}
+	if postalCode != nil {
+		*postalCode = normalized.PostalCode
+
+		if country != nil {
+			return address.ValidatePostalCode(normalized.Country, normalized.PostalCode)
+		}
+	}
+
+	return nil
+}
+
+func shopAddress(street, city, postalCode, country *string) types.Address {
+	location := types.Address{}
+	if street != nil {
+		location.Street = *street
+	}
+	if city != nil {
+		location.City = *city
+	}
+	if postalCode != nil {
+		location.PostalCode = *postalCode
+	}
+	if country != nil {
+		location.Country = *country
+	}
+
+	return location
+}
+
+func (h *Handler) handleRestoreShopCategory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	str, ok := vars["category_id"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing shop category ID"))
+		return
+	}
+
+	categoryID, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid shop category ID"))
+		return
+	}
+
+	rowsAffected, err := h.categoryStore.RestoreShopCategory(r.Context(), categoryID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to restore shop category: %w", err))
+		return
+	}
+
+	if rowsAffected == 0 {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("deleted shop category not found"))
+		return
+	}
+
+	restored, _ := h.categoryStore.GetShopCategoryByID(r.Context(), categoryID)
+	utils.WriteJSON(w, http.StatusOK, restored)
+}
+
 func (h *Handler) handleGetShop(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	str, ok := vars["shop_id"]
@@ -175,40 +283,143 @@ func (h *Handler) handleGetShop(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	shop, err := h.store.GetShopByID(shopID)
+	shop, err := h.store.GetShopByID(r.Context(), shopID)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 
+	visible, err := shopmember.CanViewShop(r.Context(), h.memberStore, h.userStore, shop, auth.GetUserIDFromContext(r.Context()))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !visible {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("shop not found"))
+		return
+	}
+
+	h.attachImages(r.Context(), shop)
+	h.attachAvailability(r.Context(), shop)
+	h.attachFollowed(r.Context(), auth.GetUserIDFromContext(r.Context()), shop)
+
 	utils.WriteJSON(w, http.StatusOK, shop)
 }
 
+func (h *Handler) handleGetNearbyShops(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	lat, err := strconv.ParseFloat(query.Get("lat"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid latitude"))
+		return
+	}
+
+	lng, err := strconv.ParseFloat(query.Get("lng"), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid longitude"))
+		return
+	}
+
+	radiusKm := defaultNearbyRadiusKm
+	if str := query.Get("radius_km"); str != "" {
+		radiusKm, err = strconv.ParseFloat(str, 64)
+		if err != nil || radiusKm <= 0 || radiusKm > maxNearbyRadiusKm {
+			utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("radius_km must be between 0 and %v", maxNearbyRadiusKm))
+			return
+		}
+	}
+
+	limit := defaultNearbyLimit
+	if str := query.Get("limit"); str != "" {
+		limit, err = strconv.Atoi(str)
+		if err != nil || limit <= 0 || limit > maxNearbyLimit {
+			utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("limit must be between 1 and %d", maxNearbyLimit))
+			return
+		}
+	}
+
+	shops, err := h.store.GetNearbyShops(r.Context(), types.GeoPoint{Latitude: lat, Longitude: lng}, radiusKm, limit)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	followed := make([]*types.Shop, len(shops))
+	for i := range shops {
+		h.attachImages(r.Context(), &shops[i].Shop)
+		followed[i] = &shops[i].Shop
+	}
+	h.attachFollowed(r.Context(), auth.GetUserIDFromContext(r.Context()), followed...)
+
+	utils.WriteJSON(w, http.StatusOK, shops)
+}
+
+const (
+	defaultNearbyRadiusKm = 5.0
+	maxNearbyRadiusKm     = 100.0
+	defaultNearbyLimit    = 20
+	maxNearbyLimit        = 100
+)
+
 func (h *Handler) handleCreateShop(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserIDFromContext(r.Context())
+	if userID == -1 {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("unauthorized"))
+		return
+	}
+
 	var shop types.CreateShopPayload
 	if err := utils.ParseJSON(r, &shop); err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
 
+	shop.UserID = userID
+
 	if err := utils.Validate.Struct(shop); err != nil {
 		errors := err.(validator.ValidationErrors)
 		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
 		return
 	}
 
-	if _, err := h.categoryStore.GetShopCategoryByID(shop.CategoryID); err != nil {
-		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("invalid payload: %v", err))
+	if _, err := h.categoryStore.GetShopCategoryByID(r.Context(), shop.CategoryID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("invalid payload: %w", err))
+		return
+	}
+
+	if shop.Timezone == "" {
+		shop.Timezone = "UTC"
+	}
+	if shop.Allocation == "" {
+		shop.Allocation = types.AllocationHighestStock
+	}
+
+	if err := normalizeAddress(shop.Street, shop.City, shop.PostalCode, shop.Country); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", err))
 		return
 	}
 
-	err := h.store.CreateShop(shop)
+	if shop.Latitude == nil {
+		if point := h.geocode(r.Context(), shop.Street, shop.City, shop.PostalCode, shop.Country); point != nil {
+			shop.Latitude = &point.Latitude
+			shop.Longitude = &point.Longitude
+		}
+	}
+
+	shopID, err := h.store.CreateShop(r.Context(), shop)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	utils.WriteJSON(w, http.StatusOK, shop)
+	metrics.ShopsCreated.Inc()
+
+	h.images.Enqueue(shop.Image)
+
+	createdShop, _ := h.store.GetShopByID(r.Context(), shopID)
+	utils.WriteJSON(w, http.StatusOK, createdShop)
 }
 
 func (h *Handler) handleUpdateShop(w http.ResponseWriter, r *http.Request) {
@@ -233,13 +444,19 @@ func (h *Handler) handleUpdateShop(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	existingShop, err := h.store.GetShopByID(shopID)
+	existingShop, err := h.store.GetShopByID(r.Context(), shopID)
 	if err != nil {
-		utils.WriteError(w, http.StatusNotFound, err)
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	allowed, err := shopmember.HasRole(r.Context(), h.memberStore, existingShop.ID, userID, types.ShopRoleManager)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	if existingShop.UserID != userID {
+	if !allowed {
 		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you do not have permission to modify this shop"))
 		return
 	}
@@ -256,9 +473,9 @@ func (h *Handler) handleUpdateShop(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if shop.CategoryID != nil {
-		_, err := h.categoryStore.GetShopCategoryByID(*shop.CategoryID)
+		_, err := h.categoryStore.GetShopCategoryByID(r.Context(), *shop.CategoryID)
 		if err != nil {
-			utils.WriteError(w, http.StatusNotFound, fmt.Errorf("shop category not found"))
+			utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("invalid payload: %w", err))
 			return
 		}
 	}
@@ -284,14 +501,59 @@ func (h *Handler) handleUpdateShop(w http.ResponseWriter, r *http.Request) {
 	if shop.Image == nil {
 		shop.Image = &existingShop.Image
 	}
+	if shop.Timezone == nil {
+		shop.Timezone = &existingShop.Timezone
+	}
+	if shop.Allocation == nil {
+		shop.Allocation = &existingShop.Allocation
+	}
 
-	err = h.store.UpdateShop(shopID, shop)
+	addressChanged := shop.Street != nil || shop.City != nil || shop.PostalCode != nil || shop.Country != nil
+	if shop.Street == nil {
+		shop.Street = existingShop.Street
+	}
+	if shop.City == nil {
+		shop.City = existingShop.City
+	}
+	if shop.PostalCode == nil {
+		shop.PostalCode = existingShop.PostalCode
+	}
+	if shop.Country == nil {
+		shop.Country = existingShop.Country
+	}
+
+	if addressChanged {
+		if err := normalizeAddress(shop.Street, shop.City, shop.PostalCode, shop.Country); err != nil {
+			utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", err))
+			return
+		}
+	}
+	if shop.Latitude == nil {
+		shop.Latitude = existingShop.Latitude
+		shop.Longitude = existingShop.Longitude
+
+		if addressChanged {
+			if point := h.geocode(r.Context(), shop.Street, shop.City, shop.PostalCode, shop.Country); point != nil {
+				shop.Latitude = &point.Latitude
+				shop.Longitude = &point.Longitude
+			}
+		}
+	}
+
+	err = h.store.UpdateShop(r.Context(), shopID, shop)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	updatedShop, _ := h.store.GetShopByID(shopID)
+	if *shop.Image != existingShop.Image {
+		h.images.Enqueue(*shop.Image)
+	}
+
+	updatedShop, _ := h.store.GetShopByID(r.Context(), shopID)
+	h.attachImages(r.Context(), updatedShop)
+	h.attachAvailability(r.Context(), updatedShop)
+	h.attachFollowed(r.Context(), auth.GetUserIDFromContext(r.Context()), updatedShop)
 	utils.WriteJSON(w, http.StatusOK, updatedShop)
 }
 
@@ -315,20 +577,26 @@ func (h *Handler) handleDeleteShop(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	existingShop, err := h.store.GetShopByID(shopID)
+	existingShop, err := h.store.GetShopByID(r.Context(), shopID)
 	if err != nil {
-		utils.WriteError(w, http.StatusNotFound, err)
+		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	if existingShop.UserID != userID {
-		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you do not have permission to modify this shop"))
+	allowed, err := shopmember.HasRole(r.Context(), h.memberStore, existingShop.ID, userID, types.ShopRoleOwner)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	rowsAffected, err := h.store.DeleteShop(shopID)
+	if !allowed {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you do not have permission to delete this shop"))
+		return
+	}
+
+	rowsAffected, err := h.store.DeleteShop(r.Context(), shopID)
 	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to delete shop: %v", err))
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to delete shop: %w", err))
 		return
 	}
 
@@ -339,3 +607,473 @@ func (h *Handler) handleDeleteShop(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *Handler) attachImages(ctx context.Context, shop *types.Shop) {
+	if shop == nil || shop.Image == "" {
+		return
+	}
+
+	variants, err := h.imageStore.GetImageVariants(ctx, shop.Image)
+	if err != nil {
+		return
+	}
+
+	shop.Images = imaging.NewImageSet(variants)
+}
+
+func (h *Handler) handleGetShopHours(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	str, ok := vars["shop_id"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing shop ID"))
+		return
+	}
+
+	shopID, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid shop ID"))
+		return
+	}
+
+	shop, err := h.store.GetShopByID(r.Context(), shopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	visible, err := shopmember.CanViewShop(r.Context(), h.memberStore, h.userStore, shop, auth.GetUserIDFromContext(r.Context()))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !visible {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("shop not found"))
+		return
+	}
+
+	hours, err := h.getShopHours(r.Context(), shop, time.Now())
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, hours)
+}
+
+func (h *Handler) handleUpdateShopHours(w http.ResponseWriter, r *http.Request) {
+	shop, ok := h.getManagedShop(w, r)
+	if !ok {
+		return
+	}
+
+	var payload types.UpdateOpeningHoursPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	if err := h.hoursStore.ReplaceOpeningHours(r.Context(), shop.ID, payload.Hours); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	hours, err := h.getShopHours(r.Context(), shop, time.Now())
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, hours)
+}
+
+func (h *Handler) handleCreateShopHoursException(w http.ResponseWriter, r *http.Request) {
+	shop, ok := h.getManagedShop(w, r)
+	if !ok {
+		return
+	}
+
+	var payload types.CreateShopHoursExceptionPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	if err := h.hoursStore.CreateShopHoursException(r.Context(), shop.ID, payload); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, payload)
+}
+
+func (h *Handler) handleDeleteShopHoursException(w http.ResponseWriter, r *http.Request) {
+	shop, ok := h.getManagedShop(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	str, ok := vars["exception_id"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing exception ID"))
+		return
+	}
+
+	exceptionID, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid exception ID"))
+		return
+	}
+
+	rowsAffected, err := h.hoursStore.DeleteShopHoursException(r.Context(), shop.ID, exceptionID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to delete hours exception: %w", err))
+		return
+	}
+
+	if rowsAffected == 0 {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("hours exception not found"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getManagedShop loads the shop from the request path and makes sure the
+// authenticated user is its owner or a manager, writing an error response
+// otherwise.
+func (h *Handler) getManagedShop(w http.ResponseWriter, r *http.Request) (*types.Shop, bool) {
+	userID := auth.GetUserIDFromContext(r.Context())
+	if userID == -1 {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("unauthorized"))
+		return nil, false
+	}
+
+	shop, ok := h.getShopFromPath(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	allowed, err := shopmember.HasRole(r.Context(), h.memberStore, shop.ID, userID, types.ShopRoleManager)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	if !allowed {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you do not have permission to modify this shop"))
+		return nil, false
+	}
+
+	return shop, true
+}
+
+func (h *Handler) getShopHours(ctx context.Context, shop *types.Shop, now time.Time) (*types.ShopHours, error) {
+	hours, err := h.hoursStore.GetOpeningHours(ctx, shop.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start two days back so exceptions still apply to overnight intervals
+	// regardless of the shop's offset from UTC.
+	exceptions, err := h.hoursStore.GetShopHoursExceptions(ctx, shop.ID, now.AddDate(0, 0, -2))
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := openinghours.NewSchedule(shop.Timezone, hours, exceptions)
+	if err != nil {
+		return nil, err
+	}
+
+	shopHours := &types.ShopHours{
+		Timezone:   shop.Timezone,
+		Hours:      hours,
+		Exceptions: exceptions,
+		IsOpenNow:  schedule.IsOpen(now),
+	}
+	if next, ok := schedule.NextOpening(now); ok {
+		shopHours.NextOpening = &next
+	}
+
+	return shopHours, nil
+}
+
+func (h *Handler) attachAvailability(ctx context.Context, shop *types.Shop) {
+	if shop == nil {
+		return
+	}
+
+	hours, err := h.getShopHours(ctx, shop, time.Now())
+	if err != nil {
+		return
+	}
+
+	shop.IsOpenNow = hours.IsOpenNow
+	shop.NextOpening = hours.NextOpening
+}
+
+// attachFollowed sets whether the user follows each of the shops.
+func (h *Handler) attachFollowed(ctx context.Context, userID int, shops ...*types.Shop) {
+	shopIDs := []int{}
+	for _, shop := range shops {
+		if shop != nil {
+			shopIDs = append(shopIDs, shop.ID)
+		}
+	}
+
+	followed, err := h.favoriteStore.GetFavoriteShopSet(ctx, userID, shopIDs)
+	if err != nil {
+		return
+	}
+
+	for _, shop := range shops {
+		if shop != nil {
+			isFollowed := followed[shop.ID]
+			shop.IsFollowed = &isFollowed
+		}
+	}
+}
+
+// geocode looks up coordinates for a shop address. Failures are logged and
+// leave the shop without coordinates rather than failing the request.
+func (h *Handler) geocode(ctx context.Context, street, city, postalCode, country *string) *types.GeoPoint {
+	location := shopAddress(street, city, postalCode, country)
+	if location.City == "" && location.PostalCode == "" {
+		return nil
+	}
+
+	point, err := h.geocoder.Geocode(ctx, location)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to geocode shop address", "error", err)
+		return nil
+	}
+
+	return point
+}
+
+func (h *Handler) handleRestoreShop(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	str, ok := vars["shop_id"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing shop ID"))
+		return
+	}
+
+	shopID, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid shop ID"))
+		return
+	}
+
+	rowsAffected, err := h.store.RestoreShop(r.Context(), shopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to restore shop: %w", err))
+		return
+	}
+
+	if rowsAffected == 0 {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("deleted shop not found"))
+		return
+	}
+
+	restored, _ := h.store.GetShopByID(r.Context(), shopID)
+	utils.WriteJSON(w, http.StatusOK, restored)
+}
+
+func (h *Handler) handleGetShopQueue(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = types.ShopStatusPending
+	}
+
+	switch status {
+	case types.ShopStatusPending, types.ShopStatusApproved, types.ShopStatusRejected, types.ShopStatusSuspended:
+	default:
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid status %q", status))
+		return
+	}
+
+	shops, err := h.store.GetShopsByStatus(r.Context(), status)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, shops)
+}
+
+// handleSubmitShop lets the owner send a rejected shop back to the review
+// queue after addressing the rejection reason.
+func (h *Handler) handleSubmitShop(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserIDFromContext(r.Context())
+	if userID == -1 {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("unauthorized"))
+		return
+	}
+
+	shop, ok := h.getShopFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	allowed, err := shopmember.HasRole(r.Context(), h.memberStore, shop.ID, userID, types.ShopRoleOwner)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !allowed {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you do not have permission to submit this shop"))
+		return
+	}
+
+	h.changeShopStatus(r.Context(), w, shop, []string{types.ShopStatusRejected}, types.ShopStatusPending, nil, nil)
+}
+
+func (h *Handler) handleApproveShop(w http.ResponseWriter, r *http.Request) {
+	shop, ok := h.getShopFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	reviewerID := auth.GetUserIDFromContext(r.Context())
+	h.changeShopStatus(r.Context(), w, shop, []string{types.ShopStatusPending, types.ShopStatusSuspended}, types.ShopStatusApproved, nil, &reviewerID)
+}
+
+func (h *Handler) handleRejectShop(w http.ResponseWriter, r *http.Request) {
+	shop, ok := h.getShopFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	reason, ok := parseReviewReason(w, r)
+	if !ok {
+		return
+	}
+
+	reviewerID := auth.GetUserIDFromContext(r.Context())
+	h.changeShopStatus(r.Context(), w, shop, []string{types.ShopStatusPending}, types.ShopStatusRejected, &reason, &reviewerID)
+}
+
+func (h *Handler) handleSuspendShop(w http.ResponseWriter, r *http.Request) {
+	shop, ok := h.getShopFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	reason, ok := parseReviewReason(w, r)
+	if !ok {
+		return
+	}
+
+	reviewerID := auth.GetUserIDFromContext(r.Context())
+	h.changeShopStatus(r.Context(), w, shop, []string{types.ShopStatusApproved}, types.ShopStatusSuspended, &reason, &reviewerID)
+}
+
+// changeShopStatus moves the shop to status if its current status is one of
+// from, then notifies the owner about the change.
+func (h *Handler) changeShopStatus(ctx context.Context, w http.ResponseWriter, shop *types.Shop, from []string, status string, reason *string, reviewerID *int) {
+	allowed := false
+	for _, s := range from {
+		if shop.Status == s {
+			allowed = true
+		}
+	}
+
+	if !allowed {
+		utils.WriteError(w, http.StatusConflict, fmt.Errorf("cannot change shop status from %s to %s", shop.Status, status))
+		return
+	}
+
+	rowsAffected, err := h.store.UpdateShopStatus(ctx, shop.ID, from, status, reason, reviewerID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Another request changed the status since the shop was read.
+	if rowsAffected == 0 {
+		utils.WriteError(w, http.StatusConflict, fmt.Errorf("cannot change shop status to %s, it was changed in the meantime", status))
+		return
+	}
+
+	updatedShop, err := h.store.GetShopByID(ctx, shop.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.notifyOwner(ctx, updatedShop)
+
+	utils.WriteJSON(w, http.StatusOK, updatedShop)
+}
+
+func (h *Handler) notifyOwner(ctx context.Context, shop *types.Shop) {
+	owner, err := h.userStore.GetUserByID(ctx, shop.UserID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to load shop owner", "shop_id", shop.ID, "error", err)
+		return
+	}
+
+	body := fmt.Sprintf("The status of your shop %s is now %s.", shop.Name, shop.Status)
+	if shop.StatusReason != nil && *shop.StatusReason != "" {
+		body += fmt.Sprintf("\n\nReason: %s", *shop.StatusReason)
+	}
+
+	if err := h.notifier.Notify(owner.Email, fmt.Sprintf("Your shop %s is %s", shop.Name, shop.Status), body); err != nil {
+		logging.FromContext(ctx).Error("failed to notify shop owner", "shop_id", shop.ID, "error", err)
+	}
+}
+
+func (h *Handler) getShopFromPath(w http.ResponseWriter, r *http.Request) (*types.Shop, bool) {
+	vars := mux.Vars(r)
+	str, ok := vars["shop_id"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing shop ID"))
+		return nil, false
+	}
+
+	shopID, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid shop ID"))
+		return nil, false
+	}
+
+	shop, err := h.store.GetShopByID(r.Context(), shopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	return shop, true
+}
+
+func parseReviewReason(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var payload types.ShopReviewPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return "", false
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return "", false
+	}
+
+	return payload.Reason, true
+}
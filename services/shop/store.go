@@ -1,61 +1,210 @@
 package shop
 
 import (
+	"context"
 	"database/sql"
+	"ecom_go/db"
+	"ecom_go/services/geo"
 	"ecom_go/types"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 )
 
+const defaultLocationName = "Main"
+
 type Store struct {
-	db *sql.DB
+	db *db.DB
 }
 
-func NewStore(db *sql.DB) *Store {
+func NewStore(db *db.DB) *Store {
 	return &Store{db: db}
 }
 
-func (s *Store) GetShopByID(shopID int) (*types.Shop, error) {
-	rows, err := s.db.Query("SELECT * FROM shops WHERE id = ?", shopID)
+const shopColumns = `id, user_id, name, description, category_id, opens_at, closes_at, address, street, city, postal_code, country,
+	latitude, longitude, image, timezone, allocation_strategy, status, status_reason, reviewed_by, reviewed_at,
+	rating_average, rating_count, created_at, updated_at, deleted_at`
+
+func (s *Store) GetShopByID(ctx context.Context, shopID int) (*types.Shop, error) {
+	shop, err := scanShop(s.db.QueryRowContext(ctx, "SELECT "+shopColumns+" FROM shops WHERE id = ? AND deleted_at IS NULL", shopID))
+	if err != nil {
+		return nil, types.NotFound("shop", err)
+	}
+
+	return shop, nil
+}
+
+func (s *Store) GetShopsByStatus(ctx context.Context, status string) ([]types.Shop, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+shopColumns+" FROM shops WHERE status = ? AND deleted_at IS NULL ORDER BY created_at", status)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	shop := new(types.Shop)
+	shops := []types.Shop{}
 	for rows.Next() {
-		shop, err = scanRowsIntoShop(rows)
+		shop, err := scanShop(rows)
 		if err != nil {
 			return nil, err
 		}
+
+		shops = append(shops, *shop)
 	}
 
-	if shop.ID == 0 {
-		return nil, fmt.Errorf("shop not found")
+	return shops, rows.Err()
+}
+
+// GetNearbyShops returns approved shops within radiusKm of point ordered by distance.
+// The bounding boxes let MySQL use the spatial index on shops.location before
+// computing exact distances.
+func (s *Store) GetNearbyShops(ctx context.Context, point types.GeoPoint, radiusKm float64, limit int) ([]types.NearbyShop, error) {
+	boxes := geo.BoundingBoxes(point, radiusKm)
+	if s.db.Dialect() != db.MySQL {
+		return s.getNearbyShopsInBoxes(ctx, point, boxes, radiusKm, limit)
 	}
 
-	return shop, nil
+	within := make([]string, len(boxes))
+	args := []any{point.Longitude, point.Latitude, types.ShopStatusApproved}
+	for i, box := range boxes {
+		within[i] = "MBRContains(ST_GeomFromText(?), location)"
+		args = append(args, fmt.Sprintf("POLYGON((%[1]f %[2]f, %[3]f %[2]f, %[3]f %[4]f, %[1]f %[4]f, %[1]f %[2]f))",
+			box.SW.Longitude, box.SW.Latitude, box.NE.Longitude, box.NE.Latitude))
+	}
+	args = append(args, radiusKm, limit)
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+shopColumns+`, ST_Distance_Sphere(location, POINT(?, ?)) / 1000 AS distance_km
+		FROM shops
+		WHERE status = ? AND deleted_at IS NULL AND latitude IS NOT NULL AND longitude IS NOT NULL AND (`+strings.Join(within, " OR ")+`)
+		HAVING distance_km <= ?
+		ORDER BY distance_km
+		LIMIT ?`,
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shops := []types.NearbyShop{}
+	for rows.Next() {
+		var distance float64
+		shop, err := scanShop(rows, &distance)
+		if err != nil {
+			return nil, err
+		}
+
+		shops = append(shops, types.NearbyShop{Shop: *shop, DistanceKm: distance})
+	}
+
+	return shops, rows.Err()
 }
 
-func (s *Store) CreateShop(shop types.CreateShopPayload) error {
-	_, err := s.db.Exec(
-		"INSERT INTO shops (user_id, name, description, category_id, opens_at, closes_at, address, image) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
-		shop.UserID, shop.Name, shop.Description, shop.CategoryID, shop.Opens_at, shop.Closes_at, shop.Address, shop.Image)
+// getNearbyShopsInBoxes finds nearby shops without spatial functions, which
+// PostgreSQL and SQLite lack: it loads the shops in the bounding boxes and
+// computes their distances itself.
+func (s *Store) getNearbyShopsInBoxes(ctx context.Context, point types.GeoPoint, boxes []geo.Box, radiusKm float64, limit int) ([]types.NearbyShop, error) {
+	// The boxes only differ in longitude.
+	within := make([]string, len(boxes))
+	args := []any{types.ShopStatusApproved, boxes[0].SW.Latitude, boxes[0].NE.Latitude}
+	for i, box := range boxes {
+		within[i] = "longitude BETWEEN ? AND ?"
+		args = append(args, box.SW.Longitude, box.NE.Longitude)
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+shopColumns+` FROM shops
+		WHERE status = ? AND deleted_at IS NULL AND latitude BETWEEN ? AND ? AND (`+strings.Join(within, " OR ")+`)`,
+		args...)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer rows.Close()
+
+	shops := []types.NearbyShop{}
+	for rows.Next() {
+		shop, err := scanShop(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		distance := geo.DistanceKm(point, types.GeoPoint{Latitude: *shop.Latitude, Longitude: *shop.Longitude})
+		if distance <= radiusKm {
+			shops = append(shops, types.NearbyShop{Shop: *shop, DistanceKm: distance})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return nil
+	sort.SliceStable(shops, func(i, j int) bool { return shops[i].DistanceKm < shops[j].DistanceKm })
+
+	return shops[:min(len(shops), limit)], nil
 }
 
-func (s *Store) UpdateShop(shopID int, shop types.UpdateShopPayload) error {
-	_, err := s.db.Exec(
-		"UPDATE shops SET name = ?, description = ?, category_id = ?, opens_at = ?, closes_at = ?, address = ?, image = ? WHERE id = ?",
-		shop.Name, shop.Description, shop.CategoryID, shop.Opens_at, shop.Closes_at, shop.Address, shop.Image, shopID)
+// CreateShop inserts the shop and registers its creator as the owner member.
+func (s *Store) CreateShop(ctx context.Context, shop types.CreateShopPayload) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	shopID, err := s.db.InsertTx(ctx, tx,
+		"INSERT INTO shops (user_id, name, description, category_id, opens_at, closes_at, address, street, city, postal_code, country, latitude, longitude, image, timezone, allocation_strategy) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		shop.UserID, shop.Name, shop.Description, shop.CategoryID, shop.Opens_at, shop.Closes_at, shop.Address,
+		shop.Street, shop.City, shop.PostalCode, shop.Country, shop.Latitude, shop.Longitude, shop.Image, shop.Timezone, shop.Allocation)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO shop_members (shop_id, user_id, role) VALUES (?, ?, ?)",
+		shopID, shop.UserID, types.ShopRoleOwner); err != nil {
+		return 0, err
+	}
+
+	// Every shop starts with a default stock location at its own address.
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO stock_locations (shop_id, name, street, city, postal_code, country, latitude, longitude, is_default) VALUES (?, ?, ?, ?, ?, ?, ?, ?, TRUE)",
+		shopID, defaultLocationName, valueOrEmpty(shop.Street), valueOrEmpty(shop.City), valueOrEmpty(shop.PostalCode), valueOrEmpty(shop.Country),
+		shop.Latitude, shop.Longitude); err != nil {
+		return 0, err
+	}
+
+	return int(shopID), tx.Commit()
+}
+
+func (s *Store) UpdateShop(ctx context.Context, shopID int, shop types.UpdateShopPayload) error {
+	_, err := s.db.ExecContext(ctx,
+		"UPDATE shops SET name = ?, description = ?, category_id = ?, opens_at = ?, closes_at = ?, address = ?, street = ?, city = ?, postal_code = ?, country = ?, latitude = ?, longitude = ?, image = ?, timezone = ?, allocation_strategy = ? WHERE id = ? AND deleted_at IS NULL",
+		shop.Name, shop.Description, shop.CategoryID, shop.Opens_at, shop.Closes_at, shop.Address,
+		shop.Street, shop.City, shop.PostalCode, shop.Country, shop.Latitude, shop.Longitude, shop.Image, shop.Timezone, shop.Allocation, shopID)
 
 	return err
 }
 
-func (s *Store) DeleteShop(shopID int) (int64, error) {
-	result, err := s.db.Exec("DELETE FROM shops WHERE id = ?", shopID)
+// UpdateShopStatus changes the status of the shop if it is still in one of
+// the from statuses, so that concurrent changes cannot both apply. It returns
+// the number of shops changed.
+func (s *Store) UpdateShopStatus(ctx context.Context, shopID int, from []string, status string, reason *string, reviewerID *int) (int64, error) {
+	args := []any{status, reason, reviewerID, time.Now().UTC(), shopID}
+	for _, f := range from {
+		args = append(args, f)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(from)), ", ")
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE shops SET status = ?, status_reason = ?, reviewed_by = ?, reviewed_at = ? WHERE id = ? AND deleted_at IS NULL AND status IN ("+placeholders+")",
+		args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (s *Store) DeleteShop(ctx context.Context, shopID int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "UPDATE shops SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL", time.Now().UTC(), shopID)
 	if err != nil {
 		return 0, err
 	}
@@ -68,25 +217,68 @@ func (s *Store) DeleteShop(shopID int) (int64, error) {
 	return rowsAffected, nil
 }
 
-func scanRowsIntoShop(rows *sql.Rows) (*types.Shop, error) {
+func (s *Store) RestoreShop(ctx context.Context, shopID int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "UPDATE shops SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL", shopID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// scanShop reads a row of shopColumns followed by the extra columns. The
+// text columns shops were created without are read as empty strings.
+func scanShop(row db.Scanner, extra ...any) (*types.Shop, error) {
 	shop := new(types.Shop)
 
-	err := rows.Scan(
+	var description, opensAt, closesAt, address, image sql.NullString
+	dest := []any{
 		&shop.ID,
 		&shop.UserID,
 		&shop.Name,
-		&shop.Description,
+		&description,
 		&shop.CategoryID,
-		&shop.Opens_at,
-		&shop.Closes_at,
-		&shop.Address,
-		&shop.Image,
+		&opensAt,
+		&closesAt,
+		&address,
+		&shop.Street,
+		&shop.City,
+		&shop.PostalCode,
+		&shop.Country,
+		&shop.Latitude,
+		&shop.Longitude,
+		&image,
+		&shop.Timezone,
+		&shop.Allocation,
+		&shop.Status,
+		&shop.StatusReason,
+		&shop.ReviewedBy,
+		&shop.ReviewedAt,
+		&shop.RatingAverage,
+		&shop.RatingCount,
 		&shop.CreatedAt,
 		&shop.UpdatedAt,
-	)
+		&shop.DeletedAt,
+	}
+
+	err := row.Scan(append(dest, extra...)...)
 	if err != nil {
 		return nil, err
 	}
 
+	shop.Description = description.String
+	shop.Opens_at = opensAt.String
+	shop.Closes_at = closesAt.String
+	shop.Address = address.String
+	shop.Image = image.String
+
 	return shop, nil
 }
+
+func valueOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
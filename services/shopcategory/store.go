@@ -1,41 +1,34 @@
 package shopcategory
 
 import (
-	"database/sql"
+	"context"
+	"ecom_go/db"
 	"ecom_go/types"
-	"fmt"
+	"time"
 )
 
 type Store struct {
-	db *sql.DB
+	db *db.DB
 }
 
-func NewStore(db *sql.DB) *Store {
+func NewStore(db *db.DB) *Store {
 	return &Store{db: db}
 }
 
-func (s *Store) GetShopCategoryByID(shopCategoryId int) (*types.ShopCategory, error) {
-	rows, err := s.db.Query("SELECT * FROM shopcategories WHERE id = ?", shopCategoryId)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+const shopCategoryColumns = "id, name, created_at, updated_at, deleted_at"
 
-	shopCategory := new(types.ShopCategory)
-	if rows.Next() {
-		shopCategory, err = scanRowsIntoShopCategory(rows)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		return nil, fmt.Errorf("shop category not found")
+func (s *Store) GetShopCategoryByID(ctx context.Context, shopCategoryId int) (*types.ShopCategory, error) {
+	shopCategory, err := scanShopCategory(s.db.QueryRowContext(ctx,
+		"SELECT "+shopCategoryColumns+" FROM shopcategories WHERE id = ? AND deleted_at IS NULL", shopCategoryId))
+	if err != nil {
+		return nil, types.NotFound("shop category", err)
 	}
 
 	return shopCategory, nil
 }
 
-func (s *Store) CreateShopCategory(shopCategory types.CreateUpdateShopCategoryPayload) error {
-	_, err := s.db.Exec(
+func (s *Store) CreateShopCategory(ctx context.Context, shopCategory types.CreateUpdateShopCategoryPayload) error {
+	_, err := s.db.ExecContext(ctx,
 		"INSERT INTO shopcategories (name) VALUES (?)", shopCategory.Name)
 	if err != nil {
 		return err
@@ -44,14 +37,14 @@ func (s *Store) CreateShopCategory(shopCategory types.CreateUpdateShopCategoryPa
 	return nil
 }
 
-func (s *Store) UpdateShopCategory(categoryID int, shopCategory types.CreateUpdateShopCategoryPayload) error {
-	_, err := s.db.Exec("UPDATE shopcategories SET name = ? WHERE id = ?", shopCategory.Name, categoryID)
+func (s *Store) UpdateShopCategory(ctx context.Context, categoryID int, shopCategory types.CreateUpdateShopCategoryPayload) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE shopcategories SET name = ? WHERE id = ? AND deleted_at IS NULL", shopCategory.Name, categoryID)
 
 	return err
 }
 
-func (s *Store) DeleteShopCategory(categoryID int) (int64, error) {
-	result, err := s.db.Exec("DELETE FROM shopcategories WHERE id = ?", categoryID)
+func (s *Store) DeleteShopCategory(ctx context.Context, categoryID int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "UPDATE shopcategories SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL", time.Now().UTC(), categoryID)
 	if err != nil {
 		return 0, err
 	}
@@ -64,14 +57,24 @@ func (s *Store) DeleteShopCategory(categoryID int) (int64, error) {
 	return rowsAffected, nil
 }
 
-func scanRowsIntoShopCategory(rows *sql.Rows) (*types.ShopCategory, error) {
+func (s *Store) RestoreShopCategory(ctx context.Context, categoryID int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "UPDATE shopcategories SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL", categoryID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func scanShopCategory(row db.Scanner) (*types.ShopCategory, error) {
 	shopCategory := new(types.ShopCategory)
 
-	err := rows.Scan(
+	err := row.Scan(
 		&shopCategory.ID,
 		&shopCategory.Name,
 		&shopCategory.CreatedAt,
 		&shopCategory.UpdatedAt,
+		&shopCategory.DeletedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,62 @@
+package shopmember
+
+import (
+	"context"
+	"ecom_go/types"
+	"errors"
+)
+
+var roleRanks = map[string]int{
+	types.ShopRoleStaff:   1,
+	types.ShopRoleManager: 2,
+	types.ShopRoleOwner:   3,
+}
+
+// RoleAtLeast reports whether role grants at least the permissions of
+// required. Owners can do everything managers can, managers everything staff
+// can.
+func RoleAtLeast(role, required string) bool {
+	return roleRanks[role] > 0 && roleRanks[role] >= roleRanks[required]
+}
+
+// HasRole reports whether the user is a member of the shop with at least the
+// required role. Users who are not members have no role; any other failure to
+// look up the membership is returned.
+func HasRole(ctx context.Context, store types.ShopMemberStore, shopID, userID int, required string) (bool, error) {
+	member, err := store.GetShopMember(ctx, shopID, userID)
+	if errors.Is(err, types.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return RoleAtLeast(member.Role, required), nil
+}
+
+// CanViewShop reports whether the shop is visible to the user. Approved shops
+// are public, shops in any other state are only visible to their members and
+// to admins.
+func CanViewShop(ctx context.Context, store types.ShopMemberStore, userStore types.UserStore, shop *types.Shop, userID int) (bool, error) {
+	if shop.Status == types.ShopStatusApproved {
+		return true, nil
+	}
+
+	_, err := store.GetShopMember(ctx, shop.ID, userID)
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, types.ErrNotFound) {
+		return false, err
+	}
+
+	user, err := userStore.GetUserByID(ctx, userID)
+	if errors.Is(err, types.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return user.Role == "admin", nil
+}
@@ -0,0 +1,383 @@
+package shopmember
+
+import (
+	"crypto/rand"
+	"ecom_go/configs"
+	"ecom_go/services/auth"
+	"ecom_go/services/logging"
+	"ecom_go/types"
+	"ecom_go/utils"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+const invitationTTL = 7 * 24 * time.Hour
+
+type Handler struct {
+	store     types.ShopMemberStore
+	shopStore types.ShopStore
+	userStore types.UserStore
+	notifier  types.Notifier
+}
+
+func NewHandler(store types.ShopMemberStore, shopStore types.ShopStore, userStore types.UserStore, notifier types.Notifier) *Handler {
+	return &Handler{
+		store:     store,
+		shopStore: shopStore,
+		userStore: userStore,
+		notifier:  notifier,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/invitations/{token}/accept", auth.WithJWTAuth(h.handleAcceptInvitation, h.userStore)).Methods(http.MethodPost)
+
+	router.HandleFunc("/{shop_id}/members", auth.WithJWTAuth(h.handleGetMembers, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/{shop_id}/members/{user_id}", auth.WithJWTAuth(h.handleUpdateMember, h.userStore)).Methods(http.MethodPut)
+	router.HandleFunc("/{shop_id}/members/{user_id}", auth.WithJWTAuth(h.handleDeleteMember, h.userStore)).Methods(http.MethodDelete)
+	router.HandleFunc("/{shop_id}/invitations", auth.WithJWTAuth(h.handleGetInvitations, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/{shop_id}/invitations", auth.WithJWTAuth(h.handleCreateInvitation, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/{shop_id}/invitations/{invitation_id}", auth.WithJWTAuth(h.handleDeleteInvitation, h.userStore)).Methods(http.MethodDelete)
+	router.HandleFunc("/{shop_id}/transfer-ownership", auth.WithJWTAuth(h.handleTransferOwnership, h.userStore)).Methods(http.MethodPost)
+}
+
+func (h *Handler) handleGetMembers(w http.ResponseWriter, r *http.Request) {
+	shopID, _, ok := h.authorize(w, r, types.ShopRoleStaff)
+	if !ok {
+		return
+	}
+
+	members, err := h.store.GetShopMembers(r.Context(), shopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, members)
+}
+
+func (h *Handler) handleUpdateMember(w http.ResponseWriter, r *http.Request) {
+	shopID, _, ok := h.authorize(w, r, types.ShopRoleOwner)
+	if !ok {
+		return
+	}
+
+	memberID, ok := pathID(w, r, "user_id", "user ID")
+	if !ok {
+		return
+	}
+
+	var payload types.UpdateShopMemberPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	member, err := h.store.GetShopMember(r.Context(), shopID, memberID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if member.Role == types.ShopRoleOwner {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("use ownership transfer to change the owner's role"))
+		return
+	}
+
+	if err := h.store.UpdateShopMemberRole(r.Context(), shopID, memberID, payload.Role); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	member.Role = payload.Role
+	utils.WriteJSON(w, http.StatusOK, member)
+}
+
+// handleDeleteMember removes a member from the shop. Owners can remove anyone
+// but themselves, other members can only leave the shop.
+func (h *Handler) handleDeleteMember(w http.ResponseWriter, r *http.Request) {
+	shopID, caller, ok := h.authorize(w, r, types.ShopRoleStaff)
+	if !ok {
+		return
+	}
+
+	memberID, ok := pathID(w, r, "user_id", "user ID")
+	if !ok {
+		return
+	}
+
+	if caller.UserID != memberID && caller.Role != types.ShopRoleOwner {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you do not have permission to remove this member"))
+		return
+	}
+
+	rowsAffected, err := h.store.DeleteShopMember(r.Context(), shopID, memberID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to remove shop member: %w", err))
+		return
+	}
+
+	if rowsAffected == 0 {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("shop member not found or is the owner"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleGetInvitations(w http.ResponseWriter, r *http.Request) {
+	shopID, _, ok := h.authorize(w, r, types.ShopRoleManager)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.store.GetShopInvitations(r.Context(), shopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, invitations)
+}
+
+// handleCreateInvitation invites a user by email. Managers may only invite
+// staff, owners may invite managers as well.
+func (h *Handler) handleCreateInvitation(w http.ResponseWriter, r *http.Request) {
+	shopID, caller, ok := h.authorize(w, r, types.ShopRoleManager)
+	if !ok {
+		return
+	}
+
+	var payload types.CreateShopInvitationPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	if payload.Role == types.ShopRoleManager && caller.Role != types.ShopRoleOwner {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("only the owner can invite managers"))
+		return
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	invitation := types.ShopInvitation{
+		ShopID:    shopID,
+		Email:     strings.ToLower(payload.Email),
+		Role:      payload.Role,
+		Token:     token,
+		InvitedBy: caller.UserID,
+		ExpiresAt: time.Now().Add(invitationTTL).UTC(),
+	}
+	if err := h.store.CreateShopInvitation(r.Context(), invitation); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	shop, err := h.shopStore.GetShopByID(r.Context(), shopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	body := fmt.Sprintf("You have been invited to join %s as %s.\n\nAccept the invitation within 7 days: %s:%s/api/v1/shops/invitations/%s/accept",
+		shop.Name, payload.Role, configs.Envs.PublicHost, configs.Envs.Port, token)
+	if err := h.notifier.Notify(invitation.Email, fmt.Sprintf("Invitation to join %s", shop.Name), body); err != nil {
+		logging.FromContext(r.Context()).Error("failed to send shop invitation", "shop_id", shop.ID, "error", err)
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, invitation)
+}
+
+func (h *Handler) handleDeleteInvitation(w http.ResponseWriter, r *http.Request) {
+	shopID, _, ok := h.authorize(w, r, types.ShopRoleManager)
+	if !ok {
+		return
+	}
+
+	invitationID, ok := pathID(w, r, "invitation_id", "invitation ID")
+	if !ok {
+		return
+	}
+
+	rowsAffected, err := h.store.DeleteShopInvitation(r.Context(), shopID, invitationID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to delete invitation: %w", err))
+		return
+	}
+
+	if rowsAffected == 0 {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("invitation not found"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleAcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserIDFromContext(r.Context())
+	if userID == -1 {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("unauthorized"))
+		return
+	}
+
+	invitation, err := h.store.GetShopInvitationByToken(r.Context(), mux.Vars(r)["token"])
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if invitation.AcceptedAt != nil || time.Now().After(invitation.ExpiresAt) {
+		utils.WriteError(w, http.StatusGone, fmt.Errorf("invitation is no longer valid"))
+		return
+	}
+
+	user, err := h.userStore.GetUserByID(r.Context(), userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("invitation was sent to a different email address"))
+		return
+	}
+
+	if _, err := h.store.GetShopMember(r.Context(), invitation.ShopID, userID); err == nil {
+		utils.WriteError(w, http.StatusConflict, fmt.Errorf("you are already a member of this shop"))
+		return
+	}
+
+	if err := h.store.AcceptShopInvitation(r.Context(), *invitation, userID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	member, _ := h.store.GetShopMember(r.Context(), invitation.ShopID, userID)
+	utils.WriteJSON(w, http.StatusOK, member)
+}
+
+func (h *Handler) handleTransferOwnership(w http.ResponseWriter, r *http.Request) {
+	shopID, caller, ok := h.authorize(w, r, types.ShopRoleOwner)
+	if !ok {
+		return
+	}
+
+	var payload types.TransferShopOwnershipPayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	if payload.UserID == caller.UserID {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("you already own this shop"))
+		return
+	}
+
+	if _, err := h.store.GetShopMember(r.Context(), shopID, payload.UserID); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("new owner must be a member of the shop"))
+		return
+	}
+
+	if err := h.store.TransferShopOwnership(r.Context(), shopID, caller.UserID, payload.UserID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	members, err := h.store.GetShopMembers(r.Context(), shopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, members)
+}
+
+// authorize resolves the shop from the request path and checks that the
+// authenticated user is a member with at least the required role. It writes
+// an error response and returns false otherwise.
+func (h *Handler) authorize(w http.ResponseWriter, r *http.Request, required string) (int, *types.ShopMember, bool) {
+	userID := auth.GetUserIDFromContext(r.Context())
+	if userID == -1 {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("unauthorized"))
+		return 0, nil, false
+	}
+
+	shopID, ok := pathID(w, r, "shop_id", "shop ID")
+	if !ok {
+		return 0, nil, false
+	}
+
+	if _, err := h.shopStore.GetShopByID(r.Context(), shopID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return 0, nil, false
+	}
+
+	member, err := h.store.GetShopMember(r.Context(), shopID, userID)
+	if err != nil && !errors.Is(err, types.ErrNotFound) {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return 0, nil, false
+	}
+
+	if err != nil || !RoleAtLeast(member.Role, required) {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("you do not have permission to manage this shop"))
+		return 0, nil, false
+	}
+
+	return shopID, member, true
+}
+
+func pathID(w http.ResponseWriter, r *http.Request, key, name string) (int, bool) {
+	str, ok := mux.Vars(r)[key]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing %s", name))
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid %s", name))
+		return 0, false
+	}
+
+	return id, true
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,208 @@
+package shopmember
+
+import (
+	"context"
+	"ecom_go/db"
+	"ecom_go/types"
+	"fmt"
+	"time"
+)
+
+type Store struct {
+	db *db.DB
+}
+
+func NewStore(db *db.DB) *Store {
+	return &Store{db: db}
+}
+
+const (
+	shopMemberColumns     = "id, shop_id, user_id, role, created_at, updated_at"
+	shopInvitationColumns = "id, shop_id, email, role, token, invited_by, expires_at, accepted_at, created_at, updated_at"
+)
+
+func (s *Store) GetShopMember(ctx context.Context, shopID int, userID int) (*types.ShopMember, error) {
+	member, err := scanShopMember(s.db.QueryRowContext(ctx,
+		"SELECT "+shopMemberColumns+" FROM shop_members WHERE shop_id = ? AND user_id = ?", shopID, userID))
+	if err != nil {
+		return nil, types.NotFound("shop member", err)
+	}
+
+	return member, nil
+}
+
+func (s *Store) GetShopMembers(ctx context.Context, shopID int) ([]types.ShopMember, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+shopMemberColumns+" FROM shop_members WHERE shop_id = ? ORDER BY id", shopID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []types.ShopMember{}
+	for rows.Next() {
+		member, err := scanShopMember(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		members = append(members, *member)
+	}
+
+	return members, rows.Err()
+}
+
+func (s *Store) UpdateShopMemberRole(ctx context.Context, shopID int, userID int, role string) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE shop_members SET role = ? WHERE shop_id = ? AND user_id = ?", role, shopID, userID)
+
+	return err
+}
+
+func (s *Store) DeleteShopMember(ctx context.Context, shopID int, userID int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM shop_members WHERE shop_id = ? AND user_id = ? AND role <> ?",
+		shopID, userID, types.ShopRoleOwner)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// TransferShopOwnership makes toUserID the owner of the shop and demotes the
+// previous owner to manager.
+func (s *Store) TransferShopOwnership(ctx context.Context, shopID int, fromUserID int, toUserID int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE shop_members SET role = ? WHERE shop_id = ? AND user_id = ?",
+		types.ShopRoleManager, shopID, fromUserID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE shop_members SET role = ? WHERE shop_id = ? AND user_id = ?",
+		types.ShopRoleOwner, shopID, toUserID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE shops SET user_id = ? WHERE id = ? AND deleted_at IS NULL", toUserID, shopID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) CreateShopInvitation(ctx context.Context, invitation types.ShopInvitation) error {
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO shop_invitations (shop_id, email, role, token, invited_by, expires_at) VALUES (?, ?, ?, ?, ?, ?)",
+		invitation.ShopID, invitation.Email, invitation.Role, invitation.Token, invitation.InvitedBy, invitation.ExpiresAt)
+
+	return err
+}
+
+func (s *Store) GetShopInvitations(ctx context.Context, shopID int) ([]types.ShopInvitation, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+shopInvitationColumns+" FROM shop_invitations WHERE shop_id = ? ORDER BY id DESC", shopID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []types.ShopInvitation{}
+	for rows.Next() {
+		invitation, err := scanShopInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		invitations = append(invitations, *invitation)
+	}
+
+	return invitations, rows.Err()
+}
+
+func (s *Store) GetShopInvitationByToken(ctx context.Context, token string) (*types.ShopInvitation, error) {
+	invitation, err := scanShopInvitation(s.db.QueryRowContext(ctx, "SELECT "+shopInvitationColumns+" FROM shop_invitations WHERE token = ?", token))
+	if err != nil {
+		return nil, types.NotFound("invitation", err)
+	}
+
+	return invitation, nil
+}
+
+// AcceptShopInvitation adds the user to the shop with the invited role and
+// marks the invitation as used.
+func (s *Store) AcceptShopInvitation(ctx context.Context, invitation types.ShopInvitation, userID int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "UPDATE shop_invitations SET accepted_at = ? WHERE id = ? AND accepted_at IS NULL",
+		time.Now().UTC(), invitation.ID)
+	if err != nil {
+		return err
+	}
+
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return fmt.Errorf("invitation has already been accepted")
+	}
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO shop_members (shop_id, user_id, role) VALUES (?, ?, ?)",
+		invitation.ShopID, userID, invitation.Role); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) DeleteShopInvitation(ctx context.Context, shopID int, invitationID int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM shop_invitations WHERE id = ? AND shop_id = ? AND accepted_at IS NULL",
+		invitationID, shopID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func scanShopMember(row db.Scanner) (*types.ShopMember, error) {
+	member := new(types.ShopMember)
+
+	err := row.Scan(
+		&member.ID,
+		&member.ShopID,
+		&member.UserID,
+		&member.Role,
+		&member.CreatedAt,
+		&member.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func scanShopInvitation(row db.Scanner) (*types.ShopInvitation, error) {
+	invitation := new(types.ShopInvitation)
+
+	err := row.Scan(
+		&invitation.ID,
+		&invitation.ShopID,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.Token,
+		&invitation.InvitedBy,
+		&invitation.ExpiresAt,
+		&invitation.AcceptedAt,
+		&invitation.CreatedAt,
+		&invitation.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
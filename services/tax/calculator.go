@@ -0,0 +1,131 @@
+package tax
+
+import (
+	"context"
+	"ecom_go/types"
+	"strings"
+)
+
+const (
+	RoundingHalfUp   = "half_up"
+	RoundingHalfEven = "half_even"
+	RoundingDown     = "down"
+)
+
+// TableCalculator computes taxes from the rates stored in the database.
+//
+// Lines are taxed in the buyer's country and region when the request has
+// them and in the country of the shop otherwise. A rate for the region takes
+// precedence over the country wide rate of the same tax class. Lines without
+// a matching rate are not taxed. Tax is rounded per line.
+type TableCalculator struct {
+	store    types.TaxRateStore
+	rounding string
+}
+
+func NewCalculator(store types.TaxRateStore, rounding string) *TableCalculator {
+	return &TableCalculator{store: store, rounding: rounding}
+}
+
+func (c *TableCalculator) Calculate(ctx context.Context, request types.TaxRequest) (*types.TaxResult, error) {
+	rates := map[string][]types.TaxRate{}
+	result := &types.TaxResult{Lines: []types.TaxLineResult{}}
+
+	for _, line := range request.Lines {
+		country, region := request.Country, request.Region
+		if country == "" {
+			if line.OriginCountry == nil {
+				return nil, &types.NoTaxCountryError{ProductID: line.ProductID}
+			}
+			country, region = *line.OriginCountry, nil
+		}
+		country = strings.ToUpper(country)
+
+		if _, ok := rates[country]; !ok {
+			countryRates, err := c.store.GetTaxRates(ctx, country)
+			if err != nil {
+				return nil, err
+			}
+			rates[country] = countryRates
+		}
+
+		lineResult := ApplyRate(line, country, FindRate(rates[country], region, line.TaxClass), c.rounding)
+		result.Lines = append(result.Lines, lineResult)
+		result.NetCents += lineResult.NetCents
+		result.TaxCents += lineResult.TaxCents
+		result.GrossCents += lineResult.GrossCents
+	}
+
+	return result, nil
+}
+
+// FindRate picks the rate for the tax class in the region, falling back to
+// the country wide one. It returns nil when neither exists.
+func FindRate(rates []types.TaxRate, region *string, taxClass string) *types.TaxRate {
+	var countryRate *types.TaxRate
+	for i, rate := range rates {
+		if rate.TaxClass != taxClass {
+			continue
+		}
+
+		if rate.Region == nil {
+			countryRate = &rates[i]
+		} else if region != nil && strings.EqualFold(*rate.Region, *region) {
+			return &rates[i]
+		}
+	}
+
+	return countryRate
+}
+
+// ApplyRate applies the rate to the line. Inclusive rates split the tax out of
+// the amount, exclusive ones add it on top.
+func ApplyRate(line types.TaxLine, country string, rate *types.TaxRate, rounding string) types.TaxLineResult {
+	result := types.TaxLineResult{
+		ProductID:  line.ProductID,
+		TaxClass:   line.TaxClass,
+		Country:    country,
+		NetCents:   line.AmountCents,
+		GrossCents: line.AmountCents,
+	}
+
+	if rate == nil || line.AmountCents <= 0 {
+		return result
+	}
+
+	result.RateID = &rate.ID
+	result.RateName = rate.Name
+	result.RateBasisPoints = rate.RateBasisPoints
+	result.Inclusive = rate.Inclusive
+
+	bps := int64(rate.RateBasisPoints)
+	if rate.Inclusive {
+		result.TaxCents = divRound(line.AmountCents*bps, 10000+bps, rounding)
+		result.NetCents = line.AmountCents - result.TaxCents
+	} else {
+		result.TaxCents = divRound(line.AmountCents*bps, 10000, rounding)
+		result.GrossCents = line.AmountCents + result.TaxCents
+	}
+
+	return result
+}
+
+// divRound divides two non-negative numbers, rounding the result to an
+// integer with the given rounding mode.
+func divRound(num, den int64, rounding string) int64 {
+	q, r := num/den, num%den
+
+	switch rounding {
+	case RoundingDown:
+	case RoundingHalfEven:
+		if 2*r > den || (2*r == den && q%2 == 1) {
+			q++
+		}
+	default:
+		if 2*r >= den {
+			q++
+		}
+	}
+
+	return q
+}
@@ -0,0 +1,251 @@
+package tax
+
+import (
+	"context"
+	"ecom_go/services/auth"
+	"ecom_go/services/shopmember"
+	"ecom_go/types"
+	"ecom_go/utils"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	store        types.TaxRateStore
+	calculator   types.TaxCalculator
+	productStore types.ProductStore
+	shopStore    types.ShopStore
+	memberStore  types.ShopMemberStore
+	userStore    types.UserStore
+}
+
+func NewHandler(store types.TaxRateStore, calculator types.TaxCalculator, productStore types.ProductStore, shopStore types.ShopStore, memberStore types.ShopMemberStore, userStore types.UserStore) *Handler {
+	return &Handler{
+		store:        store,
+		calculator:   calculator,
+		productStore: productStore,
+		shopStore:    shopStore,
+		memberStore:  memberStore,
+		userStore:    userStore,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/quote", auth.WithJWTAuth(h.handleQuote, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/rates", auth.WithAdminJWTAuth(h.handleGetRates, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/rates", auth.WithAdminJWTAuth(h.handleCreateRate, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/rates/{rate_id}", auth.WithAdminJWTAuth(h.handleGetRate, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/rates/{rate_id}", auth.WithAdminJWTAuth(h.handleUpdateRate, h.userStore)).Methods(http.MethodPut)
+	router.HandleFunc("/rates/{rate_id}", auth.WithAdminJWTAuth(h.handleDeleteRate, h.userStore)).Methods(http.MethodDelete)
+}
+
+func (h *Handler) handleGetRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.store.GetTaxRates(r.Context(), r.URL.Query().Get("country"))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, rates)
+}
+
+func (h *Handler) handleGetRate(w http.ResponseWriter, r *http.Request) {
+	rate, ok := h.getRateFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, rate)
+}
+
+func (h *Handler) handleCreateRate(w http.ResponseWriter, r *http.Request) {
+	var rate types.CreateUpdateTaxRatePayload
+	if err := utils.ParseJSON(r, &rate); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(rate); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	if !h.checkRate(r.Context(), w, 0, rate) {
+		return
+	}
+
+	rateID, err := h.store.CreateTaxRate(r.Context(), rate)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	createdRate, _ := h.store.GetTaxRateByID(r.Context(), rateID)
+	utils.WriteJSON(w, http.StatusCreated, createdRate)
+}
+
+func (h *Handler) handleUpdateRate(w http.ResponseWriter, r *http.Request) {
+	existingRate, ok := h.getRateFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var rate types.CreateUpdateTaxRatePayload
+	if err := utils.ParseJSON(r, &rate); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(rate); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	if !h.checkRate(r.Context(), w, existingRate.ID, rate) {
+		return
+	}
+
+	if err := h.store.UpdateTaxRate(r.Context(), existingRate.ID, rate); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	updatedRate, _ := h.store.GetTaxRateByID(r.Context(), existingRate.ID)
+	utils.WriteJSON(w, http.StatusOK, updatedRate)
+}
+
+func (h *Handler) handleDeleteRate(w http.ResponseWriter, r *http.Request) {
+	existingRate, ok := h.getRateFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	rowsAffected, err := h.store.DeleteTaxRate(r.Context(), existingRate.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to delete tax rate: %w", err))
+		return
+	}
+
+	if rowsAffected == 0 {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("tax rate not found"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleQuote computes the taxes on the items at their current prices.
+func (h *Handler) handleQuote(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var payload types.TaxQuotePayload
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	request := types.TaxRequest{Region: payload.Region}
+	if payload.Country != nil {
+		request.Country = *payload.Country
+	}
+
+	for _, item := range payload.Items {
+		product, err := h.productStore.GetProductByID(r.Context(), item.ProductID)
+		if err != nil {
+			utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product %d not found", item.ProductID))
+			return
+		}
+
+		shop, err := h.shopStore.GetShopByID(r.Context(), product.ShopID)
+		if err != nil {
+			utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product %d not found", item.ProductID))
+			return
+		}
+
+		visible, err := shopmember.CanViewShop(r.Context(), h.memberStore, h.userStore, shop, userID)
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if !visible {
+			utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product %d not found", item.ProductID))
+			return
+		}
+
+		request.Lines = append(request.Lines, types.TaxLine{
+			ProductID:     product.ID,
+			TaxClass:      product.TaxClass,
+			Quantity:      item.Quantity,
+			AmountCents:   product.PriceCents * int64(item.Quantity),
+			OriginCountry: shop.Country,
+		})
+	}
+
+	result, err := h.calculator.Calculate(r.Context(), request)
+	var noCountry *types.NoTaxCountryError
+	if errors.As(err, &noCountry) {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, result)
+}
+
+// checkRate makes sure no other rate exists for the same country, region and
+// tax class, writing a conflict response otherwise. The unique key of the
+// table does not cover rates without a region, as NULLs never compare equal.
+func (h *Handler) checkRate(ctx context.Context, w http.ResponseWriter, rateID int, rate types.CreateUpdateTaxRatePayload) bool {
+	other, err := h.store.GetTaxRate(ctx, rate.Country, rate.Region, rate.TaxClass)
+	if err != nil && !errors.Is(err, types.ErrNotFound) {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return false
+	}
+
+	if err == nil && other.ID != rateID {
+		utils.WriteError(w, http.StatusConflict, fmt.Errorf("a %s tax rate for this country and region already exists", other.TaxClass))
+		return false
+	}
+
+	return true
+}
+
+func (h *Handler) getRateFromPath(w http.ResponseWriter, r *http.Request) (*types.TaxRate, bool) {
+	vars := mux.Vars(r)
+	str, ok := vars["rate_id"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing tax rate ID"))
+		return nil, false
+	}
+
+	rateID, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid tax rate ID"))
+		return nil, false
+	}
+
+	rate, err := h.store.GetTaxRateByID(r.Context(), rateID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	return rate, true
+}
@@ -0,0 +1,123 @@
+package tax
+
+import (
+	"context"
+	"ecom_go/db"
+	"ecom_go/types"
+	"strings"
+)
+
+type Store struct {
+	db *db.DB
+}
+
+func NewStore(db *db.DB) *Store {
+	return &Store{db: db}
+}
+
+const taxRateColumns = "id, country, region, tax_class, name, rate_basis_points, inclusive, created_at, updated_at"
+
+func (s *Store) GetTaxRateByID(ctx context.Context, rateID int) (*types.TaxRate, error) {
+	rate, err := scanTaxRate(s.db.QueryRowContext(ctx, "SELECT "+taxRateColumns+" FROM tax_rates WHERE id = ?", rateID))
+	if err != nil {
+		return nil, types.NotFound("tax rate", err)
+	}
+
+	return rate, nil
+}
+
+// GetTaxRate finds the rate of a tax class in a country, or in one region of
+// it when region is set.
+func (s *Store) GetTaxRate(ctx context.Context, country string, region *string, taxClass string) (*types.TaxRate, error) {
+	query := "SELECT " + taxRateColumns + " FROM tax_rates WHERE country = ? AND region IS NULL AND tax_class = ?"
+	args := []any{strings.ToUpper(country), taxClass}
+	if region != nil {
+		query = "SELECT " + taxRateColumns + " FROM tax_rates WHERE country = ? AND region = ? AND tax_class = ?"
+		args = []any{strings.ToUpper(country), *region, taxClass}
+	}
+
+	rate, err := scanTaxRate(s.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		return nil, types.NotFound("tax rate", err)
+	}
+
+	return rate, nil
+}
+
+// GetTaxRates lists the rates of a country, or of every country when country
+// is empty.
+func (s *Store) GetTaxRates(ctx context.Context, country string) ([]types.TaxRate, error) {
+	query := "SELECT " + taxRateColumns + " FROM tax_rates ORDER BY country, region, tax_class"
+	args := []any{}
+	if country != "" {
+		query = "SELECT " + taxRateColumns + " FROM tax_rates WHERE country = ? ORDER BY region, tax_class"
+		args = append(args, strings.ToUpper(country))
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []types.TaxRate{}
+	for rows.Next() {
+		rate, err := scanTaxRate(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		rates = append(rates, *rate)
+	}
+
+	return rates, rows.Err()
+}
+
+func (s *Store) CreateTaxRate(ctx context.Context, rate types.CreateUpdateTaxRatePayload) (int, error) {
+	id, err := s.db.Insert(ctx,
+		"INSERT INTO tax_rates (country, region, tax_class, name, rate_basis_points, inclusive) VALUES (?, ?, ?, ?, ?, ?)",
+		strings.ToUpper(rate.Country), rate.Region, rate.TaxClass, rate.Name, rate.RateBasisPoints, rate.Inclusive)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+func (s *Store) UpdateTaxRate(ctx context.Context, rateID int, rate types.CreateUpdateTaxRatePayload) error {
+	_, err := s.db.ExecContext(ctx,
+		"UPDATE tax_rates SET country = ?, region = ?, tax_class = ?, name = ?, rate_basis_points = ?, inclusive = ? WHERE id = ?",
+		strings.ToUpper(rate.Country), rate.Region, rate.TaxClass, rate.Name, rate.RateBasisPoints, rate.Inclusive, rateID)
+
+	return err
+}
+
+func (s *Store) DeleteTaxRate(ctx context.Context, rateID int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM tax_rates WHERE id = ?", rateID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func scanTaxRate(row db.Scanner) (*types.TaxRate, error) {
+	rate := new(types.TaxRate)
+
+	err := row.Scan(
+		&rate.ID,
+		&rate.Country,
+		&rate.Region,
+		&rate.TaxClass,
+		&rate.Name,
+		&rate.RateBasisPoints,
+		&rate.Inclusive,
+		&rate.CreatedAt,
+		&rate.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return rate, nil
+}
@@ -0,0 +1,84 @@
+package trash
+
+import (
+	"context"
+	"ecom_go/types"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Purger periodically removes soft deleted records once they are older than
+// the retention window.
+type Purger struct {
+	store     types.TrashStore
+	retention time.Duration
+	interval  time.Duration
+	stop      chan struct{}
+	wg        sync.WaitGroup
+	running   atomic.Bool
+}
+
+func NewPurger(store types.TrashStore, retention, interval time.Duration) *Purger {
+	return &Purger{
+		store:     store,
+		retention: retention,
+		interval:  interval,
+		stop:      make(chan struct{}),
+	}
+}
+
+func (p *Purger) Start() {
+	p.running.Store(true)
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer p.running.Store(false)
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			p.purge()
+
+			select {
+			case <-ticker.C:
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop waits for a running purge to finish.
+func (p *Purger) Stop() {
+	close(p.stop)
+	p.wg.Wait()
+}
+
+func (p *Purger) Name() string {
+	return "trash_purger"
+}
+
+// Check reports whether the trash purger is running.
+func (p *Purger) Check(ctx context.Context) error {
+	if !p.running.Load() {
+		return fmt.Errorf("trash purger is not running")
+	}
+
+	return nil
+}
+
+func (p *Purger) purge() {
+	n, err := p.store.PurgeDeleted(context.Background(), time.Now().Add(-p.retention).UTC())
+	if err != nil {
+		slog.Error("failed to purge deleted records", "error", err)
+		return
+	}
+
+	if n > 0 {
+		slog.Info("purged deleted records", "count", n)
+	}
+}
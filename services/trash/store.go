@@ -0,0 +1,57 @@
+package trash
+
+import (
+	"context"
+	"ecom_go/db"
+	"strings"
+	"time"
+)
+
+type Store struct {
+	db *db.DB
+}
+
+func NewStore(db *db.DB) *Store {
+	return &Store{db: db}
+}
+
+// purgeQueries are run in order so that rows are removed before the rows
+// they reference. Products of purged shops go first, and categories are only
+// purged once nothing references them anymore.
+var purgeQueries = []string{
+	"DELETE FROM products WHERE deleted_at < ? OR shop_id IN (SELECT id FROM shops WHERE deleted_at < ?)",
+	"DELETE FROM shops WHERE deleted_at < ? AND NOT EXISTS (SELECT 1 FROM products WHERE products.shop_id = shops.id)",
+	"DELETE FROM productcategories WHERE deleted_at < ? AND NOT EXISTS (SELECT 1 FROM products WHERE products.category_id = productcategories.id)",
+	"DELETE FROM shopcategories WHERE deleted_at < ? AND NOT EXISTS (SELECT 1 FROM shops WHERE shops.category_id = shopcategories.id)",
+}
+
+// PurgeDeleted permanently removes shops, products and categories that were
+// soft deleted before the given time.
+func (s *Store) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var total int64
+	for _, query := range purgeQueries {
+		args := make([]any, strings.Count(query, "?"))
+		for i := range args {
+			args[i] = before
+		}
+
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+
+	return total, tx.Commit()
+}
@@ -3,8 +3,10 @@ package user
 import (
 	"ecom_go/configs"
 	"ecom_go/services/auth"
+	"ecom_go/services/metrics"
 	"ecom_go/types"
 	"ecom_go/utils"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -43,11 +45,15 @@ func (h *Handler) handleRegister(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err := h.store.GetUserByEmail(user.Email)
+	_, err := h.store.GetUserByEmail(r.Context(), user.Email)
 	if err == nil {
 		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("user with email %s already exists", user.Email))
 		return
 	}
+	if !errors.Is(err, types.ErrNotFound) {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
 
 	hashedPassword, err := auth.HashPassword(user.Password)
 	if err != nil {
@@ -56,7 +62,7 @@ func (h *Handler) handleRegister(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var role string = "customer"
-	err = h.store.CreateUser(types.User{
+	err = h.store.CreateUser(r.Context(), types.User{
 		FirstName:   user.FirstName,
 		LastName:    user.LastName,
 		Email:       user.Email,
@@ -69,6 +75,8 @@ func (h *Handler) handleRegister(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	metrics.Registrations.WithLabelValues(role).Inc()
+
 	utils.WriteJSON(w, http.StatusCreated, nil)
 }
 
@@ -85,11 +93,15 @@ func (h *Handler) handleRegisterAdmin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err := h.store.GetUserByEmail(user.Email)
+	_, err := h.store.GetUserByEmail(r.Context(), user.Email)
 	if err == nil {
 		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("user with email %s already exists", user.Email))
 		return
 	}
+	if !errors.Is(err, types.ErrNotFound) {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
 
 	hashedPassword, err := auth.HashPassword(user.Password)
 	if err != nil {
@@ -98,7 +110,7 @@ func (h *Handler) handleRegisterAdmin(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var role string = "admin"
-	err = h.store.CreateUser(types.User{
+	err = h.store.CreateUser(r.Context(), types.User{
 		FirstName:   user.FirstName,
 		LastName:    user.LastName,
 		Email:       user.Email,
@@ -111,6 +123,8 @@ func (h *Handler) handleRegisterAdmin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	metrics.Registrations.WithLabelValues(role).Inc()
+
 	utils.WriteJSON(w, http.StatusCreated, nil)
 }
 
@@ -127,13 +141,19 @@ func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	u, err := h.store.GetUserByEmail(user.Email)
+	u, err := h.store.GetUserByEmail(r.Context(), user.Email)
+	if err != nil && !errors.Is(err, types.ErrNotFound) {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
 	if err != nil {
+		metrics.FailedLogins.Inc()
 		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("not found, invalid email or password"))
 		return
 	}
 
 	if !auth.ComparePasswords(u.Password, []byte(user.Password)) {
+		metrics.FailedLogins.Inc()
 		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid email or password"))
 		return
 	}
@@ -151,6 +171,8 @@ func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	metrics.Logins.Inc()
+
 	utils.WriteJSON(w, http.StatusOK, map[string]string{
 		"access_token":  accessToken,
 		"refresh_token": refreshToken,
@@ -206,9 +228,9 @@ func (h *Handler) handleGetProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.store.GetUserByID(userID)
+	user, err := h.store.GetUserByID(r.Context(), userID)
 	if err != nil {
-		utils.WriteError(w, http.StatusNotFound, err)
+		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -1,63 +1,41 @@
 package user
 
 import (
-	"database/sql"
+	"context"
+	"ecom_go/db"
 	"ecom_go/types"
-	"fmt"
 )
 
 type Store struct {
-	db *sql.DB
+	db *db.DB
 }
 
-func NewStore(db *sql.DB) *Store {
+func NewStore(db *db.DB) *Store {
 	return &Store{db: db}
 }
 
-func (s *Store) GetUserByID(id int) (*types.User, error) {
-	rows, err := s.db.Query("SELECT * FROM users WHERE id = ?", id)
-	if err != nil {
-		return nil, err
-	}
+const userColumns = "id, first_name, last_name, email, phone_number, role, password, created_at, updated_at"
 
-	u := new(types.User)
-	for rows.Next() {
-		u, err = scanRowsIntoUser(rows)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	if u.ID == 0 {
-		return nil, fmt.Errorf("user not found")
+func (s *Store) GetUserByID(ctx context.Context, id int) (*types.User, error) {
+	u, err := scanUser(s.db.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE id = ?", id))
+	if err != nil {
+		return nil, types.NotFound("user", err)
 	}
 
 	return u, nil
 }
 
-func (s *Store) GetUserByEmail(email string) (*types.User, error) {
-	rows, err := s.db.Query("SELECT * FROM users WHERE email = ?", email)
+func (s *Store) GetUserByEmail(ctx context.Context, email string) (*types.User, error) {
+	u, err := scanUser(s.db.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE email = ?", email))
 	if err != nil {
-		return nil, err
-	}
-
-	u := new(types.User)
-	for rows.Next() {
-		u, err = scanRowsIntoUser(rows)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	if u.ID == 0 {
-		return nil, fmt.Errorf("user not found")
+		return nil, types.NotFound("user", err)
 	}
 
 	return u, nil
 }
 
-func (s *Store) CreateUser(user types.User) error {
-	_, err := s.db.Exec(
+func (s *Store) CreateUser(ctx context.Context, user types.User) error {
+	_, err := s.db.ExecContext(ctx,
 		"INSERT INTO users (first_name, last_name, email, phone_number, role, password) VALUES (?, ?, ?, ?, ?, ?)",
 		user.FirstName, user.LastName, user.Email, user.PhoneNumber, user.Role, user.Password)
 	if err != nil {
@@ -67,10 +45,10 @@ func (s *Store) CreateUser(user types.User) error {
 	return nil
 }
 
-func scanRowsIntoUser(rows *sql.Rows) (*types.User, error) {
+func scanUser(row db.Scanner) (*types.User, error) {
 	user := new(types.User)
 
-	err := rows.Scan(
+	err := row.Scan(
 		&user.ID,
 		&user.FirstName,
 		&user.LastName,
@@ -0,0 +1,451 @@
+package wishlist
+
+import (
+	"context"
+	"crypto/rand"
+	"ecom_go/services/auth"
+	"ecom_go/services/shopmember"
+	"ecom_go/types"
+	"ecom_go/utils"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	store        types.WishlistStore
+	productStore types.ProductStore
+	shopStore    types.ShopStore
+	memberStore  types.ShopMemberStore
+	userStore    types.UserStore
+}
+
+func NewHandler(store types.WishlistStore, productStore types.ProductStore, shopStore types.ShopStore, memberStore types.ShopMemberStore, userStore types.UserStore) *Handler {
+	return &Handler{
+		store:        store,
+		productStore: productStore,
+		shopStore:    shopStore,
+		memberStore:  memberStore,
+		userStore:    userStore,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/me/wishlist", auth.WithJWTAuth(h.handleGetDefaultWishlist, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/me/wishlist", auth.WithJWTAuth(h.handleAddToDefaultWishlist, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/me/wishlist/{product_id}", auth.WithJWTAuth(h.handleRemoveFromDefaultWishlist, h.userStore)).Methods(http.MethodDelete)
+	router.HandleFunc("/me/wishlists", auth.WithJWTAuth(h.handleGetWishlists, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/me/wishlists", auth.WithJWTAuth(h.handleCreateWishlist, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/me/wishlists/{wishlist_id}", auth.WithJWTAuth(h.handleGetWishlist, h.userStore)).Methods(http.MethodGet)
+	router.HandleFunc("/me/wishlists/{wishlist_id}", auth.WithJWTAuth(h.handleUpdateWishlist, h.userStore)).Methods(http.MethodPut)
+	router.HandleFunc("/me/wishlists/{wishlist_id}", auth.WithJWTAuth(h.handleDeleteWishlist, h.userStore)).Methods(http.MethodDelete)
+	router.HandleFunc("/me/wishlists/{wishlist_id}/items", auth.WithJWTAuth(h.handleAddItem, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/me/wishlists/{wishlist_id}/items/{product_id}", auth.WithJWTAuth(h.handleRemoveItem, h.userStore)).Methods(http.MethodDelete)
+	router.HandleFunc("/me/wishlists/{wishlist_id}/share", auth.WithJWTAuth(h.handleShareWishlist, h.userStore)).Methods(http.MethodPost)
+	router.HandleFunc("/me/wishlists/{wishlist_id}/share", auth.WithJWTAuth(h.handleUnshareWishlist, h.userStore)).Methods(http.MethodDelete)
+	router.HandleFunc("/wishlists/shared/{token}", h.handleGetSharedWishlist).Methods(http.MethodGet)
+}
+
+func (h *Handler) handleGetDefaultWishlist(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	wishlist, err := h.store.GetDefaultWishlist(r.Context(), userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := h.attachProducts(r.Context(), wishlist, userID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, wishlist)
+}
+
+func (h *Handler) handleAddToDefaultWishlist(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	wishlist, err := h.store.GetDefaultWishlist(r.Context(), userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.addItem(w, r, wishlist)
+}
+
+func (h *Handler) handleRemoveFromDefaultWishlist(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	wishlist, err := h.store.GetDefaultWishlist(r.Context(), userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.removeItem(w, r, wishlist)
+}
+
+func (h *Handler) handleGetWishlists(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	// Make sure the default wishlist always shows up in the listing.
+	if _, err := h.store.GetDefaultWishlist(r.Context(), userID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	wishlists, err := h.store.GetWishlists(r.Context(), userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, wishlists)
+}
+
+func (h *Handler) handleCreateWishlist(w http.ResponseWriter, r *http.Request) {
+	userID := auth.GetUserIDFromContext(r.Context())
+
+	var wishlist types.CreateUpdateWishlistPayload
+	if err := utils.ParseJSON(r, &wishlist); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(wishlist); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	wishlistID, err := h.store.CreateWishlist(r.Context(), userID, wishlist.Name)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	createdWishlist, _ := h.store.GetWishlistByID(r.Context(), wishlistID)
+	utils.WriteJSON(w, http.StatusCreated, createdWishlist)
+}
+
+func (h *Handler) handleGetWishlist(w http.ResponseWriter, r *http.Request) {
+	wishlist, ok := h.getOwnWishlist(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.attachProducts(r.Context(), wishlist, wishlist.UserID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, wishlist)
+}
+
+func (h *Handler) handleUpdateWishlist(w http.ResponseWriter, r *http.Request) {
+	existingWishlist, ok := h.getOwnWishlist(w, r)
+	if !ok {
+		return
+	}
+
+	var wishlist types.CreateUpdateWishlistPayload
+	if err := utils.ParseJSON(r, &wishlist); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(wishlist); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	if err := h.store.UpdateWishlist(r.Context(), existingWishlist.ID, wishlist.Name); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	updatedWishlist, _ := h.store.GetWishlistByID(r.Context(), existingWishlist.ID)
+	if err := h.attachProducts(r.Context(), updatedWishlist, existingWishlist.UserID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, updatedWishlist)
+}
+
+func (h *Handler) handleDeleteWishlist(w http.ResponseWriter, r *http.Request) {
+	existingWishlist, ok := h.getOwnWishlist(w, r)
+	if !ok {
+		return
+	}
+
+	if existingWishlist.IsDefault {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("the default wishlist cannot be deleted"))
+		return
+	}
+
+	rowsAffected, err := h.store.DeleteWishlist(r.Context(), existingWishlist.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to delete wishlist: %w", err))
+		return
+	}
+
+	if rowsAffected == 0 {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("wishlist not found"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleAddItem(w http.ResponseWriter, r *http.Request) {
+	wishlist, ok := h.getOwnWishlist(w, r)
+	if !ok {
+		return
+	}
+
+	h.addItem(w, r, wishlist)
+}
+
+func (h *Handler) handleRemoveItem(w http.ResponseWriter, r *http.Request) {
+	wishlist, ok := h.getOwnWishlist(w, r)
+	if !ok {
+		return
+	}
+
+	h.removeItem(w, r, wishlist)
+}
+
+func (h *Handler) handleShareWishlist(w http.ResponseWriter, r *http.Request) {
+	existingWishlist, ok := h.getOwnWishlist(w, r)
+	if !ok {
+		return
+	}
+
+	// Sharing an already shared wishlist keeps its link stable.
+	if existingWishlist.ShareToken == nil {
+		token, err := generateToken()
+		if err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := h.store.SetWishlistShareToken(r.Context(), existingWishlist.ID, &token); err != nil {
+			utils.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	updatedWishlist, _ := h.store.GetWishlistByID(r.Context(), existingWishlist.ID)
+	if err := h.attachProducts(r.Context(), updatedWishlist, existingWishlist.UserID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, updatedWishlist)
+}
+
+func (h *Handler) handleUnshareWishlist(w http.ResponseWriter, r *http.Request) {
+	existingWishlist, ok := h.getOwnWishlist(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.SetWishlistShareToken(r.Context(), existingWishlist.ID, nil); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleGetSharedWishlist(w http.ResponseWriter, r *http.Request) {
+	token, ok := mux.Vars(r)["token"]
+	if !ok || token == "" {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing share token"))
+		return
+	}
+
+	wishlist, err := h.store.GetWishlistByShareToken(r.Context(), token)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Visitors only see products of approved shops and never the token
+	// itself.
+	wishlist.ShareToken = nil
+	if err := h.attachProducts(r.Context(), wishlist, -1); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, wishlist)
+}
+
+func (h *Handler) addItem(w http.ResponseWriter, r *http.Request, wishlist *types.Wishlist) {
+	var item types.AddWishlistItemPayload
+	if err := utils.ParseJSON(r, &item); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(item); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload: %v", errors))
+		return
+	}
+
+	product, err := h.productStore.GetProductByID(r.Context(), item.ProductID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	shop, err := h.shopStore.GetShopByID(r.Context(), product.ShopID)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product not found"))
+		return
+	}
+
+	visible, err := shopmember.CanViewShop(r.Context(), h.memberStore, h.userStore, shop, wishlist.UserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !visible {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product not found"))
+		return
+	}
+
+	added, err := h.store.AddWishlistItem(r.Context(), wishlist.ID, product.ID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	status := http.StatusOK
+	if added {
+		status = http.StatusCreated
+	}
+
+	updatedWishlist, _ := h.store.GetWishlistByID(r.Context(), wishlist.ID)
+	if err := h.attachProducts(r.Context(), updatedWishlist, wishlist.UserID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, status, updatedWishlist)
+}
+
+func (h *Handler) removeItem(w http.ResponseWriter, r *http.Request, wishlist *types.Wishlist) {
+	productID, ok := pathID(w, r, "product_id", "product ID")
+	if !ok {
+		return
+	}
+
+	rowsAffected, err := h.store.RemoveWishlistItem(r.Context(), wishlist.ID, productID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to remove product from wishlist: %w", err))
+		return
+	}
+
+	if rowsAffected == 0 {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("product not in wishlist"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getOwnWishlist loads the wishlist from the request path and makes sure it
+// belongs to the authenticated user. Wishlists of other users are reported as
+// not found.
+func (h *Handler) getOwnWishlist(w http.ResponseWriter, r *http.Request) (*types.Wishlist, bool) {
+	wishlistID, ok := pathID(w, r, "wishlist_id", "wishlist ID")
+	if !ok {
+		return nil, false
+	}
+
+	wishlist, err := h.store.GetWishlistByID(r.Context(), wishlistID)
+	if err != nil || wishlist.UserID != auth.GetUserIDFromContext(r.Context()) {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("wishlist not found"))
+		return nil, false
+	}
+
+	return wishlist, true
+}
+
+// attachProducts fills in the products of the wishlist items as seen by
+// userID, dropping items whose product is deleted or not visible.
+func (h *Handler) attachProducts(ctx context.Context, wishlist *types.Wishlist, userID int) error {
+	if wishlist == nil {
+		return nil
+	}
+
+	inWishlist := true
+	items := []types.WishlistItem{}
+	for _, item := range wishlist.Items {
+		product, err := h.productStore.GetProductByID(ctx, item.ProductID)
+		if err != nil {
+			continue
+		}
+
+		shop, err := h.shopStore.GetShopByID(ctx, product.ShopID)
+		if err != nil {
+			continue
+		}
+
+		visible, err := shopmember.CanViewShop(ctx, h.memberStore, h.userStore, shop, userID)
+		if err != nil {
+			return err
+		}
+
+		if !visible {
+			continue
+		}
+
+		if userID == wishlist.UserID {
+			product.InWishlist = &inWishlist
+		}
+		item.Product = product
+		items = append(items, item)
+	}
+
+	wishlist.Items = items
+
+	return nil
+}
+
+func pathID(w http.ResponseWriter, r *http.Request, key, name string) (int, bool) {
+	str, ok := mux.Vars(r)[key]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing %s", name))
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid %s", name))
+		return 0, false
+	}
+
+	return id, true
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,222 @@
+package wishlist
+
+import (
+	"context"
+	"ecom_go/db"
+	"ecom_go/types"
+	"errors"
+	"strings"
+)
+
+const defaultWishlistName = "Wishlist"
+
+const wishlistColumns = "id, user_id, name, is_default, share_token, created_at, updated_at"
+
+type Store struct {
+	db *db.DB
+}
+
+func NewStore(db *db.DB) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) GetWishlists(ctx context.Context, userID int) ([]types.Wishlist, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+wishlistColumns+" FROM wishlists WHERE user_id = ? ORDER BY is_default DESC, id", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	wishlists := []types.Wishlist{}
+	for rows.Next() {
+		wishlist, err := scanWishlist(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		wishlists = append(wishlists, *wishlist)
+	}
+
+	return wishlists, rows.Err()
+}
+
+func (s *Store) GetWishlistByID(ctx context.Context, wishlistID int) (*types.Wishlist, error) {
+	return s.getWishlist(ctx, "SELECT "+wishlistColumns+" FROM wishlists WHERE id = ?", wishlistID)
+}
+
+func (s *Store) GetWishlistByShareToken(ctx context.Context, token string) (*types.Wishlist, error) {
+	return s.getWishlist(ctx, "SELECT "+wishlistColumns+" FROM wishlists WHERE share_token = ?", token)
+}
+
+// GetDefaultWishlist returns the default wishlist of the user, creating it on
+// first use.
+func (s *Store) GetDefaultWishlist(ctx context.Context, userID int) (*types.Wishlist, error) {
+	wishlist, err := s.getWishlist(ctx, "SELECT "+wishlistColumns+" FROM wishlists WHERE user_id = ? AND is_default = TRUE", userID)
+	if !errors.Is(err, types.ErrNotFound) {
+		return wishlist, err
+	}
+
+	if _, err := s.db.ExecContext(ctx, "INSERT INTO wishlists (user_id, name, is_default) VALUES (?, ?, TRUE)",
+		userID, defaultWishlistName); err != nil {
+		return nil, err
+	}
+
+	return s.getWishlist(ctx, "SELECT "+wishlistColumns+" FROM wishlists WHERE user_id = ? AND is_default = TRUE", userID)
+}
+
+func (s *Store) CreateWishlist(ctx context.Context, userID int, name string) (int, error) {
+	id, err := s.db.Insert(ctx, "INSERT INTO wishlists (user_id, name) VALUES (?, ?)", userID, name)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+func (s *Store) UpdateWishlist(ctx context.Context, wishlistID int, name string) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE wishlists SET name = ? WHERE id = ?", name, wishlistID)
+
+	return err
+}
+
+// DeleteWishlist removes a wishlist and its items. The default wishlist
+// cannot be deleted.
+func (s *Store) DeleteWishlist(ctx context.Context, wishlistID int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM wishlists WHERE id = ? AND is_default = FALSE", wishlistID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// SetWishlistShareToken sets the token the wishlist is shared with. A nil
+// token stops sharing.
+func (s *Store) SetWishlistShareToken(ctx context.Context, wishlistID int, token *string) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE wishlists SET share_token = ? WHERE id = ?", token, wishlistID)
+
+	return err
+}
+
+// AddWishlistItem adds the product to the wishlist and reports whether it was
+// not there yet.
+func (s *Store) AddWishlistItem(ctx context.Context, wishlistID int, productID int) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM wishlist_items WHERE wishlist_id = ? AND product_id = ?",
+		wishlistID, productID).Scan(&count); err != nil {
+		return false, err
+	}
+
+	if count > 0 {
+		return false, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO wishlist_items (wishlist_id, product_id) VALUES (?, ?)", wishlistID, productID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (s *Store) RemoveWishlistItem(ctx context.Context, wishlistID int, productID int) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM wishlist_items WHERE wishlist_id = ? AND product_id = ?", wishlistID, productID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// GetWishlistedProductSet reports which of productIDs are saved in any of the
+// wishlists of the user.
+func (s *Store) GetWishlistedProductSet(ctx context.Context, userID int, productIDs []int) (map[int]bool, error) {
+	set := map[int]bool{}
+	if len(productIDs) == 0 {
+		return set, nil
+	}
+
+	args := []any{userID}
+	for _, id := range productIDs {
+		args = append(args, id)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(productIDs)), ", ")
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT DISTINCT i.product_id FROM wishlist_items i JOIN wishlists w ON w.id = i.wishlist_id "+
+			"WHERE w.user_id = ? AND i.product_id IN ("+placeholders+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID int
+		if err := rows.Scan(&productID); err != nil {
+			return nil, err
+		}
+
+		set[productID] = true
+	}
+
+	return set, rows.Err()
+}
+
+func (s *Store) getWishlist(ctx context.Context, query string, args ...any) (*types.Wishlist, error) {
+	wishlist, err := scanWishlist(s.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		return nil, types.NotFound("wishlist", err)
+	}
+
+	items, err := s.getWishlistItems(ctx, wishlist.ID)
+	if err != nil {
+		return nil, err
+	}
+	wishlist.Items = items
+
+	return wishlist, nil
+}
+
+func (s *Store) getWishlistItems(ctx context.Context, wishlistID int) ([]types.WishlistItem, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT id, wishlist_id, product_id, created_at, updated_at FROM wishlist_items WHERE wishlist_id = ? ORDER BY id DESC", wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []types.WishlistItem{}
+	for rows.Next() {
+		item := types.WishlistItem{}
+		if err := rows.Scan(&item.ID, &item.WishlistID, &item.ProductID, &item.CreatedAt, &item.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func scanWishlist(row db.Scanner) (*types.Wishlist, error) {
+	wishlist := new(types.Wishlist)
+
+	err := row.Scan(
+		&wishlist.ID,
+		&wishlist.UserID,
+		&wishlist.Name,
+		&wishlist.IsDefault,
+		&wishlist.ShareToken,
+		&wishlist.CreatedAt,
+		&wishlist.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return wishlist, nil
+}
@@ -1,6 +1,44 @@
 package types
 
-import "time"
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotFound is matched by the errors stores return for rows that do not
+// exist.
+var ErrNotFound = errors.New("not found")
+
+// NotFoundError reports that a row of the named resource does not exist. It
+// matches ErrNotFound and wraps sql.ErrNoRows.
+type NotFoundError struct {
+	Resource string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Resource + " not found"
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return sql.ErrNoRows
+}
+
+// NotFound turns sql.ErrNoRows into a NotFoundError for resource and returns
+// any other error unchanged.
+func NotFound(resource string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return &NotFoundError{Resource: resource}
+	}
+
+	return err
+}
 
 type BaseTimeModel struct {
 	CreatedAt time.Time `json:"created_at"`
@@ -32,18 +70,140 @@ type ShopCategory struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
 	BaseTimeModel
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 type Shop struct {
-	ID          int    `json:"id"`
-	UserID      int    `json:"user_id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	CategoryID  int    `json:"category_id"`
-	Opens_at    string `json:"opens_at"`
-	Closes_at   string `json:"closes_at"`
-	Address     string `json:"address"`
-	Image       string `json:"image"`
+	ID            int        `json:"id"`
+	UserID        int        `json:"user_id"`
+	Name          string     `json:"name"`
+	Description   string     `json:"description"`
+	CategoryID    int        `json:"category_id"`
+	Opens_at      string     `json:"opens_at"`
+	Closes_at     string     `json:"closes_at"`
+	Address       string     `json:"address"`
+	Street        *string    `json:"street"`
+	City          *string    `json:"city"`
+	PostalCode    *string    `json:"postal_code"`
+	Country       *string    `json:"country"`
+	Latitude      *float64   `json:"latitude"`
+	Longitude     *float64   `json:"longitude"`
+	Image         string     `json:"image"`
+	Images        *ImageSet  `json:"images,omitempty"`
+	Timezone      string     `json:"timezone"`
+	Allocation    string     `json:"allocation_strategy"`
+	Status        string     `json:"status"`
+	StatusReason  *string    `json:"status_reason"`
+	ReviewedBy    *int       `json:"reviewed_by"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+	RatingAverage float64    `json:"rating_average"`
+	RatingCount   int        `json:"rating_count"`
+	IsOpenNow     bool       `json:"is_open_now"`
+	NextOpening   *time.Time `json:"next_opening"`
+	IsFollowed    *bool      `json:"is_followed,omitempty"`
+	BaseTimeModel
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+const (
+	ShopStatusPending   = "pending"
+	ShopStatusApproved  = "approved"
+	ShopStatusRejected  = "rejected"
+	ShopStatusSuspended = "suspended"
+)
+
+type NearbyShop struct {
+	Shop
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// Address is a postal address. Addresses are normalized before they are
+// stored: the country is an upper case ISO 3166-1 alpha-2 code and the postal
+// code is written the way the country writes it.
+type Address struct {
+	Street     string `json:"street"`
+	Street2    string `json:"street2,omitempty"`
+	City       string `json:"city"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+}
+
+// UserAddress is an entry in the address book of a user, usable as both a
+// shipping and a billing address. A user with addresses has exactly one
+// default address.
+type UserAddress struct {
+	ID       int     `json:"id"`
+	UserID   int     `json:"user_id"`
+	Label    *string `json:"label,omitempty"`
+	FullName string  `json:"full_name"`
+	Phone    *string `json:"phone,omitempty"`
+	Address
+	IsDefault bool `json:"is_default"`
+	BaseTimeModel
+}
+
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// OpeningHours is a single weekly opening interval. Weekday follows
+// time.Weekday (0 is Sunday). An interval whose closing time is not after its
+// opening time runs overnight into the next day; equal times mean the shop is
+// open around the clock.
+type OpeningHours struct {
+	ID       int    `json:"id"`
+	ShopID   int    `json:"shop_id"`
+	Weekday  int    `json:"weekday"`
+	OpensAt  string `json:"opens_at"`
+	ClosesAt string `json:"closes_at"`
+	BaseTimeModel
+}
+
+// ShopHoursException overrides the weekly schedule for a single date. A row
+// without opening and closing times marks the shop as closed that day.
+type ShopHoursException struct {
+	ID       int     `json:"id"`
+	ShopID   int     `json:"shop_id"`
+	Date     string  `json:"date"`
+	OpensAt  *string `json:"opens_at"`
+	ClosesAt *string `json:"closes_at"`
+	Note     *string `json:"note"`
+	BaseTimeModel
+}
+
+type ShopHours struct {
+	Timezone    string               `json:"timezone"`
+	Hours       []OpeningHours       `json:"hours"`
+	Exceptions  []ShopHoursException `json:"exceptions"`
+	IsOpenNow   bool                 `json:"is_open_now"`
+	NextOpening *time.Time           `json:"next_opening"`
+}
+
+const (
+	ShopRoleOwner   = "owner"
+	ShopRoleManager = "manager"
+	ShopRoleStaff   = "staff"
+)
+
+type ShopMember struct {
+	ID     int    `json:"id"`
+	ShopID int    `json:"shop_id"`
+	UserID int    `json:"user_id"`
+	Role   string `json:"role"`
+	BaseTimeModel
+}
+
+type ShopInvitation struct {
+	ID         int        `json:"id"`
+	ShopID     int        `json:"shop_id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	Token      string     `json:"-"`
+	InvitedBy  int        `json:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
 	BaseTimeModel
 }
 
@@ -51,44 +211,694 @@ type ProductCategory struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
 	BaseTimeModel
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 type Product struct {
-	ID          int    `json:"id"`
-	ShopID      int    `json:"shop_id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	CategoryID  int    `json:"category_id"`
-	Quantity    int    `json:"quantity"`
-	Image       string `json:"image"`
+	ID                int       `json:"id"`
+	ShopID            int       `json:"shop_id"`
+	SKU               *string   `json:"sku"`
+	Title             string    `json:"title"`
+	Description       string    `json:"description"`
+	CategoryID        int       `json:"category_id"`
+	Quantity          int       `json:"quantity"`
+	LowStockThreshold *int      `json:"low_stock_threshold"`
+	PriceCents        int64     `json:"price_cents"`
+	TaxClass          string    `json:"tax_class"`
+	WeightGrams       int       `json:"weight_grams"`
+	LengthMm          int       `json:"length_mm"`
+	WidthMm           int       `json:"width_mm"`
+	HeightMm          int       `json:"height_mm"`
+	Image             string    `json:"image"`
+	Images            *ImageSet `json:"images,omitempty"`
+	RatingAverage     float64   `json:"rating_average"`
+	RatingCount       int       `json:"rating_count"`
+	InWishlist        *bool     `json:"in_wishlist,omitempty"`
+	BaseTimeModel
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+const (
+	ReviewStatusVisible = "visible"
+	ReviewStatusHidden  = "hidden"
+	ReviewStatusFlagged = "flagged"
+)
+
+// Review is a product review. Hidden reviews are excluded from listings and
+// rating aggregates, flagged ones stay visible until a moderator decides.
+type Review struct {
+	ID               int           `json:"id"`
+	ProductID        int           `json:"product_id"`
+	UserID           int           `json:"user_id"`
+	Rating           int           `json:"rating"`
+	Body             *string       `json:"body"`
+	Status           string        `json:"status"`
+	ModerationReason *string       `json:"moderation_reason,omitempty"`
+	OwnerReply       *string       `json:"owner_reply"`
+	OwnerRepliedAt   *time.Time    `json:"owner_replied_at"`
+	HelpfulCount     int           `json:"helpful_count"`
+	Photos           []ReviewPhoto `json:"photos"`
+	BaseTimeModel
+}
+
+type ReviewPhoto struct {
+	ID       int    `json:"id"`
+	ReviewID int    `json:"review_id"`
+	URL      string `json:"url"`
+	BaseTimeModel
+}
+
+// Wishlist is a named list of saved products. Every user has one default
+// wishlist, created on first use. A wishlist with a share token can be viewed
+// by anyone who knows the token.
+type Wishlist struct {
+	ID         int            `json:"id"`
+	UserID     int            `json:"user_id"`
+	Name       string         `json:"name"`
+	IsDefault  bool           `json:"is_default"`
+	ShareToken *string        `json:"share_token,omitempty"`
+	Items      []WishlistItem `json:"items,omitempty"`
+	BaseTimeModel
+}
+
+type WishlistItem struct {
+	ID         int      `json:"id"`
+	WishlistID int      `json:"wishlist_id"`
+	ProductID  int      `json:"product_id"`
+	Product    *Product `json:"product,omitempty"`
+	BaseTimeModel
+}
+
+const (
+	PromotionTypePercentage   = "percentage"
+	PromotionTypeFixedAmount  = "fixed_amount"
+	PromotionTypeBuyXGetY     = "buy_x_get_y"
+	PromotionTypeFreeShipping = "free_shipping"
+)
+
+const (
+	PromotionScopeAll      = "all"
+	PromotionScopeShop     = "shop"
+	PromotionScopeCategory = "category"
+	PromotionScopeProduct  = "product"
+)
+
+// Promotion is a discount rule. Value is a percentage for percentage and
+// buy_x_get_y promotions and an amount in cents for fixed_amount ones.
+// Promotions with a ShopID only apply to products of that shop, those with a
+// Code only when the coupon is entered.
+type Promotion struct {
+	ID                int        `json:"id"`
+	ShopID            *int       `json:"shop_id"`
+	CreatedBy         int        `json:"created_by"`
+	Name              string     `json:"name"`
+	Code              *string    `json:"code"`
+	Type              string     `json:"type"`
+	Value             int64      `json:"value"`
+	BuyQuantity       int        `json:"buy_quantity"`
+	GetQuantity       int        `json:"get_quantity"`
+	Scope             string     `json:"scope"`
+	TargetIDs         []int      `json:"target_ids"`
+	MinSubtotalCents  int64      `json:"min_subtotal_cents"`
+	StartsAt          *time.Time `json:"starts_at"`
+	EndsAt            *time.Time `json:"ends_at"`
+	UsageLimit        *int       `json:"usage_limit"`
+	UsageLimitPerUser *int       `json:"usage_limit_per_user"`
+	UsageCount        int        `json:"usage_count"`
+	UserUsageCount    int        `json:"-"`
+	Stackable         bool       `json:"stackable"`
+	Priority          int        `json:"priority"`
+	Active            bool       `json:"active"`
+	BaseTimeModel
+}
+
+type CartLine struct {
+	ProductID      int   `json:"product_id"`
+	ShopID         int   `json:"shop_id"`
+	CategoryID     int   `json:"category_id"`
+	Quantity       int   `json:"quantity"`
+	UnitPriceCents int64 `json:"unit_price_cents"`
+}
+
+type Cart struct {
+	UserID        int        `json:"user_id"`
+	Lines         []CartLine `json:"lines"`
+	ShippingCents int64      `json:"shipping_cents"`
+	CouponCodes   []string   `json:"coupon_codes"`
+	At            time.Time  `json:"at"`
+}
+
+type DiscountLine struct {
+	ProductID   int   `json:"product_id"`
+	AmountCents int64 `json:"amount_cents"`
+}
+
+// Discount is the effect of one promotion on a cart. AmountCents is taken off
+// the lines, ShippingCents off the shipping cost.
+type Discount struct {
+	PromotionID   int            `json:"promotion_id"`
+	Name          string         `json:"name"`
+	Code          *string        `json:"code,omitempty"`
+	Type          string         `json:"type"`
+	AmountCents   int64          `json:"amount_cents"`
+	ShippingCents int64          `json:"shipping_cents"`
+	Lines         []DiscountLine `json:"lines,omitempty"`
+}
+
+type CartEvaluation struct {
+	SubtotalCents         int64      `json:"subtotal_cents"`
+	DiscountCents         int64      `json:"discount_cents"`
+	ShippingCents         int64      `json:"shipping_cents"`
+	ShippingDiscountCents int64      `json:"shipping_discount_cents"`
+	TotalCents            int64      `json:"total_cents"`
+	Discounts             []Discount `json:"discounts"`
+}
+
+const TaxClassStandard = "standard"
+
+// TaxRate is the rate applied to products of a tax class in a country, or in
+// one region of it when Region is set. Inclusive rates are already part of
+// the product prices.
+type TaxRate struct {
+	ID              int     `json:"id"`
+	Country         string  `json:"country"`
+	Region          *string `json:"region"`
+	TaxClass        string  `json:"tax_class"`
+	Name            string  `json:"name"`
+	RateBasisPoints int     `json:"rate_basis_points"`
+	Inclusive       bool    `json:"inclusive"`
+	BaseTimeModel
+}
+
+// TaxLine is a priced line to tax. AmountCents is the total of the line after
+// discounts. OriginCountry is used when the request has no buyer country.
+type TaxLine struct {
+	ProductID     int     `json:"product_id"`
+	TaxClass      string  `json:"tax_class"`
+	Quantity      int     `json:"quantity"`
+	AmountCents   int64   `json:"amount_cents"`
+	OriginCountry *string `json:"origin_country"`
+}
+
+type TaxRequest struct {
+	Country string    `json:"country"`
+	Region  *string   `json:"region"`
+	Lines   []TaxLine `json:"lines"`
+}
+
+type TaxLineResult struct {
+	ProductID       int    `json:"product_id"`
+	TaxClass        string `json:"tax_class"`
+	Country         string `json:"country"`
+	RateID          *int   `json:"rate_id"`
+	RateName        string `json:"rate_name"`
+	RateBasisPoints int    `json:"rate_basis_points"`
+	Inclusive       bool   `json:"inclusive"`
+	NetCents        int64  `json:"net_cents"`
+	TaxCents        int64  `json:"tax_cents"`
+	GrossCents      int64  `json:"gross_cents"`
+}
+
+type TaxResult struct {
+	Lines      []TaxLineResult `json:"lines"`
+	NetCents   int64           `json:"net_cents"`
+	TaxCents   int64           `json:"tax_cents"`
+	GrossCents int64           `json:"gross_cents"`
+}
+
+const (
+	ShippingMethodPickup      = "pickup"
+	ShippingMethodFlatRate    = "flat_rate"
+	ShippingMethodWeightTiers = "weight_tiers"
+	ShippingMethodPriceTiers  = "price_tiers"
+)
+
+const (
+	ShippingZonePostalRange = "postal_range"
+	ShippingZoneRadius      = "radius"
+)
+
+// ShippingMethod is a way a shop delivers orders. Flat rate methods charge
+// RateCents, tiered ones the rate of the highest tier the parcel weight or
+// price reaches. Delivery is free from FreeOverCents on. Methods without
+// zones deliver anywhere, pickup happens at the shop.
+type ShippingMethod struct {
+	ID            int                `json:"id"`
+	ShopID        int                `json:"shop_id"`
+	Name          string             `json:"name"`
+	Type          string             `json:"type"`
+	RateCents     int64              `json:"rate_cents"`
+	FreeOverCents *int64             `json:"free_over_cents"`
+	Active        bool               `json:"active"`
+	Tiers         []ShippingRateTier `json:"tiers"`
+	Zones         []ShippingZone     `json:"zones"`
+	BaseTimeModel
+}
+
+// ShippingRateTier applies from MinValue on, in grams for weight tiers and
+// in cents for price tiers.
+type ShippingRateTier struct {
+	ID        int   `json:"id"`
+	MethodID  int   `json:"method_id"`
+	MinValue  int64 `json:"min_value"`
+	RateCents int64 `json:"rate_cents"`
+	BaseTimeModel
+}
+
+// ShippingZone is an area a method delivers to: either a range of postal
+// codes in a country or a radius around the shop.
+type ShippingZone struct {
+	ID         int      `json:"id"`
+	MethodID   int      `json:"method_id"`
+	Type       string   `json:"type"`
+	Country    *string  `json:"country"`
+	PostalFrom *string  `json:"postal_from"`
+	PostalTo   *string  `json:"postal_to"`
+	RadiusKm   *float64 `json:"radius_km"`
+	BaseTimeModel
+}
+
+type ShippingOption struct {
+	MethodID  int    `json:"method_id"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	CostCents int64  `json:"cost_cents"`
+}
+
+// ShippingQuote lists the options of one shop for the items bought there.
+type ShippingQuote struct {
+	ShopID        int              `json:"shop_id"`
+	WeightGrams   int64            `json:"weight_grams"`
+	SubtotalCents int64            `json:"subtotal_cents"`
+	Options       []ShippingOption `json:"options"`
+}
+
+const (
+	StockMovementReceipt    = "receipt"
+	StockMovementSale       = "sale"
+	StockMovementAdjustment = "adjustment"
+	StockMovementReturn     = "return"
+	StockMovementTransfer   = "transfer"
+)
+
+// StockMovement is an entry in the append-only stock ledger of a product.
+// Quantity is signed: receipts and returns add stock, sales remove it and
+// adjustments go either way. A transfer is recorded as two movements, one
+// out of a location and one into another.
+type StockMovement struct {
+	ID            int     `json:"id"`
+	ProductID     int     `json:"product_id"`
+	Type          string  `json:"type"`
+	Quantity      int     `json:"quantity"`
+	LocationID    *int    `json:"location_id,omitempty"`
+	Reason        *string `json:"reason,omitempty"`
+	ActorID       *int    `json:"actor_id,omitempty"`
+	ReservationID *int    `json:"reservation_id,omitempty"`
+	BaseTimeModel
+}
+
+// StockLevel is the reconciled stock of a product. OnHand is the quantity
+// kept on the product and LedgerOnHand the sum of its stock movements; they
+// are reconciled when they agree with each other and with the sum of the
+// stock at the locations of the shop. Available is what is on hand and not
+// held by an active reservation.
+type StockLevel struct {
+	ProductID         int                  `json:"product_id"`
+	OnHand            int                  `json:"on_hand"`
+	Reserved          int                  `json:"reserved"`
+	Available         int                  `json:"available"`
+	LedgerOnHand      int                  `json:"ledger_on_hand"`
+	Reconciled        bool                 `json:"reconciled"`
+	LowStockThreshold *int                 `json:"low_stock_threshold"`
+	LowStock          bool                 `json:"low_stock"`
+	Locations         []LocationStockLevel `json:"locations"`
+}
+
+type LocationStockLevel struct {
+	LocationID int    `json:"location_id"`
+	Name       string `json:"name"`
+	OnHand     int    `json:"on_hand"`
+	Reserved   int    `json:"reserved"`
+	Available  int    `json:"available"`
+}
+
+const (
+	AllocationHighestStock = "highest_stock"
+	AllocationNearest      = "nearest"
+)
+
+// StockLocation is a warehouse or storefront of a shop holding stock. Every
+// shop has one default location, which receives stock that is not booked to
+// a location explicitly.
+type StockLocation struct {
+	ID     int    `json:"id"`
+	ShopID int    `json:"shop_id"`
+	Name   string `json:"name"`
+	Address
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	IsDefault bool     `json:"is_default"`
+	BaseTimeModel
+}
+
+// StockChange reports how the available stock of a product moved, for the
+// low stock alerts.
+type StockChange struct {
+	ProductID         int
+	AvailableBefore   int
+	AvailableAfter    int
+	LowStockThreshold *int
+}
+
+const (
+	ReservationStatusActive    = "active"
+	ReservationStatusCommitted = "committed"
+	ReservationStatusReleased  = "released"
+	ReservationStatusExpired   = "expired"
+)
+
+// StockReservation holds stock for a pending checkout until it expires. An
+// active reservation past its expiry no longer holds any stock.
+type StockReservation struct {
+	ID        int                    `json:"id"`
+	UserID    int                    `json:"user_id"`
+	Status    string                 `json:"status"`
+	ExpiresAt time.Time              `json:"expires_at"`
+	Items     []StockReservationItem `json:"items"`
+	BaseTimeModel
+}
+
+type StockReservationItem struct {
+	ID            int `json:"id"`
+	ReservationID int `json:"reservation_id"`
+	ProductID     int `json:"product_id"`
+	LocationID    int `json:"location_id"`
+	Quantity      int `json:"quantity"`
+	BaseTimeModel
+}
+
+const (
+	ImportFormatCSV   = "csv"
+	ImportFormatJSONL = "jsonl"
+)
+
+const (
+	ImportStatusPending   = "pending"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+)
+
+// ProductImportJob is a bulk product import of a shop. Rows are upserted by
+// SKU; a dry run validates the rows and counts what would be created or
+// updated without writing anything.
+type ProductImportJob struct {
+	ID          int              `json:"id"`
+	ShopID      int              `json:"shop_id"`
+	UserID      int              `json:"user_id"`
+	Format      string           `json:"format"`
+	DryRun      bool             `json:"dry_run"`
+	Status      string           `json:"status"`
+	TotalRows   int              `json:"total_rows"`
+	CreatedRows int              `json:"created_rows"`
+	UpdatedRows int              `json:"updated_rows"`
+	FailedRows  int              `json:"failed_rows"`
+	Error       *string          `json:"error,omitempty"`
+	RowErrors   []ImportRowError `json:"row_errors"`
+	StartedAt   *time.Time       `json:"started_at"`
+	FinishedAt  *time.Time       `json:"finished_at"`
+	BaseTimeModel
+}
+
+// ImportRowError is a row of an import that was rejected. Rows are numbered
+// from 1, not counting the CSV header.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	SKU     string `json:"sku,omitempty"`
+	Message string `json:"message"`
+}
+
+type ImageVariant struct {
+	ID     int    `json:"id"`
+	Source string `json:"source"`
+	Size   string `json:"size"`
+	Format string `json:"format"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
 	BaseTimeModel
 }
 
+type ImageSize struct {
+	Width   int               `json:"width"`
+	Height  int               `json:"height"`
+	Formats map[string]string `json:"formats"`
+}
+
+type ImageSet struct {
+	Thumbnail *ImageSize `json:"thumbnail,omitempty"`
+	Medium    *ImageSize `json:"medium,omitempty"`
+	Large     *ImageSize `json:"large,omitempty"`
+}
+
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusUnavailable = "unavailable"
+)
+
+// HealthCheckResult is the outcome of one readiness check.
+type HealthCheckResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// HealthReport is the outcome of all readiness checks. The API is only ready
+// when every check passed.
+type HealthReport struct {
+	Status string                       `json:"status"`
+	Checks map[string]HealthCheckResult `json:"checks"`
+}
+
 type UserStore interface {
-	GetUserByID(id int) (*User, error)
-	GetUserByEmail(email string) (*User, error)
-	CreateUser(user User) error
+	GetUserByID(ctx context.Context, id int) (*User, error)
+	GetUserByEmail(ctx context.Context, email string) (*User, error)
+	CreateUser(ctx context.Context, user User) error
 }
 
 type ShopCategoryStore interface {
-	GetShopCategoryByID(shopCategoryId int) (*ShopCategory, error)
-	CreateShopCategory(shopcategory CreateUpdateShopCategoryPayload) error
-	UpdateShopCategory(categoryID int, shopCategory CreateUpdateShopCategoryPayload) error
-	DeleteShopCategory(categoryID int) (int64, error)
+	GetShopCategoryByID(ctx context.Context, shopCategoryId int) (*ShopCategory, error)
+	CreateShopCategory(ctx context.Context, shopcategory CreateUpdateShopCategoryPayload) error
+	UpdateShopCategory(ctx context.Context, categoryID int, shopCategory CreateUpdateShopCategoryPayload) error
+	DeleteShopCategory(ctx context.Context, categoryID int) (int64, error)
+	RestoreShopCategory(ctx context.Context, categoryID int) (int64, error)
+}
+
+type ProductImportStore interface {
+	GetImportJobByID(ctx context.Context, jobID int) (*ProductImportJob, error)
+	GetImportJobData(ctx context.Context, jobID int) ([]byte, error)
+	GetUnfinishedImportJobIDs(ctx context.Context) ([]int, error)
+	CreateImportJob(ctx context.Context, job ProductImportJob, data []byte) (int, error)
+	StartImportJob(ctx context.Context, jobID int) error
+	FinishImportJob(ctx context.Context, job ProductImportJob) error
 }
 
 type ShopStore interface {
-	GetShopByID(shopID int) (*Shop, error)
-	CreateShop(shop CreateShopPayload) error
-	UpdateShop(shopID int, shop UpdateShopPayload) error
-	DeleteShop(shopID int) (int64, error)
+	GetShopByID(ctx context.Context, shopID int) (*Shop, error)
+	GetShopsByStatus(ctx context.Context, status string) ([]Shop, error)
+	GetNearbyShops(ctx context.Context, point GeoPoint, radiusKm float64, limit int) ([]NearbyShop, error)
+	CreateShop(ctx context.Context, shop CreateShopPayload) (int, error)
+	UpdateShop(ctx context.Context, shopID int, shop UpdateShopPayload) error
+	UpdateShopStatus(ctx context.Context, shopID int, from []string, status string, reason *string, reviewerID *int) (int64, error)
+	DeleteShop(ctx context.Context, shopID int) (int64, error)
+	RestoreShop(ctx context.Context, shopID int) (int64, error)
+}
+
+type OpeningHoursStore interface {
+	GetOpeningHours(ctx context.Context, shopID int) ([]OpeningHours, error)
+	ReplaceOpeningHours(ctx context.Context, shopID int, hours []OpeningHoursPayload) error
+	GetShopHoursExceptions(ctx context.Context, shopID int, from time.Time) ([]ShopHoursException, error)
+	CreateShopHoursException(ctx context.Context, shopID int, exception CreateShopHoursExceptionPayload) error
+	DeleteShopHoursException(ctx context.Context, shopID int, exceptionID int) (int64, error)
+}
+
+type ShopMemberStore interface {
+	GetShopMember(ctx context.Context, shopID int, userID int) (*ShopMember, error)
+	GetShopMembers(ctx context.Context, shopID int) ([]ShopMember, error)
+	UpdateShopMemberRole(ctx context.Context, shopID int, userID int, role string) error
+	DeleteShopMember(ctx context.Context, shopID int, userID int) (int64, error)
+	TransferShopOwnership(ctx context.Context, shopID int, fromUserID int, toUserID int) error
+	CreateShopInvitation(ctx context.Context, invitation ShopInvitation) error
+	GetShopInvitations(ctx context.Context, shopID int) ([]ShopInvitation, error)
+	GetShopInvitationByToken(ctx context.Context, token string) (*ShopInvitation, error)
+	AcceptShopInvitation(ctx context.Context, invitation ShopInvitation, userID int) error
+	DeleteShopInvitation(ctx context.Context, shopID int, invitationID int) (int64, error)
 }
 
 type ProductCategoryStore interface {
-	CreateShopCategory(productCategory CreateUpdateProductCategoryPayload) error
+	GetProductCategoryByID(ctx context.Context, categoryID int) (*ProductCategory, error)
+	CreateShopCategory(ctx context.Context, productCategory CreateUpdateProductCategoryPayload) error
+	DeleteProductCategory(ctx context.Context, categoryID int) (int64, error)
+	RestoreProductCategory(ctx context.Context, categoryID int) (int64, error)
 }
 
 type ProductStore interface {
+	GetProductByID(ctx context.Context, productID int) (*Product, error)
+	GetProductsByShopID(ctx context.Context, shopID int) ([]Product, error)
+	GetProductBySKU(ctx context.Context, shopID int, sku string) (*Product, error)
+	StreamProductsByShopID(ctx context.Context, shopID int, fn func(*Product) error) error
+	CreateProduct(ctx context.Context, product CreateProductPayload) (int, error)
+	UpdateProduct(ctx context.Context, productID int, product UpdateProductPayload) error
+	DeleteProduct(ctx context.Context, productID int) (int64, error)
+	RestoreProduct(ctx context.Context, productID int) (int64, error)
+}
+
+type ReviewStore interface {
+	GetReviewByID(ctx context.Context, reviewID int) (*Review, error)
+	GetReviewByProductAndUser(ctx context.Context, productID int, userID int) (*Review, error)
+	GetReviewsByProductID(ctx context.Context, productID int, sort string, limit int, offset int) ([]Review, error)
+	GetReviewsByStatus(ctx context.Context, status string) ([]Review, error)
+	CreateReview(ctx context.Context, userID int, review CreateReviewPayload) (int, error)
+	UpdateReview(ctx context.Context, reviewID int, review UpdateReviewPayload) error
+	DeleteReview(ctx context.Context, reviewID int) (int64, error)
+	ReplyToReview(ctx context.Context, reviewID int, reply string) error
+	ModerateReview(ctx context.Context, reviewID int, status string, reason *string) error
+	AddReviewVote(ctx context.Context, reviewID int, userID int) (bool, error)
+	RemoveReviewVote(ctx context.Context, reviewID int, userID int) (bool, error)
+}
+
+type WishlistStore interface {
+	GetWishlists(ctx context.Context, userID int) ([]Wishlist, error)
+	GetWishlistByID(ctx context.Context, wishlistID int) (*Wishlist, error)
+	GetWishlistByShareToken(ctx context.Context, token string) (*Wishlist, error)
+	GetDefaultWishlist(ctx context.Context, userID int) (*Wishlist, error)
+	CreateWishlist(ctx context.Context, userID int, name string) (int, error)
+	UpdateWishlist(ctx context.Context, wishlistID int, name string) error
+	DeleteWishlist(ctx context.Context, wishlistID int) (int64, error)
+	SetWishlistShareToken(ctx context.Context, wishlistID int, token *string) error
+	AddWishlistItem(ctx context.Context, wishlistID int, productID int) (bool, error)
+	RemoveWishlistItem(ctx context.Context, wishlistID int, productID int) (int64, error)
+	GetWishlistedProductSet(ctx context.Context, userID int, productIDs []int) (map[int]bool, error)
+}
+
+type FavoriteStore interface {
+	GetFavoriteShopIDs(ctx context.Context, userID int) ([]int, error)
+	AddFavoriteShop(ctx context.Context, userID int, shopID int) (bool, error)
+	RemoveFavoriteShop(ctx context.Context, userID int, shopID int) (int64, error)
+	GetFavoriteShopSet(ctx context.Context, userID int, shopIDs []int) (map[int]bool, error)
+}
+
+type PromotionStore interface {
+	GetPromotionByID(ctx context.Context, promotionID int) (*Promotion, error)
+	GetPromotionByCode(ctx context.Context, code string) (*Promotion, error)
+	GetPromotions(ctx context.Context, shopID *int) ([]Promotion, error)
+	GetApplicablePromotions(ctx context.Context, userID int, at time.Time) ([]Promotion, error)
+	CreatePromotion(ctx context.Context, createdBy int, promotion CreateUpdatePromotionPayload) (int, error)
+	UpdatePromotion(ctx context.Context, promotionID int, promotion CreateUpdatePromotionPayload) error
+	DeletePromotion(ctx context.Context, promotionID int) (int64, error)
+	RecordPromotionRedemptions(ctx context.Context, userID int, orderID string, promotionIDs []int) error
+}
+
+type TaxRateStore interface {
+	GetTaxRateByID(ctx context.Context, rateID int) (*TaxRate, error)
+	GetTaxRate(ctx context.Context, country string, region *string, taxClass string) (*TaxRate, error)
+	GetTaxRates(ctx context.Context, country string) ([]TaxRate, error)
+	CreateTaxRate(ctx context.Context, rate CreateUpdateTaxRatePayload) (int, error)
+	UpdateTaxRate(ctx context.Context, rateID int, rate CreateUpdateTaxRatePayload) error
+	DeleteTaxRate(ctx context.Context, rateID int) (int64, error)
+}
+
+type TaxCalculator interface {
+	Calculate(ctx context.Context, request TaxRequest) (*TaxResult, error)
+}
+
+// NoTaxCountryError reports that a line of a tax request has no country to be
+// taxed in, because neither the buyer nor the shop of the product has one.
+type NoTaxCountryError struct {
+	ProductID int
+}
+
+func (e *NoTaxCountryError) Error() string {
+	return fmt.Sprintf("no tax country for product %d", e.ProductID)
+}
+
+type ShippingMethodStore interface {
+	GetShippingMethodByID(ctx context.Context, methodID int) (*ShippingMethod, error)
+	GetShippingMethodsByShopID(ctx context.Context, shopID int) ([]ShippingMethod, error)
+	CreateShippingMethod(ctx context.Context, method CreateUpdateShippingMethodPayload) (int, error)
+	UpdateShippingMethod(ctx context.Context, methodID int, method CreateUpdateShippingMethodPayload) error
+	DeleteShippingMethod(ctx context.Context, methodID int) (int64, error)
+}
+
+type UserAddressStore interface {
+	GetUserAddresses(ctx context.Context, userID int) ([]UserAddress, error)
+	GetUserAddressByID(ctx context.Context, addressID int) (*UserAddress, error)
+	CreateUserAddress(ctx context.Context, userID int, address CreateUpdateUserAddressPayload) (int, error)
+	UpdateUserAddress(ctx context.Context, addressID int, address CreateUpdateUserAddressPayload) error
+	DeleteUserAddress(ctx context.Context, addressID int) (int64, error)
+	SetDefaultUserAddress(ctx context.Context, addressID int) error
+}
+
+type InventoryStore interface {
+	GetStockLevel(ctx context.Context, productID int) (*StockLevel, error)
+	GetStockMovements(ctx context.Context, productID int, limit int, offset int) ([]StockMovement, error)
+	RecordStockMovement(ctx context.Context, movement RecordStockMovementPayload) (*StockChange, error)
+	SetStockQuantity(ctx context.Context, productID int, quantity int, actorID int) (*StockChange, error)
+	TransferStock(ctx context.Context, transfer TransferStockPayload) error
+	SetLowStockThreshold(ctx context.Context, productID int, threshold *int) error
+	GetStockLocations(ctx context.Context, shopID int) ([]StockLocation, error)
+	GetStockLocationByID(ctx context.Context, locationID int) (*StockLocation, error)
+	CreateStockLocation(ctx context.Context, location CreateUpdateStockLocationPayload) (int, error)
+	UpdateStockLocation(ctx context.Context, locationID int, location CreateUpdateStockLocationPayload) error
+	DeleteStockLocation(ctx context.Context, locationID int) (int64, error)
+	GetStockReservationByID(ctx context.Context, reservationID int) (*StockReservation, error)
+	CreateStockReservation(ctx context.Context, userID int, items []CartItemPayload, buyer *GeoPoint, expiresAt time.Time) (int, []StockChange, error)
+	CommitStockReservation(ctx context.Context, reservationID int, actorID int) error
+	ReleaseStockReservation(ctx context.Context, reservationID int) (int64, error)
+	ExpireStockReservations(ctx context.Context, now time.Time) (int64, error)
+}
+
+// StockAlerter tells shop owners about products running low on stock.
+type StockAlerter interface {
+	CheckStock(ctx context.Context, changes ...StockChange)
+}
+
+// TrashStore permanently removes soft deleted records.
+type TrashStore interface {
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+}
+
+type Notifier interface {
+	Notify(email string, subject string, body string) error
+}
+
+type Geocoder interface {
+	Geocode(ctx context.Context, address Address) (*GeoPoint, error)
+}
+
+type ImageStore interface {
+	GetImageVariants(ctx context.Context, source string) ([]ImageVariant, error)
+	ReplaceImageVariants(ctx context.Context, source string, variants []ImageVariant) error
+}
+
+type ImageProcessor interface {
+	Enqueue(source string)
+}
+
+type ProductImporter interface {
+	Enqueue(jobID int) bool
+}
+
+// HealthCheck is a dependency the API needs to serve requests. Check returns
+// an error describing why the dependency is not usable.
+type HealthCheck interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type HealthStore interface {
+	Ping(ctx context.Context) error
+	GetMigrationVersion(ctx context.Context) (uint, bool, error)
 }
 
 type RegisterUserPayload struct {
@@ -113,26 +923,281 @@ type CreateUpdateShopCategoryPayload struct {
 }
 
 type CreateShopPayload struct {
-	UserID      int    `json:"user_id" validate:"required"`
-	Name        string `json:"name" validate:"required"`
-	Description string `json:"description,omitempty"`
-	CategoryID  int    `json:"category_id" validate:"required"`
-	Opens_at    string `json:"opens_at,omitempty"`
-	Closes_at   string `json:"closes_at,omitempty"`
-	Address     string `json:"address,omitempty" validate:"omitempty,min=5"`
-	Image       string `json:"image,omitempty" validate:"omitempty,url"`
+	UserID      int      `json:"-"`
+	Name        string   `json:"name" validate:"required"`
+	Description string   `json:"description,omitempty"`
+	CategoryID  int      `json:"category_id" validate:"required"`
+	Opens_at    string   `json:"opens_at,omitempty"`
+	Closes_at   string   `json:"closes_at,omitempty"`
+	Address     string   `json:"address,omitempty" validate:"omitempty,min=5"`
+	Street      *string  `json:"street,omitempty" validate:"omitempty,max=255"`
+	City        *string  `json:"city,omitempty" validate:"omitempty,max=255"`
+	PostalCode  *string  `json:"postal_code,omitempty" validate:"omitempty,max=32"`
+	Country     *string  `json:"country,omitempty" validate:"omitempty,iso3166_1_alpha2"`
+	Latitude    *float64 `json:"latitude,omitempty" validate:"required_with=Longitude,omitempty,latitude"`
+	Longitude   *float64 `json:"longitude,omitempty" validate:"required_with=Latitude,omitempty,longitude"`
+	Image       string   `json:"image,omitempty" validate:"omitempty,url"`
+	Timezone    string   `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	Allocation  string   `json:"allocation_strategy,omitempty" validate:"omitempty,oneof=highest_stock nearest"`
 }
 
 type UpdateShopPayload struct {
-	Name        *string `json:"name,omitempty"`
+	Name        *string  `json:"name,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	CategoryID  *int     `json:"category_id,omitempty"`
+	Opens_at    *string  `json:"opens_at,omitempty"`
+	Closes_at   *string  `json:"closes_at,omitempty"`
+	Address     *string  `json:"address,omitempty" validate:"omitempty,min=5"`
+	Street      *string  `json:"street,omitempty" validate:"omitempty,max=255"`
+	City        *string  `json:"city,omitempty" validate:"omitempty,max=255"`
+	PostalCode  *string  `json:"postal_code,omitempty" validate:"omitempty,max=32"`
+	Country     *string  `json:"country,omitempty" validate:"omitempty,iso3166_1_alpha2"`
+	Latitude    *float64 `json:"latitude,omitempty" validate:"required_with=Longitude,omitempty,latitude"`
+	Longitude   *float64 `json:"longitude,omitempty" validate:"required_with=Latitude,omitempty,longitude"`
+	Image       *string  `json:"image,omitempty" validate:"omitempty,url"`
+	Timezone    *string  `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	Allocation  *string  `json:"allocation_strategy,omitempty" validate:"omitempty,oneof=highest_stock nearest"`
+}
+
+type ShopReviewPayload struct {
+	Reason string `json:"reason" validate:"required,max=1000"`
+}
+
+type OpeningHoursPayload struct {
+	Weekday  int    `json:"weekday" validate:"min=0,max=6"`
+	OpensAt  string `json:"opens_at" validate:"required,datetime=15:04"`
+	ClosesAt string `json:"closes_at" validate:"required,datetime=15:04"`
+}
+
+type UpdateOpeningHoursPayload struct {
+	Hours []OpeningHoursPayload `json:"hours" validate:"dive"`
+}
+
+type CreateShopHoursExceptionPayload struct {
+	Date     string  `json:"date" validate:"required,datetime=2006-01-02"`
+	OpensAt  *string `json:"opens_at,omitempty" validate:"required_with=ClosesAt,omitempty,datetime=15:04"`
+	ClosesAt *string `json:"closes_at,omitempty" validate:"required_with=OpensAt,omitempty,datetime=15:04"`
+	Note     *string `json:"note,omitempty" validate:"omitempty,max=255"`
+}
+
+type CreateUpdateProductCategoryPayload struct {
+	Name string `json:"name"`
+}
+
+type CreateProductPayload struct {
+	UserID      int     `json:"-"`
+	ShopID      int     `json:"shop_id" validate:"required"`
+	SKU         *string `json:"sku,omitempty" validate:"omitempty,max=64,printascii"`
+	Title       string  `json:"title" validate:"required,max=255"`
+	Description string  `json:"description,omitempty"`
+	CategoryID  int     `json:"category_id" validate:"required"`
+	Quantity    int     `json:"quantity" validate:"min=0"`
+	PriceCents  int64   `json:"price_cents" validate:"min=0"`
+	TaxClass    string  `json:"tax_class,omitempty" validate:"omitempty,max=32"`
+	WeightGrams int     `json:"weight_grams" validate:"min=0"`
+	LengthMm    int     `json:"length_mm" validate:"min=0"`
+	WidthMm     int     `json:"width_mm" validate:"min=0"`
+	HeightMm    int     `json:"height_mm" validate:"min=0"`
+	Image       string  `json:"image,omitempty" validate:"omitempty,url"`
+}
+
+type UpdateProductPayload struct {
+	SKU         *string `json:"sku,omitempty" validate:"omitempty,max=64,printascii"`
+	Title       *string `json:"title,omitempty" validate:"omitempty,max=255"`
 	Description *string `json:"description,omitempty"`
 	CategoryID  *int    `json:"category_id,omitempty"`
-	Opens_at    *string `json:"opens_at,omitempty"`
-	Closes_at   *string `json:"closes_at,omitempty"`
-	Address     *string `json:"address,omitempty" validate:"omitempty,min=5"`
+	Quantity    *int    `json:"quantity,omitempty" validate:"omitempty,min=0"`
+	PriceCents  *int64  `json:"price_cents,omitempty" validate:"omitempty,min=0"`
+	TaxClass    *string `json:"tax_class,omitempty" validate:"omitempty,max=32"`
+	WeightGrams *int    `json:"weight_grams,omitempty" validate:"omitempty,min=0"`
+	LengthMm    *int    `json:"length_mm,omitempty" validate:"omitempty,min=0"`
+	WidthMm     *int    `json:"width_mm,omitempty" validate:"omitempty,min=0"`
+	HeightMm    *int    `json:"height_mm,omitempty" validate:"omitempty,min=0"`
 	Image       *string `json:"image,omitempty" validate:"omitempty,url"`
 }
 
-type CreateUpdateProductCategoryPayload struct {
-	Name string `json:"name"`
+type CreateShopInvitationPayload struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=manager staff"`
+}
+
+type UpdateShopMemberPayload struct {
+	Role string `json:"role" validate:"required,oneof=manager staff"`
+}
+
+type TransferShopOwnershipPayload struct {
+	UserID int `json:"user_id" validate:"required"`
+}
+
+type CreateReviewPayload struct {
+	ProductID int      `json:"product_id" validate:"required"`
+	Rating    int      `json:"rating" validate:"required,min=1,max=5"`
+	Body      *string  `json:"body,omitempty" validate:"omitempty,max=5000"`
+	Photos    []string `json:"photos,omitempty" validate:"max=5,dive,url"`
+}
+
+type UpdateReviewPayload struct {
+	Rating *int      `json:"rating,omitempty" validate:"omitempty,min=1,max=5"`
+	Body   *string   `json:"body,omitempty" validate:"omitempty,max=5000"`
+	Photos *[]string `json:"photos,omitempty" validate:"omitempty,max=5,dive,url"`
+}
+
+type ReviewReplyPayload struct {
+	Reply string `json:"reply" validate:"required,max=2000"`
+}
+
+type ModerateReviewPayload struct {
+	Status string  `json:"status" validate:"required,oneof=visible hidden flagged"`
+	Reason *string `json:"reason,omitempty" validate:"omitempty,max=1000"`
+}
+
+type CreateUpdateWishlistPayload struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type AddWishlistItemPayload struct {
+	ProductID int `json:"product_id" validate:"required"`
+}
+
+type AddFavoriteShopPayload struct {
+	ShopID int `json:"shop_id" validate:"required"`
+}
+
+type CreateUpdatePromotionPayload struct {
+	ShopID            *int       `json:"shop_id,omitempty"`
+	Name              string     `json:"name" validate:"required,max=255"`
+	Code              *string    `json:"code,omitempty" validate:"omitempty,min=3,max=64,alphanum"`
+	Type              string     `json:"type" validate:"required,oneof=percentage fixed_amount buy_x_get_y free_shipping"`
+	Value             int64      `json:"value" validate:"min=0"`
+	BuyQuantity       int        `json:"buy_quantity" validate:"min=0"`
+	GetQuantity       int        `json:"get_quantity" validate:"min=0"`
+	Scope             string     `json:"scope" validate:"required,oneof=all shop category product"`
+	TargetIDs         []int      `json:"target_ids,omitempty" validate:"max=100,dive,min=1"`
+	MinSubtotalCents  int64      `json:"min_subtotal_cents" validate:"min=0"`
+	StartsAt          *time.Time `json:"starts_at,omitempty"`
+	EndsAt            *time.Time `json:"ends_at,omitempty"`
+	UsageLimit        *int       `json:"usage_limit,omitempty" validate:"omitempty,min=1"`
+	UsageLimitPerUser *int       `json:"usage_limit_per_user,omitempty" validate:"omitempty,min=1"`
+	Stackable         *bool      `json:"stackable,omitempty"`
+	Priority          int        `json:"priority"`
+	Active            *bool      `json:"active,omitempty"`
+}
+
+type CartItemPayload struct {
+	ProductID int `json:"product_id" validate:"required"`
+	Quantity  int `json:"quantity" validate:"required,min=1,max=1000"`
+}
+
+type EvaluateCartPayload struct {
+	Items         []CartItemPayload `json:"items" validate:"required,min=1,max=100,dive"`
+	CouponCodes   []string          `json:"coupon_codes,omitempty" validate:"max=5"`
+	ShippingCents int64             `json:"shipping_cents" validate:"min=0"`
+}
+
+type CreateUpdateTaxRatePayload struct {
+	Country         string  `json:"country" validate:"required,iso3166_1_alpha2"`
+	Region          *string `json:"region,omitempty" validate:"omitempty,max=64"`
+	TaxClass        string  `json:"tax_class" validate:"required,max=32"`
+	Name            string  `json:"name" validate:"required,max=100"`
+	RateBasisPoints int     `json:"rate_basis_points" validate:"min=0,max=10000"`
+	Inclusive       bool    `json:"inclusive"`
+}
+
+type TaxQuotePayload struct {
+	Country *string           `json:"country,omitempty" validate:"omitempty,iso3166_1_alpha2"`
+	Region  *string           `json:"region,omitempty" validate:"omitempty,max=64"`
+	Items   []CartItemPayload `json:"items" validate:"required,min=1,max=100,dive"`
+}
+
+type ShippingRateTierPayload struct {
+	MinValue  int64 `json:"min_value" validate:"min=0"`
+	RateCents int64 `json:"rate_cents" validate:"min=0"`
+}
+
+type ShippingZonePayload struct {
+	Type       string   `json:"type" validate:"required,oneof=postal_range radius"`
+	Country    *string  `json:"country,omitempty" validate:"omitempty,iso3166_1_alpha2"`
+	PostalFrom *string  `json:"postal_from,omitempty" validate:"omitempty,max=16"`
+	PostalTo   *string  `json:"postal_to,omitempty" validate:"omitempty,max=16"`
+	RadiusKm   *float64 `json:"radius_km,omitempty" validate:"omitempty,gt=0,max=1000"`
+}
+
+type CreateUpdateShippingMethodPayload struct {
+	ShopID        int                       `json:"shop_id" validate:"required"`
+	Name          string                    `json:"name" validate:"required,max=100"`
+	Type          string                    `json:"type" validate:"required,oneof=pickup flat_rate weight_tiers price_tiers"`
+	RateCents     int64                     `json:"rate_cents" validate:"min=0"`
+	FreeOverCents *int64                    `json:"free_over_cents,omitempty" validate:"omitempty,min=1"`
+	Active        *bool                     `json:"active,omitempty"`
+	Tiers         []ShippingRateTierPayload `json:"tiers,omitempty" validate:"max=20,dive"`
+	Zones         []ShippingZonePayload     `json:"zones,omitempty" validate:"max=50,dive"`
+}
+
+type ShippingDestinationPayload struct {
+	Street     string   `json:"street,omitempty" validate:"max=255"`
+	City       string   `json:"city,omitempty" validate:"max=100"`
+	PostalCode string   `json:"postal_code,omitempty" validate:"max=16"`
+	Country    string   `json:"country" validate:"required,iso3166_1_alpha2"`
+	Latitude   *float64 `json:"latitude,omitempty" validate:"required_with=Longitude,omitempty,latitude"`
+	Longitude  *float64 `json:"longitude,omitempty" validate:"required_with=Latitude,omitempty,longitude"`
+}
+
+type ShippingQuotePayload struct {
+	Items       []CartItemPayload          `json:"items" validate:"required,min=1,max=100,dive"`
+	Destination ShippingDestinationPayload `json:"destination" validate:"required"`
+}
+
+type CreateUpdateUserAddressPayload struct {
+	Label      *string `json:"label,omitempty" validate:"omitempty,max=50"`
+	FullName   string  `json:"full_name" validate:"required,max=255"`
+	Phone      *string `json:"phone,omitempty" validate:"omitempty,max=32"`
+	Street     string  `json:"street" validate:"required,max=255"`
+	Street2    string  `json:"street2,omitempty" validate:"max=255"`
+	City       string  `json:"city" validate:"required,max=100"`
+	Region     string  `json:"region,omitempty" validate:"max=100"`
+	PostalCode string  `json:"postal_code,omitempty" validate:"max=16"`
+	Country    string  `json:"country" validate:"required,iso3166_1_alpha2"`
+	IsDefault  bool    `json:"is_default,omitempty"`
+}
+
+type RecordStockMovementPayload struct {
+	ProductID  int     `json:"-"`
+	ActorID    int     `json:"-"`
+	LocationID *int    `json:"location_id,omitempty"`
+	Type       string  `json:"type" validate:"required,oneof=receipt sale adjustment return"`
+	Quantity   int     `json:"quantity" validate:"required,min=-1000000,max=1000000"`
+	Reason     *string `json:"reason,omitempty" validate:"omitempty,max=255"`
+}
+
+type TransferStockPayload struct {
+	ProductID      int     `json:"-"`
+	ActorID        int     `json:"-"`
+	FromLocationID int     `json:"from_location_id" validate:"required,nefield=ToLocationID"`
+	ToLocationID   int     `json:"to_location_id" validate:"required"`
+	Quantity       int     `json:"quantity" validate:"required,min=1,max=1000000"`
+	Reason         *string `json:"reason,omitempty" validate:"omitempty,max=255"`
+}
+
+type CreateUpdateStockLocationPayload struct {
+	ShopID     int      `json:"shop_id" validate:"required"`
+	Name       string   `json:"name" validate:"required,max=100"`
+	Street     string   `json:"street,omitempty" validate:"max=255"`
+	Street2    string   `json:"street2,omitempty" validate:"max=255"`
+	City       string   `json:"city,omitempty" validate:"max=255"`
+	Region     string   `json:"region,omitempty" validate:"max=100"`
+	PostalCode string   `json:"postal_code,omitempty" validate:"max=32"`
+	Country    string   `json:"country,omitempty" validate:"omitempty,iso3166_1_alpha2"`
+	Latitude   *float64 `json:"latitude,omitempty" validate:"required_with=Longitude,omitempty,latitude"`
+	Longitude  *float64 `json:"longitude,omitempty" validate:"required_with=Latitude,omitempty,longitude"`
+	IsDefault  bool     `json:"is_default,omitempty"`
+}
+
+type LowStockThresholdPayload struct {
+	LowStockThreshold *int `json:"low_stock_threshold" validate:"omitempty,min=0"`
+}
+
+type CreateStockReservationPayload struct {
+	Items     []CartItemPayload `json:"items" validate:"required,min=1,max=100,dive"`
+	Latitude  *float64          `json:"latitude,omitempty" validate:"required_with=Longitude,omitempty,latitude"`
+	Longitude *float64          `json:"longitude,omitempty" validate:"required_with=Latitude,omitempty,longitude"`
 }
@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"context"
+	"ecom_go/types"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -10,16 +13,79 @@ import (
 
 var Validate = validator.New()
 
+// StatusClientClosedRequest is the non-standard status, borrowed from nginx,
+// of requests the client gave up on before they completed. The client never
+// sees it, but it keeps those requests apart from failures in logs.
+const StatusClientClosedRequest = 499
+
 func WriteJSON(w http.ResponseWriter, status int, v any) error {
 	w.Header().Add("Content-Type", "application/json")
 	w.WriteHeader(status)
 	return json.NewEncoder(w).Encode(v)
 }
 
+// WriteError writes err as a JSON error. Errors caused by the request context
+// take precedence over the given status: a request that ran out of time gets
+// a 504 and one the client abandoned a 499. Rows a store did not find get a
+// 404.
 func WriteError(w http.ResponseWriter, status int, err error) {
+	switch {
+	case errors.Is(err, types.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+		err = fmt.Errorf("request timed out")
+	case errors.Is(err, context.Canceled):
+		status = StatusClientClosedRequest
+		err = fmt.Errorf("request canceled")
+	}
+
 	WriteJSON(w, status, map[string]string{"error": err.Error()})
 }
 
+// IsContextError reports whether err comes from a cancelled or timed out
+// context.
+func IsContextError(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
+
+// StatusRecorder wraps a ResponseWriter to capture the status and size of the
+// response for middlewares that report on it.
+type StatusRecorder struct {
+	http.ResponseWriter
+	Status      int
+	Bytes       int
+	wroteHeader bool
+}
+
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: w, Status: http.StatusOK}
+}
+
+func (s *StatusRecorder) WriteHeader(status int) {
+	if !s.wroteHeader {
+		s.Status = status
+		s.wroteHeader = true
+	}
+
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *StatusRecorder) Write(b []byte) (int, error) {
+	s.wroteHeader = true
+
+	n, err := s.ResponseWriter.Write(b)
+	s.Bytes += n
+
+	return n, err
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer, so
+// streaming responses can still flush and extend their deadlines.
+func (s *StatusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
 func ParseJSON(r *http.Request, v any) error {
 	if r.Body == nil {
 		return fmt.Errorf("missing request body")